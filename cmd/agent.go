@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/MaksymLeiber/pgen/internal/agent"
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/input"
+	"github.com/MaksymLeiber/pgen/internal/report"
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// Значения по умолчанию для агента
+const (
+	defaultAgentIdleTimeout = 15 * time.Minute
+	defaultAgentMaxLifetime = 8 * time.Hour
+	agentStartTimeout       = 10 * time.Second // ожидание готовности фонового агента
+	agentMasterFd           = 3                // дескриптор мастер-пароля в фоновом агенте
+)
+
+// Флаги агента
+var (
+	agentIdleTimeoutFlag time.Duration
+	agentMaxLifetimeFlag time.Duration
+	agentForegroundFlag  bool
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "",
+	Long:  "",
+}
+
+var agentStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runAgentStartCommand,
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runAgentStatusCommand,
+}
+
+var agentLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runAgentLockCommand,
+}
+
+var agentUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runAgentUnlockCommand,
+}
+
+var agentStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runAgentStopCommand,
+}
+
+func init() {
+	agentStartCmd.Flags().DurationVarP(&agentIdleTimeoutFlag, "idle-timeout", "", defaultAgentIdleTimeout, "")
+	agentStartCmd.Flags().DurationVarP(&agentMaxLifetimeFlag, "max-lifetime", "", defaultAgentMaxLifetime, "")
+	agentStartCmd.Flags().BoolVarP(&agentForegroundFlag, "foreground", "", false, "")
+	addMasterSourceFlags(agentStartCmd)
+	addMasterSourceFlags(agentUnlockCmd)
+
+	agentCmd.AddCommand(agentStartCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+	agentCmd.AddCommand(agentLockCmd)
+	agentCmd.AddCommand(agentUnlockCmd)
+	agentCmd.AddCommand(agentStopCmd)
+}
+
+// updateAgentCommandTexts обновляет тексты команд агента
+func updateAgentCommandTexts(messages *i18n.Messages) {
+	agentCmd.Short = messages.AgentShort
+	agentCmd.Long = messages.AgentLong
+	agentStartCmd.Short = messages.AgentStartShort
+	agentStatusCmd.Short = messages.AgentStatusShort
+	agentLockCmd.Short = messages.AgentLockShort
+	agentUnlockCmd.Short = messages.AgentUnlockShort
+	agentStopCmd.Short = messages.AgentStopShort
+
+	updateMasterSourceFlagTexts(agentStartCmd, messages)
+	updateMasterSourceFlagTexts(agentUnlockCmd, messages)
+
+	flagDescs := map[string]string{
+		"idle-timeout": messages.AgentIdleTimeoutFlagDesc,
+		"max-lifetime": messages.AgentMaxLifetimeFlagDesc,
+		"foreground":   messages.AgentForegroundFlagDesc,
+	}
+	for name, usage := range flagDescs {
+		if flag := agentStartCmd.Flags().Lookup(name); flag != nil {
+			flag.Usage = usage
+		}
+	}
+}
+
+func runAgentStartCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	path := agent.DefaultSocketPath()
+
+	if _, err := agent.Call(path, agent.Request{Op: agent.OpStatus}); err == nil {
+		exitWithError(messages.AgentAlreadyRunning)
+	}
+
+	masterPassword := readMasterOrPrompt(cmd, messages)
+	defer masterPassword.Clear()
+	verifyMaster(masterPassword, messages)
+
+	if agentForegroundFlag {
+		serveAgent(path, masterPassword, messages)
+		return
+	}
+
+	pid := spawnAgent(path, masterPassword, messages)
+	if isJSONOutput(messages) {
+		writeReport(report.Agent{Action: "start", Socket: path, Running: true, PID: pid}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.AgentStarted, pid, path)))
+}
+
+// serveAgent обслуживает сокет в текущем процессе до остановки агента
+func serveAgent(path string, masterPassword *security.SecureString, messages *i18n.Messages) {
+	// Дамп памяти или отладчик выдали бы мастер-пароль
+	if err := agent.Harden(); err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.AgentStartFailed, err))
+	}
+
+	listener, err := agent.Listen(path)
+	if err != nil {
+		exitWithError(getAgentErrorText(err, messages))
+	}
+
+	server := agent.NewServer(agent.ServerOptions{
+		IdleTimeout: agentIdleTimeoutFlag,
+		MaxLifetime: agentMaxLifetimeFlag,
+	})
+	master := masterPassword.Bytes()
+	err = server.Unlock(master)
+	security.ZeroMemory(master)
+	masterPassword.Clear()
+	if err != nil {
+		listener.Close()
+		os.Remove(path)
+		exitWithError(getAgentErrorText(err, messages))
+	}
+
+	// stderr фонового агента - канал к уже завершённому процессу запуска
+	signal.Ignore(syscall.SIGPIPE)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		select {
+		case <-signals:
+			server.Stop()
+		case <-server.Done():
+		}
+	}()
+
+	fmt.Fprintln(os.Stderr, colors.InfoMsg(fmt.Sprintf(messages.AgentListening, path)))
+	if err := server.Serve(listener); err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.AgentError, err))
+	}
+}
+
+// spawnAgent запускает агент отдельным процессом в фоне. Мастер-пароль
+// передаётся через канал, а не аргументом или переменной окружения, которые
+// видны другим процессам. Возвращает PID агента после его готовности.
+func spawnAgent(path string, masterPassword *security.SecureString, messages *i18n.Messages) int {
+	executable, err := os.Executable()
+	if err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.AgentStartFailed, err))
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.AgentStartFailed, err))
+	}
+
+	child := exec.Command(executable, "agent", "start", "--foreground",
+		"--master-fd", fmt.Sprint(agentMasterFd),
+		"--idle-timeout", agentIdleTimeoutFlag.String(),
+		"--max-lifetime", agentMaxLifetimeFlag.String(),
+		"--lang", string(detectLanguageFromArgs()))
+	child.Env = append(os.Environ(), agent.SocketEnv+"="+path)
+	child.ExtraFiles = []*os.File{reader}
+	var stderr bytes.Buffer
+	child.Stderr = &stderr
+	agent.Detach(child)
+
+	if err := child.Start(); err != nil {
+		reader.Close()
+		writer.Close()
+		exitWithError(fmt.Sprintf("%s %v", messages.AgentStartFailed, err))
+	}
+	reader.Close()
+
+	master := append(masterPassword.Bytes(), '\n')
+	_, err = writer.Write(master)
+	security.ZeroMemory(master)
+	writer.Close()
+	if err != nil {
+		child.Process.Kill()
+		exitWithError(fmt.Sprintf("%s %v", messages.AgentStartFailed, err))
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	deadline := time.After(agentStartTimeout)
+	for {
+		response, err := agent.Call(path, agent.Request{Op: agent.OpStatus})
+		if err == nil && !response.Status.Locked {
+			return response.Status.PID
+		}
+		select {
+		case <-exited:
+			// Агент уже напечатал локализованную причину
+			if reason := strings.TrimSpace(stderr.String()); reason != "" {
+				exitWithError(reason)
+			}
+			exitWithError(messages.AgentStartFailed)
+		case <-deadline:
+			child.Process.Kill()
+			exitWithError(fmt.Sprintf(messages.AgentStartTimeout, agentStartTimeout))
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// readMasterOrPrompt читает мастер-пароль из источника, заданного
+// флагами, а без него - с терминала
+func readMasterOrPrompt(cmd *cobra.Command, messages *i18n.Messages) *security.SecureString {
+	masterPassword, err := readMasterSource(cmd)
+	if err != nil && err.Error() == "master_source_missing" {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			exitWithCode(exitMaster, messages.AgentMasterMissing)
+		}
+		masterPassword, err = promptMasterPassword(messages)
+		if err != nil {
+			exitWithCode(exitMaster, err.Error())
+		}
+	} else if err != nil {
+		exitWithCode(exitMaster, getMasterSourceErrorText(err, messages))
+	}
+
+	if masterPassword.IsEmpty() {
+		masterPassword.Clear()
+		exitWithCode(exitMaster, messages.Errors.EmptyMaster)
+	}
+	return masterPassword
+}
+
+// promptMasterPassword запрашивает мастер-пароль в терминале. Приглашение
+// идёт в stderr, stdout остаётся для результата.
+func promptMasterPassword(messages *i18n.Messages) (*security.SecureString, error) {
+	fmt.Fprint(os.Stderr, colors.PromptMsg(messages.EnterMasterPassword+" "))
+	return input.ReadPasswordWithStarsAndMessages(&input.InputMessages{
+		UserCanceled:  messages.Errors.UserCanceled,
+		InputCanceled: messages.Errors.InputCanceled,
+	})
+}
+
+func runAgentStatusCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	path := agent.DefaultSocketPath()
+	jsonOutput := isJSONOutput(messages)
+
+	response, err := agent.Call(path, agent.Request{Op: agent.OpStatus})
+	if err != nil {
+		if err.Error() == "agent_not_running" && jsonOutput {
+			writeReport(report.Agent{Socket: path}, messages)
+			os.Exit(exitFailure)
+		}
+		exitWithError(getAgentErrorText(err, messages))
+	}
+
+	status := response.Status
+	if jsonOutput {
+		writeReport(report.Agent{
+			Socket:             path,
+			Running:            true,
+			Locked:             status.Locked,
+			PID:                status.PID,
+			IdleTimeoutSeconds: status.IdleTimeoutSeconds,
+			ExpiresInSeconds:   status.ExpiresInSeconds,
+			CachedKeys:         status.CachedKeys,
+		}, messages)
+		return
+	}
+
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.AgentStatusRunning, status.PID, path)))
+	if status.Locked {
+		fmt.Println(colors.InfoMsg(messages.AgentStatusLocked))
+	} else {
+		fmt.Println(colors.InfoMsg(messages.AgentStatusUnlocked))
+	}
+	if status.IdleTimeoutSeconds > 0 {
+		fmt.Println(colors.InfoMsg(fmt.Sprintf(messages.AgentStatusIdle, time.Duration(status.IdleTimeoutSeconds)*time.Second)))
+	}
+	if status.ExpiresInSeconds > 0 {
+		fmt.Println(colors.InfoMsg(fmt.Sprintf(messages.AgentStatusExpires, time.Duration(status.ExpiresInSeconds)*time.Second)))
+	}
+	fmt.Println(colors.InfoMsg(fmt.Sprintf(messages.AgentStatusKeys, status.CachedKeys)))
+}
+
+func runAgentLockCommand(cmd *cobra.Command, args []string) {
+	runAgentAction(agent.Request{Op: agent.OpLock}, "lock", func(m *i18n.Messages) string { return m.AgentLocked })
+}
+
+func runAgentStopCommand(cmd *cobra.Command, args []string) {
+	runAgentAction(agent.Request{Op: agent.OpStop}, "stop", func(m *i18n.Messages) string { return m.AgentStopped })
+}
+
+func runAgentUnlockCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+
+	// Незапущенный агент обнаруживается до ввода мастер-пароля
+	if _, err := agent.Call(agent.DefaultSocketPath(), agent.Request{Op: agent.OpStatus}); err != nil {
+		exitWithError(getAgentErrorText(err, messages))
+	}
+
+	masterPassword := readMasterOrPrompt(cmd, messages)
+	defer masterPassword.Clear()
+	verifyMaster(masterPassword, messages)
+	master := masterPassword.Bytes()
+	defer security.ZeroMemory(master)
+
+	runAgentAction(agent.Request{Op: agent.OpUnlock, Master: master}, "unlock", func(m *i18n.Messages) string { return m.AgentUnlocked })
+}
+
+// runAgentAction отправляет агенту управляющий запрос и печатает результат
+func runAgentAction(request agent.Request, action string, done func(*i18n.Messages) string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	path := agent.DefaultSocketPath()
+
+	if _, err := agent.Call(path, request); err != nil {
+		exitWithError(getAgentErrorText(err, messages))
+	}
+	if isJSONOutput(messages) {
+		writeReport(report.Agent{Action: action, Socket: path, Running: action != "stop"}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(done(messages)))
+}
+
+// getAgentErrorText возвращает текст ошибки агента на соответствующем языке
+func getAgentErrorText(err error, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "agent_not_running":
+		return messages.AgentNotRunning
+	case "agent_running":
+		return messages.AgentAlreadyRunning
+	case "agent_locked":
+		return messages.AgentLockedError
+	case "socket_dir_insecure":
+		return messages.AgentSocketInsecure
+	case "peer_credentials_unsupported":
+		return messages.AgentPeerUnsupported
+	case "peer_denied":
+		return messages.AgentPeerDenied
+	case "agent_peer_foreign":
+		return messages.AgentPeerForeign
+	case "memory_lock_failed":
+		return messages.AgentMemoryLockFailed
+	case "protocol_version":
+		return messages.AgentProtocolMismatch
+	case "master_empty":
+		return messages.Errors.EmptyMaster
+	default:
+		return fmt.Sprintf("%s %v", messages.AgentError, err)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/agent"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+func TestGetAgentErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Не запущен", errors.New("agent_not_running"), messages.AgentNotRunning},
+		{"Уже запущен", errors.New("agent_running"), messages.AgentAlreadyRunning},
+		{"Заблокирован", errors.New("agent_locked"), messages.AgentLockedError},
+		{"Открытый каталог", errors.New("socket_dir_insecure"), messages.AgentSocketInsecure},
+		{"Нет проверки собеседника", errors.New("peer_credentials_unsupported"), messages.AgentPeerUnsupported},
+		{"Чужой пользователь", errors.New("peer_denied"), messages.AgentPeerDenied},
+		{"Нет mlock", errors.New("memory_lock_failed"), messages.AgentMemoryLockFailed},
+		{"Другая версия", errors.New("protocol_version"), messages.AgentProtocolMismatch},
+		{"Неизвестная ошибка", errors.New("boom"), messages.AgentError + " boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getAgentErrorText(tt.err, messages); got != tt.expected {
+				t.Errorf("getAgentErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequestAgentPassword(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	cfg.Username = "testuser"
+
+	dir := filepath.Join(t.TempDir(), "pgen")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatalf("Ошибка создания каталога: %v", err)
+	}
+	path := filepath.Join(dir, "agent.sock")
+	t.Setenv(agent.SocketEnv, path)
+
+	listener, err := agent.Listen(path)
+	if err != nil {
+		t.Skipf("Агент недоступен в окружении: %v", err)
+	}
+	server := agent.NewServer(agent.ServerOptions{})
+	if err := server.Unlock([]byte("testmaster")); err != nil {
+		listener.Close()
+		t.Skipf("mlock недоступен в окружении: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+	defer func() {
+		server.Stop()
+		<-served
+	}()
+
+	opts := pgen.DefaultOptions()
+	opts.Argon = pgen.ArgonParams{Time: 1, Memory: 64 * 1024, Threads: 1, KeyLen: 32}
+	gen, err := pgen.New(opts)
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+	local, err := generatePassword(gen, masterPassword, "github.com", cfg.Username)
+	if err != nil {
+		t.Fatalf("generatePassword() ошибка: %v", err)
+	}
+	defer local.Clear()
+
+	password := requestAgentPassword("github.com", cfg.Username, opts, i18n.GetMessages(i18n.English, "test"))
+	defer password.Clear()
+	if password.String() != local.String() {
+		t.Errorf("Пароль агента %q не совпадает с локальной генерацией %q", password.String(), local.String())
+	}
+}
@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/MaksymLeiber/pgen/internal/batch"
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/input"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// Значения по умолчанию для пакетной генерации
+const (
+	defaultBatchMemoryBudget = 1024 // МиБ: четыре вычисления с параметрами Argon2 по умолчанию
+)
+
+// Флаги пакетной генерации
+var (
+	batchFormatFlag       string
+	batchOutFlag          string
+	batchWorkersFlag      int
+	batchMemoryBudgetFlag int
+	batchLengthFlag       int
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [file]",
+	Short: "",
+	Long:  "",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runBatchCommand,
+}
+
+func init() {
+	batchCmd.Flags().StringVarP(&batchFormatFlag, "format", "f", batch.FormatTable, "")
+	batchCmd.Flags().StringVarP(&batchOutFlag, "out", "o", "", "")
+	batchCmd.Flags().IntVarP(&batchWorkersFlag, "workers", "w", runtime.NumCPU(), "")
+	batchCmd.Flags().IntVarP(&batchMemoryBudgetFlag, "memory-budget", "", defaultBatchMemoryBudget, "")
+	batchCmd.Flags().IntVarP(&batchLengthFlag, "length", "n", 16, "")
+}
+
+// updateBatchCommandTexts обновляет тексты команды пакетной генерации
+func updateBatchCommandTexts(messages *i18n.Messages) {
+	batchCmd.Short = messages.BatchShort
+	batchCmd.Long = messages.BatchLong
+
+	flagDescs := map[string]string{
+		"format":        messages.BatchFormatFlagDesc,
+		"out":           messages.BatchOutFlagDesc,
+		"workers":       messages.BatchWorkersFlagDesc,
+		"memory-budget": messages.BatchMemoryBudgetFlagDesc,
+		"length":        messages.BatchLengthFlagDesc,
+	}
+	for name, usage := range flagDescs {
+		if flag := batchCmd.Flags().Lookup(name); flag != nil {
+			flag.Usage = usage
+		}
+	}
+}
+
+func runBatchCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	validateUserFlag(cmd, messages)
+
+	format, err := batch.ParseFormat(batchFormatFlag)
+	if err != nil {
+		exitWithError(messages.BatchUnknownFormat)
+	}
+	// --output json без явного --format выбирает JSON и для списка паролей
+	if isJSONOutput(messages) && !cmd.Flags().Changed("format") {
+		format = batch.FormatJSON
+	}
+	if batchWorkersFlag < 1 || batchMemoryBudgetFlag < 1 {
+		exitWithError(messages.BatchInvalidWorkers)
+	}
+
+	length := batchLengthFlag
+	if !cmd.Flags().Changed("length") {
+		length = cfg.DefaultLength
+	}
+	if err := generator.ValidateLength(length); err != nil {
+		exitWithError(getLengthErrorText(err, messages))
+	}
+
+	// Проверяем настройки генератора до ввода мастер-пароля
+	if _, err := newBatchGenerator(batch.Entry{Length: length, Counter: 1}, nil); err != nil {
+		exitWithError(getGeneratorErrorText(err, messages))
+	}
+
+	masterPassword, services, err := readBatchInput(args, messages)
+	if err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.BatchReadError, err))
+	}
+	defer masterPassword.Clear()
+
+	if masterPassword.IsEmpty() {
+		exitWithError(messages.Errors.EmptyMaster)
+	}
+
+	entries, err := batch.Parse(services)
+	if closer, ok := services.(io.Closer); ok {
+		closer.Close()
+	}
+	if err != nil {
+		exitWithError(getBatchParseErrorText(err, messages))
+	}
+	verifyMaster(masterPassword, messages)
+	unlockVault(masterPassword, messages)
+	defer closeVault()
+	unlockServiceRecord(masterPassword, messages)
+	defer closeServiceRecord()
+	for i := range entries {
+		if entries[i].Service, err = canonicalService(entries[i].Service); err != nil {
+			exitWithError(getGeneratorErrorText(err, messages))
+		}
+	}
+	sites, _ := loadSites(messages)
+	applyUserFlag(entries)
+	applySiteDefaults(entries, sites, !cmd.Flags().Changed("length"))
+	warnServiceTypos(entries, sites, messages)
+	batch.ApplyDefaults(entries, batch.Entry{Length: length, Counter: 1, Username: defaultUsername()})
+
+	workers := batch.Workers(cfg.ArgonMemory, batchMemoryBudgetFlag, batchWorkersFlag)
+	fmt.Fprintf(os.Stderr, "%s\n", colors.SubtleMsg(fmt.Sprintf(messages.BatchWorkersInfo, workers, batchMemoryBudgetFlag)))
+
+	masterKeys := newBatchMasterKeys(masterPassword)
+	defer masterKeys.clear()
+
+	results := batch.Run(entries, workers, func(entry batch.Entry) (*security.SecureString, error) {
+		gen, err := newBatchGenerator(entry, findSite(sites, entry.Service))
+		if err != nil {
+			return nil, err
+		}
+		if gen.Options().Algorithm == pgen.AlgorithmV3 {
+			// Один Argon2 на пользователя вместо одного на строку
+			masterKey, err := masterKeys.get(gen, entry.Username)
+			if err != nil {
+				return nil, err
+			}
+			return generateWithMasterKey(gen, masterKey, entry.Service)
+		}
+		return generatePassword(gen, masterPassword, entry.Service, entry.Username)
+	})
+
+	records := make([]batch.Record, len(results))
+	var used []string
+	generated := 0
+	for i, result := range results {
+		records[i] = batch.Record{
+			Service:  result.Entry.Service,
+			Username: result.Entry.Username,
+			Length:   result.Entry.Length,
+			Counter:  result.Entry.Counter,
+		}
+		if result.Err != nil {
+			records[i].Error = getGeneratorErrorText(result.Err, messages)
+			continue
+		}
+		records[i].Password = result.Password.String()
+		result.Password.Clear()
+		used = append(used, result.Entry.Service)
+		generated++
+	}
+	rememberServices(messages, used...)
+
+	if err := writeBatchRecords(format, records, messages); err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.BatchWriteError, err))
+	}
+
+	fmt.Fprintf(os.Stderr, "%s\n", colors.SubtleMsg(fmt.Sprintf(messages.BatchGenerated, generated, len(records))))
+	if generated < len(records) {
+		os.Exit(1)
+	}
+}
+
+// readBatchInput читает мастер-пароль и открывает список сервисов. Если список
+// приходит через stdin без терминала, мастер-пароль - его первая строка.
+func readBatchInput(args []string, messages *i18n.Messages) (*security.SecureString, io.Reader, error) {
+	fromStdin := len(args) == 0 || args[0] == "-"
+
+	var services io.Reader
+	if !fromStdin {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		services = file
+	}
+
+	if fromStdin && !term.IsTerminal(int(os.Stdin.Fd())) {
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, nil, err
+		}
+		masterPassword := security.NewSecureString(strings.TrimRight(line, "\r\n"))
+		security.SecureWipe([]byte(line))
+		return masterPassword, reader, nil
+	}
+
+	fmt.Fprint(os.Stderr, colors.PromptMsg(messages.EnterMasterPassword+" "))
+	masterPassword, err := input.ReadPasswordWithStarsAndMessages(&input.InputMessages{
+		UserCanceled:  messages.Errors.UserCanceled,
+		InputCanceled: messages.Errors.InputCanceled,
+	})
+	if err != nil {
+		if closer, ok := services.(io.Closer); ok {
+			closer.Close()
+		}
+		return nil, nil, err
+	}
+
+	if fromStdin {
+		services = os.Stdin
+	}
+	return masterPassword, services, nil
+}
+
+// applySiteDefaults заполняет незаданные в строке параметры из реестра сайтов.
+// Длина сайта берётся, только если она не задана флагом --length
+func applySiteDefaults(entries []batch.Entry, sites *site.Registry, siteLength bool) {
+	for i := range entries {
+		s := findSite(sites, entries[i].Service)
+		if s == nil {
+			continue
+		}
+		if entries[i].Length == 0 && siteLength {
+			entries[i].Length = s.Length
+		}
+		if entries[i].Counter == 0 {
+			entries[i].Counter = s.Counter
+		}
+		if entries[i].Username == "" {
+			entries[i].Username = s.Username
+		}
+	}
+}
+
+// applyUserFlag подставляет --user в строки без имени пользователя. Флаг
+// перекрывает имя из реестра, но не user= в самой строке.
+func applyUserFlag(entries []batch.Entry) {
+	username := strings.TrimSpace(userFlag)
+	if username == "" {
+		return
+	}
+	for i := range entries {
+		if entries[i].Username == "" {
+			entries[i].Username = username
+		}
+	}
+}
+
+// warnServiceTypos предупреждает о новых именах, похожих на известные сервисы.
+// Пакетная генерация не задаёт вопросов, поэтому пароли всё равно создаются.
+func warnServiceTypos(entries []batch.Entry, sites *site.Registry, messages *i18n.Messages) {
+	record, _, _ := loadServiceRecord()
+	for _, entry := range entries {
+		if suggestions := serviceSuggestions(entry.Service, record, sites); len(suggestions) > 0 {
+			fmt.Fprintf(os.Stderr, "%s\n", colors.SubtleMsg(fmt.Sprintf(messages.ServiceTypo, entry.Service, strings.Join(suggestions, ", "))))
+		}
+	}
+}
+
+// newBatchGenerator создаёт генератор строки по настройкам конфигурации
+// и сайта из реестра
+func newBatchGenerator(entry batch.Entry, s *site.Site) (*pgen.Generator, error) {
+	opts, err := configGeneratorOptions(entry.Length)
+	if err != nil {
+		return nil, err
+	}
+	opts.Counter = entry.Counter
+	if err := applySiteOptions(&opts, s); err != nil {
+		return nil, err
+	}
+	if opts.Mode == pgen.ModePassphrase {
+		opts.Passphrase = sitePassphraseOptions(s)
+	}
+	return pgen.New(opts)
+}
+
+// batchMasterKeys кеш мастер-ключей v3 по имени пользователя
+type batchMasterKeys struct {
+	masterPassword *security.SecureString
+	mu             sync.Mutex
+	keys           map[string]*batchMasterKey
+}
+
+type batchMasterKey struct {
+	once sync.Once
+	key  *pgen.MasterKey
+	err  error
+}
+
+func newBatchMasterKeys(masterPassword *security.SecureString) *batchMasterKeys {
+	return &batchMasterKeys{
+		masterPassword: masterPassword,
+		keys:           make(map[string]*batchMasterKey),
+	}
+}
+
+// get возвращает мастер-ключ пользователя, вычисляя его один раз
+func (c *batchMasterKeys) get(gen *pgen.Generator, username string) (*pgen.MasterKey, error) {
+	c.mu.Lock()
+	entry, ok := c.keys[username]
+	if !ok {
+		entry = &batchMasterKey{}
+		c.keys[username] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		master := c.masterPassword.Bytes()
+		defer security.ZeroMemory(master)
+		entry.key, entry.err = gen.DeriveMasterKey(context.Background(), master, username)
+	})
+	return entry.key, entry.err
+}
+
+func (c *batchMasterKeys) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range c.keys {
+		entry.key.Clear()
+	}
+}
+
+// writeBatchRecords выводит результат в stdout или в файл с правами 0600
+func writeBatchRecords(format string, records []batch.Record, messages *i18n.Messages) error {
+	labels := batch.Labels{
+		Service:  messages.BatchColumnService,
+		Username: messages.BatchColumnUsername,
+		Counter:  messages.BatchColumnCounter,
+		Password: messages.BatchColumnPassword,
+		Error:    messages.BatchErrorPrefix,
+	}
+
+	if batchOutFlag == "" {
+		return batch.Write(os.Stdout, format, records, labels)
+	}
+
+	file, err := os.OpenFile(batchOutFlag, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := batch.Write(file, format, records, labels); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %s %s\n", colors.SuccessMsg("✓"), messages.BatchWrittenTo, batchOutFlag)
+	return nil
+}
+
+// getBatchParseErrorText возвращает локализованный текст ошибки списка сервисов
+func getBatchParseErrorText(err error, messages *i18n.Messages) string {
+	var parseErr *batch.ParseError
+	if !errors.As(err, &parseErr) {
+		if err.Error() == "batch_no_services" {
+			return messages.BatchNoServices
+		}
+		return fmt.Sprintf("%s %v", messages.BatchReadError, err)
+	}
+
+	text := parseErr.Code
+	switch parseErr.Code {
+	case "batch_unknown_override":
+		text = messages.BatchUnknownOverride
+	case "batch_invalid_length":
+		text = messages.BatchInvalidLength
+	case "batch_invalid_counter":
+		text = messages.BatchInvalidCounter
+	case "batch_empty_username":
+		text = messages.BatchEmptyUsername
+	}
+	return fmt.Sprintf(messages.BatchLineError, parseErr.Line, text)
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/batch"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+func TestGetBatchParseErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Неизвестный параметр", &batch.ParseError{Line: 3, Code: "batch_unknown_override"}, fmt.Sprintf(messages.BatchLineError, 3, messages.BatchUnknownOverride)},
+		{"Длина", &batch.ParseError{Line: 1, Code: "batch_invalid_length"}, fmt.Sprintf(messages.BatchLineError, 1, messages.BatchInvalidLength)},
+		{"Счётчик", &batch.ParseError{Line: 2, Code: "batch_invalid_counter"}, fmt.Sprintf(messages.BatchLineError, 2, messages.BatchInvalidCounter)},
+		{"Пользователь", &batch.ParseError{Line: 4, Code: "batch_empty_username"}, fmt.Sprintf(messages.BatchLineError, 4, messages.BatchEmptyUsername)},
+		{"Пустой список", errors.New("batch_no_services"), messages.BatchNoServices},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBatchParseErrorText(tt.err, messages); got != tt.expected {
+				t.Errorf("getBatchParseErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewBatchGenerator(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+
+	cfg = config.DefaultConfig()
+	cfg.PasswordType = "pin"
+	gen, err := newBatchGenerator(batch.Entry{Length: 20, Counter: 3}, nil)
+	if err != nil {
+		t.Fatalf("newBatchGenerator() ошибка: %v", err)
+	}
+	opts := gen.Options()
+	if opts.Mode != pgen.ModeTemplate || opts.Type != pgen.TypePIN {
+		t.Errorf("Шаблон из конфигурации не применён: режим %q, тип %q", opts.Mode, opts.Type)
+	}
+	if opts.Counter != 3 || opts.Length != 20 {
+		t.Errorf("Параметры строки не применены: счётчик %d, длина %d", opts.Counter, opts.Length)
+	}
+	if string(opts.Algorithm) != cfg.Algorithm {
+		t.Errorf("Algorithm = %s, ожидается %s", opts.Algorithm, cfg.Algorithm)
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.Algorithm = "v9"
+	if _, err := newBatchGenerator(batch.Entry{Length: 16, Counter: 1}, nil); !errors.Is(err, pgen.ErrUnknownAlgorithm) {
+		t.Errorf("newBatchGenerator() ошибка = %v, ожидается ErrUnknownAlgorithm", err)
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.RequiredClasses = "lower,upper,digit,symbol"
+	if _, err := newBatchGenerator(batch.Entry{Length: 16, Counter: 1}, nil); err != nil {
+		t.Errorf("newBatchGenerator() ошибка: %v", err)
+	}
+}
+
+func TestBatchMasterKeys(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+
+	cfg = config.DefaultConfig()
+	cfg.Algorithm = string(pgen.AlgorithmV3)
+	cfg.ArgonTime = 1
+	cfg.ArgonMemory = 64 * 1024
+	cfg.ArgonThreads = 1
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	keys := newBatchMasterKeys(masterPassword)
+	defer keys.clear()
+
+	gen, err := newBatchGenerator(batch.Entry{Length: 16, Counter: 1}, nil)
+	if err != nil {
+		t.Fatalf("newBatchGenerator() ошибка: %v", err)
+	}
+
+	first, err := keys.get(gen, "alice")
+	if err != nil {
+		t.Fatalf("get() ошибка: %v", err)
+	}
+	if again, _ := keys.get(gen, "alice"); again != first {
+		t.Error("Мастер-ключ пользователя вычислен повторно")
+	}
+	if other, _ := keys.get(gen, "bob"); other == first {
+		t.Error("Разные пользователи получили один мастер-ключ")
+	}
+
+	// Пароль из кешированного ключа совпадает с обычной генерацией
+	cached, err := generateWithMasterKey(gen, first, "github.com")
+	if err != nil {
+		t.Fatalf("generateWithMasterKey() ошибка: %v", err)
+	}
+	defer cached.Clear()
+	direct, err := generatePassword(gen, masterPassword, "github.com", "alice")
+	if err != nil {
+		t.Fatalf("generatePassword() ошибка: %v", err)
+	}
+	defer direct.Clear()
+	if cached.String() != direct.String() {
+		t.Error("Пароль из кеша мастер-ключей отличается от обычной генерации")
+	}
+}
+
+func TestWriteBatchRecordsToFile(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+	savedOut := batchOutFlag
+	defer func() { batchOutFlag = savedOut }()
+
+	batchOutFlag = filepath.Join(t.TempDir(), "passwords.csv")
+	records := []batch.Record{{Service: "github.com", Username: "user", Length: 16, Counter: 1, Password: "secret"}}
+	if err := writeBatchRecords(batch.FormatCSV, records, messages); err != nil {
+		t.Fatalf("writeBatchRecords() ошибка: %v", err)
+	}
+
+	info, err := os.Stat(batchOutFlag)
+	if err != nil {
+		t.Fatalf("Файл результата не создан: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Права файла = %o, ожидается 600", info.Mode().Perm())
+	}
+}
+
+func TestApplyUserFlag(t *testing.T) {
+	savedCfg, savedUser := cfg, userFlag
+	defer func() { cfg, userFlag = savedCfg, savedUser }()
+	cfg = config.DefaultConfig()
+
+	sites := site.New()
+	sites.Sites = []*site.Site{{Service: "github.com", Username: "alice"}}
+	newEntries := func() []batch.Entry {
+		return []batch.Entry{{Service: "github.com"}, {Service: "gitlab.com", Username: "carol"}}
+	}
+
+	// Без --user имя берётся из реестра
+	userFlag = ""
+	entries := newEntries()
+	applyUserFlag(entries)
+	applySiteDefaults(entries, sites, true)
+	if entries[0].Username != "alice" || entries[1].Username != "carol" {
+		t.Errorf("Имена без --user = %q, %q", entries[0].Username, entries[1].Username)
+	}
+
+	// --user перекрывает реестр, но не user= в строке
+	userFlag = "bob"
+	entries = newEntries()
+	applyUserFlag(entries)
+	applySiteDefaults(entries, sites, true)
+	if entries[0].Username != "bob" || entries[1].Username != "carol" {
+		t.Errorf("Имена с --user = %q, %q", entries[0].Username, entries[1].Username)
+	}
+}
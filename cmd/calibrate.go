@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/calibrate"
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/report"
+)
+
+// Флаги калибровки
+var (
+	calibrateTargetFlag    time.Duration
+	calibrateMaxMemoryFlag string
+	calibrateThreadsFlag   uint8
+	calibrateApplyFlag     bool
+)
+
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "",
+	Long:  "",
+	Args:  cobra.NoArgs,
+	Run:   runCalibrateCommand,
+}
+
+func init() {
+	calibrateCmd.Flags().DurationVarP(&calibrateTargetFlag, "target", "", time.Second, "")
+	calibrateCmd.Flags().StringVarP(&calibrateMaxMemoryFlag, "max-memory", "", "512MiB", "")
+	calibrateCmd.Flags().Uint8VarP(&calibrateThreadsFlag, "threads", "", 0, "")
+	calibrateCmd.Flags().BoolVarP(&calibrateApplyFlag, "apply", "", false, "")
+}
+
+// updateCalibrateCommandTexts обновляет тексты команды калибровки
+func updateCalibrateCommandTexts(messages *i18n.Messages) {
+	calibrateCmd.Short = messages.CalibrateShort
+	calibrateCmd.Long = messages.CalibrateLong
+
+	flagTexts := map[string]string{
+		"target":     messages.CalibrateTargetFlagDesc,
+		"max-memory": messages.CalibrateMaxMemoryFlagDesc,
+		"threads":    messages.CalibrateThreadsFlagDesc,
+		"apply":      messages.CalibrateApplyFlagDesc,
+	}
+	for name, usage := range flagTexts {
+		if flag := calibrateCmd.Flags().Lookup(name); flag != nil {
+			flag.Usage = usage
+		}
+	}
+}
+
+func runCalibrateCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	maxMemory, err := calibrate.ParseMemory(calibrateMaxMemoryFlag)
+	if err != nil {
+		exitWithCode(exitUsage, getCalibrateErrorText(err, messages))
+	}
+	threads := calibrateThreadsFlag
+	if threads == 0 {
+		threads = cfg.ArgonThreads
+	}
+	opts := calibrate.Options{Target: calibrateTargetFlag, MaxMemoryKiB: maxMemory, Threads: threads}
+	if err := calibrate.Validate(opts); err != nil {
+		exitWithCode(exitUsage, getCalibrateErrorText(err, messages))
+	}
+	jsonOutput := isJSONOutput(messages)
+
+	fmt.Fprintln(os.Stderr, colors.SubtleMsg(fmt.Sprintf(messages.CalibrateRunning, opts.Target, maxMemory/1024, threads)))
+	results := calibrate.Run(opts, calibrate.Argon2Bench)
+	best, fits := calibrate.Recommend(results, opts.Target)
+
+	current := report.Argon{Time: cfg.ArgonTime, MemoryKiB: cfg.ArgonMemory, Threads: cfg.ArgonThreads, KeyLen: cfg.ArgonKeyLen}
+	recommended := report.Argon{Time: best.Time, MemoryKiB: best.MemoryKiB, Threads: best.Threads, KeyLen: cfg.ArgonKeyLen}
+	unchanged := recommended == current
+	profile := config.CurrentProfile()
+
+	if !jsonOutput {
+		printCalibrationTable(results, best, messages)
+		fmt.Println()
+		if !fits {
+			fmt.Println(colors.ErrorMsg(fmt.Sprintf(messages.CalibrateNoneFits, opts.Target)))
+		}
+		fmt.Println(colors.InfoMsg(fmt.Sprintf(messages.CalibrateRecommended, best.Time, best.MemoryKiB/1024, best.Threads,
+			best.Duration.Round(time.Millisecond))))
+		fmt.Println(colors.SubtleMsg(fmt.Sprintf(messages.CalibrateCurrent, profile, current.Time, current.MemoryKiB/1024, current.Threads)))
+	}
+	if !unchanged {
+		fmt.Fprintf(os.Stderr, "\n%s %s\n", colors.ErrorMsg("⚠️"), colors.ErrorMsg(messages.CalibrateWarning))
+	}
+
+	applied := false
+	if calibrateApplyFlag && !unchanged {
+		old := cfg.Derivation()
+		cfg.ArgonTime, cfg.ArgonMemory, cfg.ArgonThreads = best.Time, best.MemoryKiB, best.Threads
+		if guardDerivationChange(old, configForceFlag, messages) {
+			if err := cfg.Save(messages); err != nil {
+				exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.ConfigErrorSaving, err))
+			}
+			applied = true
+		} else {
+			cfg.ApplyDerivation(old)
+		}
+	}
+
+	if jsonOutput {
+		runs := make([]report.CalibrationRun, 0, len(results))
+		for _, m := range results {
+			runs = append(runs, report.CalibrationRun{
+				Time:       m.Time,
+				MemoryKiB:  m.MemoryKiB,
+				Threads:    m.Threads,
+				DurationMs: m.Duration.Milliseconds(),
+				Fits:       m.Duration <= opts.Target,
+			})
+		}
+		writeReport(report.Calibration{
+			TargetMs:     opts.Target.Milliseconds(),
+			MaxMemoryKiB: maxMemory,
+			Runs:         runs,
+			Recommended:  recommended,
+			Fits:         fits,
+			Current:      current,
+			Applied:      applied,
+		}, messages)
+		return
+	}
+
+	switch {
+	case applied:
+		fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.CalibrateApplied, profile, best.Time, best.MemoryKiB/1024, best.Threads)))
+	case unchanged:
+		fmt.Println(colors.SuccessMsg(messages.CalibrateUnchanged))
+	case !calibrateApplyFlag:
+		fmt.Println(colors.SubtleMsg(messages.CalibrateApplyHint))
+	}
+}
+
+// printCalibrationTable выводит замеры таблицей, рекомендуемые параметры
+// отмечены звёздочкой
+func printCalibrationTable(results []calibrate.Measurement, best calibrate.Measurement, messages *i18n.Messages) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\n", messages.CalibrateColumnTime, messages.CalibrateColumnMemory,
+		messages.CalibrateColumnThreads, messages.CalibrateColumnDuration)
+	for _, m := range results {
+		marker := " "
+		if m.Params == best.Params {
+			marker = "*"
+		}
+		fmt.Fprintf(writer, "%s %d\t%d MiB\t%d\t%s\n", marker, m.Time, m.MemoryKiB/1024, m.Threads,
+			m.Duration.Round(time.Millisecond))
+	}
+	writer.Flush()
+}
+
+// getCalibrateErrorText возвращает текст ошибки калибровки на соответствующем языке
+func getCalibrateErrorText(err error, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "memory_invalid":
+		return fmt.Sprintf(messages.CalibrateMemoryInvalid, calibrateMaxMemoryFlag)
+	case "memory_range":
+		return fmt.Sprintf(messages.CalibrateMemoryRange, calibrate.MinMemoryKiB/1024, calibrate.MaxMemoryKiB/1024)
+	case "target_invalid":
+		return fmt.Sprintf(messages.CalibrateTargetInvalid, calibrate.MinTarget, calibrate.MaxTarget)
+	case "threads_invalid":
+		return messages.CalibrateThreadsInvalid
+	default:
+		return fmt.Sprintf("%s %v", messages.CalibrateError, err)
+	}
+}
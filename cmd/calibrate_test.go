@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/calibrate"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+)
+
+func TestGetCalibrateErrorText(t *testing.T) {
+	savedMemory := calibrateMaxMemoryFlag
+	defer func() { calibrateMaxMemoryFlag = savedMemory }()
+	calibrateMaxMemoryFlag = "12XB"
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Неверная память", errors.New("memory_invalid"), fmt.Sprintf(messages.CalibrateMemoryInvalid, "12XB")},
+		{"Предел памяти", errors.New("memory_range"), fmt.Sprintf(messages.CalibrateMemoryRange, 16, 4096)},
+		{"Цель", errors.New("target_invalid"), fmt.Sprintf(messages.CalibrateTargetInvalid, calibrate.MinTarget, calibrate.MaxTarget)},
+		{"Потоки", errors.New("threads_invalid"), messages.CalibrateThreadsInvalid},
+		{"Другая ошибка", errors.New("boom"), messages.CalibrateError + " boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getCalibrateErrorText(tt.err, messages); got != tt.expected {
+				t.Errorf("getCalibrateErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// Флаги защиты от незаметной смены паролей
+var (
+	configForceFlag bool
+	snapshotFlag    int
+)
+
+var configHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runConfigHistoryCommand,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{configSetCmd, configImportCmd, configResetCmd, calibrateCmd, vaultUserCmd} {
+		cmd.Flags().BoolVarP(&configForceFlag, "force", "", false, "")
+	}
+	getCmd.Flags().IntVarP(&snapshotFlag, "snapshot", "", 0, "")
+	configCmd.AddCommand(configHistoryCmd)
+}
+
+// updateDriftCommandTexts обновляет тексты флагов защиты и команды снимков
+func updateDriftCommandTexts(messages *i18n.Messages) {
+	configHistoryCmd.Short = messages.HistoryShort
+	for _, cmd := range []*cobra.Command{configSetCmd, configImportCmd, configResetCmd, calibrateCmd, vaultUserCmd} {
+		if flag := cmd.Flags().Lookup("force"); flag != nil {
+			flag.Usage = messages.DriftForceFlagDesc
+		}
+	}
+	if flag := getCmd.Flags().Lookup("snapshot"); flag != nil {
+		flag.Usage = messages.SnapshotFlagDesc
+	}
+}
+
+// guardDerivationChange защищает от незаметной смены паролей: old - настройки
+// до изменения, cfg уже изменена. Если пароли изменятся, показывает изменения
+// и затронутые сервисы, спрашивает подтверждение (без терминала нужен --force)
+// и сохраняет снимок прежних настроек. Возвращает false при отказе.
+func guardDerivationChange(old config.Derivation, force bool, messages *i18n.Messages) bool {
+	changes := config.DerivationChanges(old, cfg.Derivation())
+	if len(changes) == 0 {
+		return true
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorMsg("⚠️"), colors.ErrorMsg(messages.DriftWarning))
+	for _, change := range changes {
+		fmt.Fprintf(os.Stderr, "  %s: %s → %s\n", change.Key, change.Old, change.New)
+	}
+	if vaultEnabled() {
+		fmt.Fprintln(os.Stderr, colors.SubtleMsg(messages.DriftVaultSites))
+	} else {
+		registry, _ := loadSites(messages)
+		if affected := affectedSites(registry, old, cfg.Derivation()); len(affected) > 0 {
+			fmt.Fprintln(os.Stderr, colors.SubtleMsg(fmt.Sprintf(messages.DriftSites, len(affected), strings.Join(affected, ", "))))
+		}
+		fmt.Fprintln(os.Stderr, colors.SubtleMsg(messages.DriftOtherServices))
+	}
+
+	if !force && !confirmAction(messages.DriftConfirm, messages.DriftConfirmRequired) {
+		fmt.Fprintln(os.Stderr, colors.InfoMsg(messages.DriftCancelled))
+		return false
+	}
+	saveDerivationSnapshot(old, messages)
+	return true
+}
+
+// saveDerivationSnapshot сохраняет снимок прежних настроек и сообщает его номер.
+// При закрытом хранилище имя пользователя в конфигурации не настоящее: снимок
+// сохраняется без имени, и --snapshot берёт имя из хранилища.
+func saveDerivationSnapshot(old config.Derivation, messages *i18n.Messages) {
+	if vaultEnabled() && openedVault == nil {
+		old.Username = ""
+	}
+	n, err := config.SaveDerivationSnapshot(old)
+	if err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.DriftSnapshotError, err))
+	}
+	fmt.Fprintln(os.Stderr, colors.SubtleMsg(fmt.Sprintf(messages.DriftSnapshotSaved, n, n)))
+}
+
+// affectedSites возвращает сервисы реестра, пароли которых различаются
+// при настройках old и new
+func affectedSites(registry *site.Registry, old, new config.Derivation) []string {
+	var affected []string
+	for _, s := range registry.Sites {
+		if !reflect.DeepEqual(siteDerivation(s, old), siteDerivation(s, new)) {
+			affected = append(affected, s.Service)
+		}
+	}
+	return affected
+}
+
+// siteDerivationResult всё, от чего зависит пароль сервиса
+type siteDerivationResult struct {
+	service  string
+	opts     pgen.Options
+	username string
+	err      string
+}
+
+// siteDerivation возвращает имя сервиса для генерации, параметры генератора
+// и имя пользователя сервиса при настройках d. Параметры, которые режим
+// генерации не использует, обнуляются: их смена пароль не меняет.
+func siteDerivation(s *site.Site, d config.Derivation) siteDerivationResult {
+	service, err := generator.CanonicalizeService(s.Service, generator.ServiceCanonical(d.ServiceCanonical))
+	if err != nil {
+		return siteDerivationResult{err: err.Error()}
+	}
+
+	length := d.DefaultLength
+	if s.Length > 0 {
+		length = s.Length
+	}
+	opts, err := derivationGeneratorOptions(d, length)
+	if err == nil {
+		err = applySiteOptions(&opts, s)
+	}
+	if err != nil {
+		return siteDerivationResult{err: err.Error()}
+	}
+
+	if opts.Mode != pgen.ModePassword {
+		opts.Length, opts.Charset, opts.RequiredClasses = 0, "", nil
+	}
+	if opts.Mode != pgen.ModeTemplate {
+		opts.Type = ""
+	}
+	if opts.Mode == pgen.ModePassphrase {
+		opts.Passphrase = derivationPassphraseOptions(d, s)
+	}
+	username := d.Username
+	if s.Username != "" {
+		username = s.Username
+	}
+	return siteDerivationResult{service: service, opts: opts, username: username}
+}
+
+// applySnapshotFlag подменяет настройки конфигурации снимком из --snapshot.
+// Конфигурация после этого не сохраняется. Вызывается после открытия
+// хранилища: имя пользователя из снимка важнее имени из хранилища, снимок без
+// имени оставляет имя из хранилища.
+func applySnapshotFlag(messages *i18n.Messages) {
+	if snapshotFlag == 0 {
+		return
+	}
+	snapshot, err := config.DerivationSnapshotAt(snapshotFlag)
+	if err != nil {
+		exitWithCode(exitUsage, getHistoryErrorText(err, messages))
+	}
+	derivation := snapshot.Derivation
+	if derivation.Username == "" {
+		derivation.Username = cfg.Username
+	} else if openedVault != nil {
+		vaultUsername = derivation.Username
+	}
+	cfg.ApplyDerivation(derivation)
+	fmt.Fprintln(os.Stderr, colors.SubtleMsg(fmt.Sprintf(messages.SnapshotApplied, snapshotFlag,
+		snapshot.ReplacedAt.Format("2006-01-02 15:04"))))
+}
+
+func runConfigHistoryCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	snapshots, err := config.DerivationHistory()
+	if err != nil {
+		exitWithCode(exitConfig, getHistoryErrorText(err, messages))
+	}
+
+	if isJSONOutput(messages) {
+		if snapshots == nil {
+			snapshots = []config.DerivationSnapshot{}
+		}
+		writeReport(snapshots, messages)
+		return
+	}
+	if len(snapshots) == 0 {
+		fmt.Println(colors.InfoMsg(messages.HistoryEmpty))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "#\t%s\tARGON2\t%s\t%s\t%s\t%s\n", messages.HistoryColumnReplaced, messages.HistoryColumnUser,
+		messages.HistoryColumnAlgorithm, messages.HistoryColumnCharset, messages.HistoryColumnLength)
+	for i, snapshot := range snapshots {
+		username := snapshot.Username
+		if username == "" {
+			username = messages.VaultHiddenUser
+		}
+		fmt.Fprintf(writer, "%d\t%s\tt=%d m=%dMiB p=%d\t%s\t%s\t%s\t%d\n", i+1,
+			snapshot.ReplacedAt.Format("2006-01-02 15:04"),
+			snapshot.ArgonTime, snapshot.ArgonMemory/1024, snapshot.ArgonThreads,
+			username, snapshot.Algorithm, snapshot.CharacterSet, snapshot.DefaultLength)
+	}
+	writer.Flush()
+}
+
+// getHistoryErrorText возвращает текст ошибки снимков настроек на соответствующем языке
+func getHistoryErrorText(err error, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "snapshot_not_found":
+		return fmt.Sprintf(messages.SnapshotNotFound, snapshotFlag)
+	case "history_invalid":
+		return messages.HistoryInvalid
+	case "history_version":
+		return messages.HistoryVersion
+	default:
+		return fmt.Sprintf("%s %v", messages.HistoryLoadError, err)
+	}
+}
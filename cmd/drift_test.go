@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
+)
+
+func TestAffectedSites(t *testing.T) {
+	registry := site.New()
+	registry.Sites = []*site.Site{
+		{Service: "github.com"},
+		{Service: "gitlab.com", Length: 20},
+		{Service: "mail.ru", Username: "bob"},
+		{Service: "bank.com", Type: "pin"},
+		{Service: "notes", Passphrase: true},
+	}
+	old := config.DefaultConfig().Derivation()
+
+	tests := []struct {
+		name     string
+		change   func(d *config.Derivation)
+		expected []string
+	}{
+		{"Параметры Argon2", func(d *config.Derivation) { d.ArgonMemory = 64 * 1024 },
+			[]string{"github.com", "gitlab.com", "mail.ru", "bank.com", "notes"}},
+		{"Имя пользователя", func(d *config.Derivation) { d.Username = "alice" },
+			[]string{"github.com", "gitlab.com", "bank.com", "notes"}},
+		{"Длина по умолчанию", func(d *config.Derivation) { d.DefaultLength = 24 },
+			[]string{"github.com", "mail.ru"}},
+		{"Набор символов", func(d *config.Derivation) { d.CharacterSet = "alphanumeric" },
+			[]string{"github.com", "gitlab.com", "mail.ru"}},
+		{"Число слов фразы", func(d *config.Derivation) { d.PassphraseWords = 8 },
+			[]string{"notes"}},
+		{"Словарь фразы", func(d *config.Derivation) { d.PassphraseWordlist = "ru" },
+			[]string{"notes"}},
+		{"Цифра во фразе", func(d *config.Derivation) { d.PassphraseDigit = true },
+			[]string{"notes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := old
+			tt.change(&updated)
+			got := affectedSites(registry, old, updated)
+			if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("affectedSites() = %v, ожидается %v", got, tt.expected)
+			}
+		})
+	}
+
+	if got := affectedSites(registry, old, old); len(got) != 0 {
+		t.Errorf("affectedSites() без изменений = %v", got)
+	}
+}
+
+func TestAffectedSitesServiceNames(t *testing.T) {
+	registry := site.New()
+	registry.Sites = []*site.Site{
+		{Service: "github.com"},
+		{Service: "https://accounts.google.com"},
+		{Service: "Work VPN"},
+	}
+	old := config.DefaultConfig().Derivation()
+
+	// Пароль меняется только у сервисов, имя которых приводится иначе
+	domain := old
+	domain.ServiceCanonical = "domain"
+	if got := affectedSites(registry, old, domain); fmt.Sprint(got) != "[https://accounts.google.com]" {
+		t.Errorf("affectedSites() при service_canonical=domain = %v", got)
+	}
+	off := old
+	off.ServiceCanonical = "off"
+	if got := affectedSites(registry, old, off); fmt.Sprint(got) != "[https://accounts.google.com Work VPN]" {
+		t.Errorf("affectedSites() при service_canonical=off = %v", got)
+	}
+}
+
+func TestGetHistoryErrorText(t *testing.T) {
+	savedSnapshot := snapshotFlag
+	defer func() { snapshotFlag = savedSnapshot }()
+	snapshotFlag = 3
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Нет снимка", errors.New("snapshot_not_found"), fmt.Sprintf(messages.SnapshotNotFound, 3)},
+		{"Повреждён", errors.New("history_invalid"), messages.HistoryInvalid},
+		{"Новая версия", errors.New("history_version"), messages.HistoryVersion},
+		{"Другая ошибка", errors.New("boom"), messages.HistoryLoadError + " boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getHistoryErrorText(tt.err, messages); got != tt.expected {
+				t.Errorf("getHistoryErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSnapshotUsernameWithVault(t *testing.T) {
+	savedCfg, savedSnapshot := cfg, snapshotFlag
+	defer func() { cfg, snapshotFlag = savedCfg, savedSnapshot }()
+	cfg = config.DefaultConfig()
+	messages := i18n.GetMessages(i18n.English, "test")
+	createTestVault(t)
+
+	// При закрытом хранилище имя в конфигурации не настоящее и не сохраняется
+	saveDerivationSnapshot(cfg.Derivation(), messages)
+	withName := cfg.Derivation()
+	withName.Username = "bob"
+	withName.DefaultLength = 20
+	if _, err := config.SaveDerivationSnapshot(withName); err != nil {
+		t.Fatalf("SaveDerivationSnapshot() ошибка: %v", err)
+	}
+	snapshot, err := config.DerivationSnapshotAt(1)
+	if err != nil || snapshot.Username != "" {
+		t.Fatalf("Снимок при закрытом хранилище = %+v, %v", snapshot, err)
+	}
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+	unlockVault(masterPassword, messages)
+	defer closeVault()
+
+	// Снимок без имени оставляет имя из хранилища
+	snapshotFlag = 1
+	applySnapshotFlag(messages)
+	if got := defaultUsername(); got != "alice" {
+		t.Errorf("defaultUsername() со снимком без имени = %q, ожидается alice", got)
+	}
+
+	// Имя из снимка важнее имени из хранилища
+	snapshotFlag = 2
+	applySnapshotFlag(messages)
+	if got := defaultUsername(); got != "bob" || cfg.DefaultLength != 20 {
+		t.Errorf("defaultUsername() со снимком = %q, длина %d", got, cfg.DefaultLength)
+	}
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+	"github.com/spf13/cobra"
+)
+
+// addGenerationFlags регистрирует флаги параметров генерации, общие для
+// интерактивного режима и команды get
+func addGenerationFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&lengthFlag, "length", "n", 16, "")
+	cmd.Flags().StringVarP(&requireFlag, "require", "", "", "")
+	cmd.Flags().IntVarP(&counterFlag, "counter", "", 1, "")
+	cmd.Flags().BoolVarP(&passphraseFlag, "passphrase", "", false, "")
+	cmd.Flags().IntVarP(&wordsFlag, "words", "", generator.DefaultPassphraseWords, "")
+	cmd.Flags().StringVarP(&separatorFlag, "separator", "", generator.DefaultPassphraseSeparator, "")
+	cmd.Flags().StringVarP(&wordlistFlag, "wordlist", "", generator.DefaultWordlist, "")
+	cmd.Flags().BoolVarP(&capitalizeFlag, "capitalize", "", false, "")
+	cmd.Flags().BoolVarP(&digitFlag, "digit", "", false, "")
+	cmd.Flags().StringVarP(&typeFlag, "type", "", "", "")
+	cmd.Flags().StringVarP(&algorithmFlag, "algorithm", "", "", "")
+	cmd.MarkFlagsMutuallyExclusive("passphrase", "type")
+}
+
+// resolveGeneratorOptions возвращает параметры генератора: значения из
+// конфигурации перекрываются настройками сервиса из реестра (s может быть nil),
+// а те - флагами команды
+func resolveGeneratorOptions(cmd *cobra.Command, s *site.Site) (pgen.Options, error) {
+	length := cfg.DefaultLength
+	if s != nil && s.Length > 0 {
+		length = s.Length
+	}
+	if cmd.Flags().Changed("length") {
+		length = lengthFlag
+	}
+
+	opts, err := configGeneratorOptions(length)
+	if err != nil {
+		return opts, err
+	}
+	if err := applySiteOptions(&opts, s); err != nil {
+		return opts, err
+	}
+
+	if cmd.Flags().Changed("algorithm") {
+		opts.Algorithm = pgen.Algorithm(algorithmFlag)
+	}
+	if cmd.Flags().Changed("type") {
+		if opts.Type, err = pgen.ParsePasswordType(typeFlag); err != nil {
+			return opts, err
+		}
+		opts.Mode = passwordMode(opts.Type)
+	}
+	if cmd.Flags().Changed("require") {
+		if opts.RequiredClasses, err = pgen.ParseRequiredClasses(requireFlag); err != nil {
+			return opts, err
+		}
+	}
+	if passphraseFlag {
+		opts.Mode = pgen.ModePassphrase
+	}
+	if opts.Mode == pgen.ModePassphrase {
+		opts.Passphrase = resolvePassphraseOptions(cmd, s)
+	}
+
+	counter := counterFlag
+	if !cmd.Flags().Changed("counter") && s != nil && s.Counter > 0 {
+		counter = int(s.Counter)
+	}
+	// Отрицательный счётчик не должен превратиться в большое uint32
+	if err := generator.ValidateCounter(counter); err != nil {
+		return opts, err
+	}
+	opts.Counter = uint32(counter)
+
+	return opts, nil
+}
+
+// applySiteOptions применяет к параметрам генератора настройки сервиса
+func applySiteOptions(opts *pgen.Options, s *site.Site) error {
+	if s == nil {
+		return nil
+	}
+	var err error
+	if s.Charset != "" {
+		opts.Charset = s.Charset
+	}
+	if s.Algorithm != "" {
+		opts.Algorithm = pgen.Algorithm(s.Algorithm)
+	}
+	if s.Type != "" {
+		if opts.Type, err = pgen.ParsePasswordType(s.Type); err != nil {
+			return err
+		}
+		opts.Mode = passwordMode(opts.Type)
+	}
+	if s.RequiredClasses != "" {
+		if opts.RequiredClasses, err = pgen.ParseRequiredClasses(s.RequiredClasses); err != nil {
+			return err
+		}
+	}
+	if s.Passphrase {
+		opts.Mode = pgen.ModePassphrase
+	}
+	return nil
+}
+
+// siteUsername возвращает имя пользователя для соли: из флага --user, из
+// реестра или имя по умолчанию из хранилища или конфигурации
+func siteUsername(s *site.Site) string {
+	if username := strings.TrimSpace(userFlag); username != "" {
+		return username
+	}
+	if s != nil && s.Username != "" {
+		return s.Username
+	}
+	return defaultUsername()
+}
+
+// configGeneratorOptions возвращает параметры генератора из конфигурации
+func configGeneratorOptions(length int) (pgen.Options, error) {
+	return derivationGeneratorOptions(cfg.Derivation(), length)
+}
+
+// derivationGeneratorOptions возвращает параметры генератора из настроек,
+// от которых зависят пароли
+func derivationGeneratorOptions(d config.Derivation, length int) (pgen.Options, error) {
+	opts := pgen.DefaultOptions()
+	opts.Length = length
+	opts.Charset = d.CharacterSet
+	opts.Algorithm = pgen.Algorithm(d.Algorithm)
+	opts.Argon = pgen.ArgonParams{
+		Time:    d.ArgonTime,
+		Memory:  d.ArgonMemory,
+		Threads: d.ArgonThreads,
+		KeyLen:  d.ArgonKeyLen,
+	}
+
+	passwordType, err := pgen.ParsePasswordType(d.PasswordType)
+	if err != nil {
+		return opts, err
+	}
+	opts.Type = passwordType
+	opts.Mode = passwordMode(passwordType)
+
+	requiredClasses, err := pgen.ParseRequiredClasses(d.RequiredClasses)
+	if err != nil {
+		return opts, err
+	}
+	opts.RequiredClasses = requiredClasses
+
+	return opts, nil
+}
+
+// passwordMode возвращает режим для типа шаблона: шаблон сам задаёт длину и
+// классы символов, без шаблона пароль строится по набору символов
+func passwordMode(passwordType pgen.PasswordType) pgen.Mode {
+	if passwordType != "" {
+		return pgen.ModeTemplate
+	}
+	return pgen.ModePassword
+}
+
+// generatePassword генерирует пароль сервиса, не оставляя копий мастер-пароля
+func generatePassword(gen *pgen.Generator, masterPassword *security.SecureString, service, username string) (*security.SecureString, error) {
+	master := masterPassword.Bytes()
+	defer security.ZeroMemory(master)
+
+	password, err := gen.Generate(context.Background(), master, service, username)
+	if err != nil {
+		return nil, err
+	}
+	return secureResult(password), nil
+}
+
+// generateWithMasterKey генерирует пароль сервиса из мастер-ключа v3
+func generateWithMasterKey(gen *pgen.Generator, masterKey *pgen.MasterKey, service string) (*security.SecureString, error) {
+	password, err := gen.GenerateWithMasterKey(context.Background(), masterKey, service)
+	if err != nil {
+		return nil, err
+	}
+	return secureResult(password), nil
+}
+
+// secureResult переносит пароль в SecureString и затирает исходный срез
+func secureResult(password []byte) *security.SecureString {
+	secure := security.NewSecureStringFromBytes(password)
+	security.SecureWipe(password)
+	return secure
+}
+
+// exitWithError печатает сообщение об ошибке и завершает программу
+func exitWithError(text string) {
+	exitWithCode(exitFailure, text)
+}
+
+// exitWithCode печатает сообщение об ошибке и завершает программу с кодом code
+func exitWithCode(code int, text string) {
+	fmt.Fprintf(os.Stderr, "%s\n", colors.ErrorMsg(text))
+	os.Exit(code)
+}
+
+// getLengthErrorText возвращает текст ошибки длины на соответствующем языке
+func getLengthErrorText(err error, messages *i18n.Messages) string {
+	switch pgen.ErrorCode(err) {
+	case "length_too_short":
+		return messages.Errors.LengthTooShort
+	case "length_too_long":
+		return messages.Errors.LengthTooLong
+	default:
+		return err.Error()
+	}
+}
+
+// getCounterErrorText возвращает текст ошибки счётчика на соответствующем языке
+func getCounterErrorText(err error, messages *i18n.Messages) string {
+	switch pgen.ErrorCode(err) {
+	case "counter_too_small":
+		return messages.CounterTooSmall
+	case "counter_too_large":
+		return messages.CounterTooLarge
+	default:
+		return err.Error()
+	}
+}
+
+// getGeneratorErrorText возвращает текст ошибки генератора на соответствующем языке
+func getGeneratorErrorText(err error, messages *i18n.Messages) string {
+	switch pgen.ErrorCode(err) {
+	case "length_too_short", "length_too_long":
+		return getLengthErrorText(err, messages)
+	case "counter_too_small", "counter_too_large":
+		return getCounterErrorText(err, messages)
+	case "charset_unknown", "charset_invalid_char", "charset_too_small":
+		return messages.ConfigInvalidCharset + " " + messages.ConfigCharsetValues
+	case "algorithm_unknown":
+		return messages.ConfigInvalidAlgorithm + " " + messages.ConfigAlgorithmValues
+	case "password_type_unknown":
+		return messages.ConfigInvalidPasswordType + " " + messages.ConfigPasswordTypeValues
+	case "required_class_unknown":
+		return messages.ConfigInvalidRequiredClasses + " " + messages.ConfigRequiredClassesValues
+	case "required_classes_too_many", "required_class_unavailable":
+		return getRequiredClassesErrorText(err, messages)
+	case "words_too_few", "words_too_many", "separator_too_long", "separator_invalid", "wordlist_unknown":
+		return getPassphraseErrorText(err, messages)
+	case "master_password_empty":
+		return messages.Errors.EmptyMaster
+	case "service_empty":
+		return messages.Errors.EmptyService
+	case "service_canonical_unknown":
+		return messages.ConfigServiceCanonicalValues
+	case "hash_too_short":
+		return messages.Errors.HashTooShort
+	default:
+		return err.Error()
+	}
+}
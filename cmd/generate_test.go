@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+func TestGetGeneratorErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{"length_too_short", messages.Errors.LengthTooShort},
+		{"counter_too_large", messages.CounterTooLarge},
+		{"charset_unknown", messages.ConfigInvalidCharset + " " + messages.ConfigCharsetValues},
+		{"algorithm_unknown", messages.ConfigInvalidAlgorithm + " " + messages.ConfigAlgorithmValues},
+		{"password_type_unknown", messages.ConfigInvalidPasswordType + " " + messages.ConfigPasswordTypeValues},
+		{"required_class_unknown", messages.ConfigInvalidRequiredClasses + " " + messages.ConfigRequiredClassesValues},
+		{"required_classes_too_many", messages.RequiredClassesTooMany},
+		{"wordlist_unknown", messages.WordlistUnknown},
+		{"hash_too_short", messages.Errors.HashTooShort},
+		{"other_error", "other_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := getGeneratorErrorText(errors.New(tt.code), messages); got != tt.expected {
+				t.Errorf("getGeneratorErrorText(%q) = %q, ожидается %q", tt.code, got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Ошибка библиотеки", func(t *testing.T) {
+		opts := pgen.DefaultOptions()
+		opts.Length = 200
+		_, err := pgen.New(opts)
+		if got := getGeneratorErrorText(err, messages); got != messages.Errors.LengthTooLong {
+			t.Errorf("getGeneratorErrorText() = %q, ожидается %q", got, messages.Errors.LengthTooLong)
+		}
+	})
+}
+
+func TestConfigGeneratorOptions(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+
+	cfg = config.DefaultConfig()
+	cfg.CharacterSet = "no_ambiguous"
+	cfg.RequiredClasses = "digit,upper"
+	opts, err := configGeneratorOptions(24)
+	if err != nil {
+		t.Fatalf("configGeneratorOptions() ошибка: %v", err)
+	}
+	if opts.Length != 24 || opts.Charset != "no_ambiguous" || opts.Mode != pgen.ModePassword {
+		t.Errorf("configGeneratorOptions() = %+v", opts)
+	}
+	if len(opts.RequiredClasses) != 2 || opts.RequiredClasses[0] != pgen.ClassUpper {
+		t.Errorf("RequiredClasses = %v, ожидается [upper digit]", opts.RequiredClasses)
+	}
+	if opts.Argon.Memory != cfg.ArgonMemory || string(opts.Algorithm) != cfg.Algorithm {
+		t.Errorf("Параметры Argon2 или алгоритм не взяты из конфигурации: %+v", opts)
+	}
+
+	cfg.PasswordType = "basic"
+	if opts, _ = configGeneratorOptions(16); opts.Mode != pgen.ModeTemplate || opts.Type != pgen.TypeBasic {
+		t.Errorf("Режим = %q, тип = %q, ожидается шаблон basic", opts.Mode, opts.Type)
+	}
+
+	cfg.PasswordType = "unknown"
+	if _, err := configGeneratorOptions(16); !errors.Is(err, pgen.ErrUnknownPasswordType) {
+		t.Errorf("configGeneratorOptions() ошибка = %v, ожидается ErrUnknownPasswordType", err)
+	}
+}
+
+func TestDefaultUsernamePasswords(t *testing.T) {
+	savedCfg, savedUser := cfg, userFlag
+	defer func() { cfg, userFlag = savedCfg, savedUser }()
+	cfg, userFlag = config.DefaultConfig(), ""
+	cfg.ArgonTime, cfg.ArgonMemory, cfg.ArgonThreads = 1, 64*1024, 1
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+	generate := func(t *testing.T, s *site.Site) string {
+		t.Helper()
+		opts, err := configGeneratorOptions(16)
+		if err != nil {
+			t.Fatalf("configGeneratorOptions() ошибка: %v", err)
+		}
+		gen, err := pgen.New(opts)
+		if err != nil {
+			t.Fatalf("pgen.New() ошибка: %v", err)
+		}
+		password, err := generatePassword(gen, masterPassword, "github.com", siteUsername(s))
+		if err != nil {
+			t.Fatalf("generatePassword() ошибка: %v", err)
+		}
+		defer password.Clear()
+		return password.String()
+	}
+
+	// Значения зафиксированы до появления --user: имя по умолчанию обязано
+	// давать прежние пароли
+	expected := map[string]string{
+		"v1": "!d-^$.vQAp,w4Ue4",
+		"v2": ",u.1aMQl,k=|;.Lm",
+		"v3": "E!:|kEUX(Wm)*Abb",
+	}
+	for algorithm, want := range expected {
+		t.Run(algorithm, func(t *testing.T) {
+			cfg.Algorithm = algorithm
+			userFlag = ""
+			if got := generate(t, nil); got != want {
+				t.Errorf("Пароль с именем по умолчанию = %q, ожидается %q", got, want)
+			}
+
+			// --user с именем по умолчанию перекрывает имя из реестра
+			userFlag = "user"
+			if got := generate(t, &site.Site{Service: "github.com", Username: "work"}); got != want {
+				t.Errorf("Пароль с --user user = %q, ожидается %q", got, want)
+			}
+
+			userFlag = "work"
+			if got := generate(t, nil); got == want {
+				t.Error("Другое имя пользователя должно давать другой пароль")
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/agent"
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/input"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// Коды завершения pgen get. Скрипты опираются на них, поэтому значения не меняются
+// между версиями.
+const (
+	exitFailure    = 1 // прочие ошибки
+	exitUsage      = 2 // неверные аргументы или флаги
+	exitMaster     = 3 // мастер-пароль не получен
+	exitConfig     = 4 // ошибка конфигурации или параметров генерации
+	exitGeneration = 5 // ошибка генерации
+)
+
+// masterFileEnv переменная окружения с путём к файлу мастер-пароля
+const masterFileEnv = "PGEN_MASTER_FILE"
+
+// Флаги источника мастер-пароля, общие для get и agent
+var (
+	masterStdinFlag bool
+	masterFdFlag    int
+	masterFileFlag  string
+)
+
+// Флаги неинтерактивной генерации
+var (
+	getRawFlag bool
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <service>",
+	Short: "",
+	Long:  "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runGetCommand,
+}
+
+func init() {
+	addMasterSourceFlags(getCmd)
+	getCmd.Flags().BoolVarP(&getRawFlag, "raw", "", false, "")
+	addGenerationFlags(getCmd)
+}
+
+// addMasterSourceFlags регистрирует взаимоисключающие флаги источника мастер-пароля
+func addMasterSourceFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&masterStdinFlag, "master-stdin", "", false, "")
+	cmd.Flags().IntVarP(&masterFdFlag, "master-fd", "", -1, "")
+	cmd.Flags().StringVarP(&masterFileFlag, "master-file", "", "", "")
+	cmd.MarkFlagsMutuallyExclusive("master-stdin", "master-fd", "master-file")
+}
+
+// updateGetCommandTexts обновляет тексты команды get
+func updateGetCommandTexts(messages *i18n.Messages) {
+	getCmd.Short = messages.GetShort
+	getCmd.Long = messages.GetLong
+	updateFlagTexts(getCmd, messages)
+	updateMasterSourceFlagTexts(getCmd, messages)
+
+	if flag := getCmd.Flags().Lookup("raw"); flag != nil {
+		flag.Usage = messages.GetRawFlagDesc
+	}
+}
+
+// updateMasterSourceFlagTexts обновляет описания флагов источника мастер-пароля
+func updateMasterSourceFlagTexts(cmd *cobra.Command, messages *i18n.Messages) {
+	flagDescs := map[string]string{
+		"master-stdin": messages.GetMasterStdinFlagDesc,
+		"master-fd":    messages.GetMasterFdFlagDesc,
+		"master-file":  messages.GetMasterFileFlagDesc,
+	}
+	for name, usage := range flagDescs {
+		if flag := cmd.Flags().Lookup(name); flag != nil {
+			flag.Usage = usage
+		}
+	}
+}
+
+func runGetCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	service := args[0]
+	if service == "" {
+		exitWithCode(exitUsage, messages.Errors.EmptyService)
+	}
+	validateUserFlag(cmd, messages)
+
+	// Молча подставленная конфигурация по умолчанию дала бы другой пароль
+	if configLoadErr != nil {
+		exitWithCode(exitConfig, configLoadErr.Error())
+	}
+
+	// Без явного источника пароль выдаёт запущенный агент. Зашифрованное
+	// хранилище агенту недоступно: имя пользователя и настройки сервиса
+	// открываются только мастер-паролем.
+	masterPassword, err := readMasterSource(cmd)
+	switch {
+	case err != nil && err.Error() == "master_source_missing":
+		if vaultEnabled() {
+			exitWithCode(exitMaster, messages.VaultAgentUnsupported)
+		}
+	case err != nil:
+		exitWithCode(exitMaster, getMasterSourceErrorText(err, messages))
+	default:
+		defer masterPassword.Clear()
+		if masterPassword.IsEmpty() {
+			exitWithCode(exitMaster, messages.Errors.EmptyMaster)
+		}
+		verifyMaster(masterPassword, messages)
+		unlockVault(masterPassword, messages)
+		defer closeVault()
+		unlockServiceRecord(masterPassword, messages)
+		defer closeServiceRecord()
+	}
+
+	applySnapshotFlag(messages)
+	service, err = resolveService(os.Stderr, service, messages)
+	if err != nil {
+		code := exitConfig
+		if err.Error() == "service_empty" {
+			code = exitUsage
+		}
+		exitWithCode(code, getGeneratorErrorText(err, messages))
+	}
+	service = confirmService(os.Stderr, service, false, messages)
+	s := lookupSite(service, messages)
+	username := siteUsername(s)
+	opts, err := resolveGeneratorOptions(cmd, s)
+	if err != nil {
+		exitWithCode(exitConfig, getGeneratorErrorText(err, messages))
+	}
+	gen, err := pgen.New(opts)
+	if err != nil {
+		exitWithCode(exitConfig, getGeneratorErrorText(err, messages))
+	}
+
+	var password *security.SecureString
+	if masterPassword == nil {
+		password = requestAgentPassword(service, username, opts, messages)
+	} else {
+		password, err = generatePassword(gen, masterPassword, service, username)
+		if err != nil {
+			masterPassword.Clear()
+			exitWithCode(exitGeneration, getGeneratorErrorText(err, messages))
+		}
+	}
+	defer password.Clear()
+	rememberServices(messages, service)
+
+	if isJSONOutput(messages) {
+		writeReport(generationReport(password, service, username, opts), messages)
+		return
+	}
+	if getRawFlag {
+		os.Stdout.Write(password.Bytes())
+		return
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", password.String())
+	fmt.Fprintf(os.Stderr, "%s\n", colors.SubtleMsg(fmt.Sprintf(messages.GetSummary, service, utf8.RuneCount(password.Bytes()))))
+}
+
+// requestAgentPassword получает пароль сервиса у агента. Недоступный или
+// заблокированный агент означает, что мастер-пароль не получен.
+func requestAgentPassword(service, username string, opts pgen.Options, messages *i18n.Messages) *security.SecureString {
+	response, err := agent.Call(agent.DefaultSocketPath(), agent.Request{
+		Op:       agent.OpGet,
+		Service:  service,
+		Username: username,
+		Params:   agent.NewParams(opts),
+	})
+	if err != nil {
+		switch err.Error() {
+		case "agent_not_running":
+			exitWithCode(exitMaster, messages.GetMasterMissing)
+		case "agent_locked":
+			exitWithCode(exitMaster, messages.AgentLockedError)
+		case "peer_denied", "protocol_version", "bad_request", "bad_response", "unknown_op":
+			exitWithCode(exitFailure, getAgentErrorText(err, messages))
+		default:
+			exitWithCode(exitGeneration, getGeneratorErrorText(err, messages))
+		}
+	}
+	return secureResult(response.Password)
+}
+
+// readMasterSource читает мастер-пароль из источника, заданного флагами
+// или PGEN_MASTER_FILE. Интерактивного ввода нет: без источника это ошибка.
+func readMasterSource(cmd *cobra.Command) (*security.SecureString, error) {
+	switch {
+	case cmd.Flags().Changed("master-fd"):
+		return input.ReadMasterFd(masterFdFlag)
+	case masterFileFlag != "":
+		return input.ReadMasterFile(masterFileFlag)
+	case masterStdinFlag:
+		return input.ReadMasterPassword(os.Stdin)
+	}
+	if path := os.Getenv(masterFileEnv); path != "" {
+		return input.ReadMasterFile(path)
+	}
+	return nil, errors.New("master_source_missing")
+}
+
+// getMasterSourceErrorText возвращает текст ошибки чтения мастер-пароля на соответствующем языке
+func getMasterSourceErrorText(err error, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "master_source_missing":
+		return messages.GetMasterMissing
+	case "master_file_insecure":
+		return messages.GetMasterFileInsecure
+	case "master_too_long":
+		return messages.GetMasterTooLong
+	case "master_fd_invalid":
+		return messages.GetMasterFdInvalid
+	default:
+		return fmt.Sprintf("%s %v", messages.GetMasterReadError, err)
+	}
+}
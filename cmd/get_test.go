@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+func TestGetMasterSourceErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Нет источника", errors.New("master_source_missing"), messages.GetMasterMissing},
+		{"Открытый файл", errors.New("master_file_insecure"), messages.GetMasterFileInsecure},
+		{"Длинный пароль", errors.New("master_too_long"), messages.GetMasterTooLong},
+		{"Дескриптор", errors.New("master_fd_invalid"), messages.GetMasterFdInvalid},
+		{"Ошибка чтения", errors.New("boom"), messages.GetMasterReadError + " boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getMasterSourceErrorText(tt.err, messages); got != tt.expected {
+				t.Errorf("getMasterSourceErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadMasterSource(t *testing.T) {
+	t.Setenv(masterFileEnv, "")
+	if _, err := readMasterSource(getCmd); err == nil || err.Error() != "master_source_missing" {
+		t.Errorf("readMasterSource() без источника ошибка = %v, ожидается master_source_missing", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "master")
+	if err := os.WriteFile(path, []byte("testmaster\n"), 0o600); err != nil {
+		t.Fatalf("Ошибка записи файла: %v", err)
+	}
+	t.Setenv(masterFileEnv, path)
+	masterPassword, err := readMasterSource(getCmd)
+	if err != nil {
+		t.Fatalf("readMasterSource() из %s ошибка: %v", masterFileEnv, err)
+	}
+	defer masterPassword.Clear()
+	if masterPassword.String() != "testmaster" {
+		t.Errorf("readMasterSource() = %q, ожидается testmaster", masterPassword.String())
+	}
+}
+
+func TestResolveGeneratorOptions(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	cfg.DefaultLength = 20
+
+	cmd := &cobra.Command{Use: "test"}
+	addGenerationFlags(cmd)
+	// Регистрация флагов возвращает общим переменным значения по умолчанию
+	defer addGenerationFlags(&cobra.Command{Use: "reset"})
+
+	opts, err := resolveGeneratorOptions(cmd, nil)
+	if err != nil {
+		t.Fatalf("resolveGeneratorOptions() ошибка: %v", err)
+	}
+	if opts.Length != 20 || opts.Counter != 1 {
+		t.Errorf("Значения конфигурации не применены: длина %d, счётчик %d", opts.Length, opts.Counter)
+	}
+
+	cmd.Flags().Set("length", "24")
+	cmd.Flags().Set("counter", "3")
+	cmd.Flags().Set("type", "pin")
+	opts, err = resolveGeneratorOptions(cmd, nil)
+	if err != nil {
+		t.Fatalf("resolveGeneratorOptions() ошибка: %v", err)
+	}
+	if opts.Length != 24 || opts.Counter != 3 || opts.Mode != pgen.ModeTemplate || opts.Type != pgen.TypePIN {
+		t.Errorf("Флаги не применены: длина %d, счётчик %d, режим %q, тип %q", opts.Length, opts.Counter, opts.Mode, opts.Type)
+	}
+
+	cmd.Flags().Set("counter", "-1")
+	if _, err := resolveGeneratorOptions(cmd, nil); pgen.ErrorCode(err) != "counter_too_small" {
+		t.Errorf("resolveGeneratorOptions() ошибка = %v, ожидается counter_too_small", err)
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/report"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/verifier"
+)
+
+// Флаги проверки мастер-пароля
+var (
+	masterBitsFlag   int
+	masterRefuseFlag bool
+)
+
+var masterCmd = &cobra.Command{
+	Use:   "master",
+	Short: "",
+	Long:  "",
+}
+
+var masterSetCheckCmd = &cobra.Command{
+	Use:   "set-check",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runMasterSetCheckCommand,
+}
+
+var masterCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runMasterCheckCommand,
+}
+
+var masterClearCheckCmd = &cobra.Command{
+	Use:   "clear-check",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runMasterClearCheckCommand,
+}
+
+func init() {
+	masterSetCheckCmd.Flags().IntVarP(&masterBitsFlag, "bits", "", verifier.DefaultBits, "")
+	masterSetCheckCmd.Flags().BoolVarP(&masterRefuseFlag, "refuse", "", false, "")
+	for _, cmd := range []*cobra.Command{masterSetCheckCmd, masterCheckCmd} {
+		addMasterSourceFlags(cmd)
+	}
+
+	masterCmd.AddCommand(masterSetCheckCmd)
+	masterCmd.AddCommand(masterCheckCmd)
+	masterCmd.AddCommand(masterClearCheckCmd)
+}
+
+// updateMasterCommandTexts обновляет тексты команд проверки мастер-пароля
+func updateMasterCommandTexts(messages *i18n.Messages) {
+	masterCmd.Short = messages.MasterShort
+	masterCmd.Long = messages.MasterLong
+	masterSetCheckCmd.Short = messages.MasterSetCheckShort
+	masterCheckCmd.Short = messages.MasterCheckShort
+	masterClearCheckCmd.Short = messages.MasterClearCheckShort
+
+	if flag := masterSetCheckCmd.Flags().Lookup("bits"); flag != nil {
+		flag.Usage = fmt.Sprintf(messages.MasterBitsFlagDesc, verifier.MinBits, verifier.MaxBits)
+	}
+	if flag := masterSetCheckCmd.Flags().Lookup("refuse"); flag != nil {
+		flag.Usage = messages.MasterRefuseFlagDesc
+	}
+	for _, cmd := range []*cobra.Command{masterSetCheckCmd, masterCheckCmd} {
+		updateMasterSourceFlagTexts(cmd, messages)
+	}
+}
+
+// verifyMaster сверяет мастер-пароль с проверкой профиля. Несовпадение
+// выводит предупреждение, а с флагом --refuse у проверки завершает программу.
+func verifyMaster(masterPassword *security.SecureString, messages *i18n.Messages) {
+	if cfg.MasterCheck == nil {
+		return
+	}
+	master := masterPassword.Bytes()
+	match, err := cfg.MasterCheck.Verify(master)
+	security.ZeroMemory(master)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorMsg("⚠️"), colors.SubtleMsg(getMasterCheckErrorText(err, messages)))
+	case match:
+	case cfg.MasterCheck.Refuse:
+		exitWithCode(exitMaster, messages.MasterCheckMismatch)
+	default:
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorMsg("⚠️"), colors.ErrorMsg(messages.MasterCheckWarning))
+	}
+}
+
+func runMasterSetCheckCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	if err := verifier.ValidateBits(masterBitsFlag); err != nil {
+		exitWithCode(exitUsage, getMasterCheckErrorText(err, messages))
+	}
+
+	masterPassword := readConfirmedMaster(cmd, messages)
+	defer masterPassword.Clear()
+
+	// Хранилище сверяет мастер-пароль полностью: проверка не должна
+	// запомнить пароль, который его не открывает
+	unlockVault(masterPassword, messages)
+	defer closeVault()
+
+	master := masterPassword.Bytes()
+	check, err := verifier.New(master, verifier.Params{
+		Time:    cfg.ArgonTime,
+		Memory:  cfg.ArgonMemory,
+		Threads: cfg.ArgonThreads,
+	}, masterBitsFlag)
+	security.ZeroMemory(master)
+	if err != nil {
+		exitWithError(getMasterCheckErrorText(err, messages))
+	}
+	check.Refuse = masterRefuseFlag
+
+	cfg.MasterCheck = check
+	if err := cfg.Save(messages); err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.ConfigErrorSaving, err))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.MasterCheck{Match: true, Bits: check.Bits, Refuse: check.Refuse}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.MasterCheckSaved, check.Bits, check.FalseAcceptRate())))
+}
+
+func runMasterCheckCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	if cfg.MasterCheck == nil {
+		exitWithCode(exitConfig, messages.MasterCheckNotSet)
+	}
+
+	masterPassword := readMasterOrPrompt(cmd, messages)
+	master := masterPassword.Bytes()
+	match, err := cfg.MasterCheck.Verify(master)
+	security.ZeroMemory(master)
+	masterPassword.Clear()
+	if err != nil {
+		exitWithCode(exitConfig, getMasterCheckErrorText(err, messages))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.MasterCheck{Match: match, Bits: cfg.MasterCheck.Bits, Refuse: cfg.MasterCheck.Refuse}, messages)
+		if !match {
+			os.Exit(exitMaster)
+		}
+		return
+	}
+	if !match {
+		exitWithCode(exitMaster, messages.MasterCheckMismatch)
+	}
+	fmt.Println(colors.SuccessMsg(messages.MasterCheckMatch))
+}
+
+func runMasterClearCheckCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	if cfg.MasterCheck == nil {
+		exitWithCode(exitConfig, messages.MasterCheckNotSet)
+	}
+
+	cfg.MasterCheck = nil
+	if err := cfg.Save(messages); err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.ConfigErrorSaving, err))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "master_clear_check"}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(messages.MasterCheckCleared))
+}
+
+// getMasterCheckErrorText возвращает текст ошибки проверки мастер-пароля на соответствующем языке
+func getMasterCheckErrorText(err error, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "check_bits_invalid":
+		return fmt.Sprintf(messages.MasterCheckBitsInvalid, verifier.MinBits, verifier.MaxBits)
+	case "check_invalid":
+		return messages.MasterCheckInvalid
+	default:
+		return fmt.Sprintf("%s %v", messages.MasterCheckError, err)
+	}
+}
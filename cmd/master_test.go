@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/verifier"
+)
+
+func TestVerifyMasterWarns(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	check, err := verifier.New([]byte("testmaster"), verifier.Params{Time: 1, Memory: 8 * 1024, Threads: 1}, verifier.DefaultBits)
+	if err != nil {
+		t.Fatalf("verifier.New() ошибка: %v", err)
+	}
+	cfg = config.DefaultConfig()
+	cfg.MasterCheck = check
+
+	// Без --refuse несовпадение только предупреждает и не завершает программу
+	for _, master := range []string{"testmaster", "testmastr"} {
+		masterPassword := security.NewSecureString(master)
+		verifyMaster(masterPassword, messages)
+		masterPassword.Clear()
+	}
+
+	// Без проверки мастер-пароль не сверяется
+	cfg.MasterCheck = nil
+	masterPassword := security.NewSecureString("anything")
+	verifyMaster(masterPassword, messages)
+	masterPassword.Clear()
+}
+
+func TestGetMasterCheckErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Число бит", errors.New("check_bits_invalid"), fmt.Sprintf(messages.MasterCheckBitsInvalid, verifier.MinBits, verifier.MaxBits)},
+		{"Повреждена", errors.New("check_invalid"), messages.MasterCheckInvalid},
+		{"Другая ошибка", errors.New("boom"), messages.MasterCheckError + " boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getMasterCheckErrorText(tt.err, messages); got != tt.expected {
+				t.Errorf("getMasterCheckErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/MaksymLeiber/pgen/internal/analyzer"
+	"github.com/MaksymLeiber/pgen/internal/clipboard"
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/report"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/validator"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// outputFlag формат вывода всех команд: text или json
+var outputFlag string
+
+// isJSONOutput сообщает, выбран ли машиночитаемый вывод. Неизвестный формат
+// завершает программу с кодом неверного использования.
+func isJSONOutput(messages *i18n.Messages) bool {
+	format, err := report.ParseFormat(outputFlag)
+	if err != nil {
+		exitWithCode(exitUsage, messages.OutputUnknown+" "+messages.OutputValues)
+	}
+	return format == report.FormatJSON
+}
+
+// promptWriter возвращает поток для приглашений ввода: при JSON выводе stdout
+// занят документом
+func promptWriter(jsonOutput bool) io.Writer {
+	if jsonOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// confirmAction спрашивает подтверждение в терминале. Без терминала ответить
+// некому: программа завершается с подсказкой required про флаг --force.
+func confirmAction(question, required string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		exitWithCode(exitUsage, required)
+	}
+	fmt.Fprintf(os.Stderr, "%s ", colors.PromptMsg(question))
+	var confirmation string
+	fmt.Scanln(&confirmation)
+	switch strings.ToLower(confirmation) {
+	case "y", "yes", "д", "да":
+		return true
+	default:
+		return false
+	}
+}
+
+// writeReport выводит документ в stdout
+func writeReport(document any, messages *i18n.Messages) {
+	if err := report.Write(os.Stdout, document); err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.OutputWriteError, err))
+	}
+}
+
+// analyzeGenerated анализирует сгенерированный пароль с учётом режима генерации.
+// Для парольной фразы с недоступным словарём возвращает nil.
+func analyzeGenerated(password string, opts pgen.Options, alphabet string, messages *i18n.Messages) *analyzer.PasswordInfo {
+	switch opts.Mode {
+	case pgen.ModePassphrase:
+		words, err := generator.LoadWordlist(opts.Passphrase.Wordlist)
+		if err != nil {
+			return nil
+		}
+		return analyzer.AnalyzePassphrase(password, opts.Passphrase.Words, len(words), opts.Passphrase.Digit, messages)
+	case pgen.ModeTemplate:
+		return analyzer.AnalyzePasswordWithEntropy(password, generator.TemplateEntropy(opts.Type), messages)
+	default:
+		return analyzer.AnalyzePasswordWithAlphabet(password, alphabet, messages)
+	}
+}
+
+// metricsReport собирает документ метрик из конфигурации
+func metricsReport() *report.Metrics {
+	stats := cfg.ProfileStats
+	username := cfg.Username
+	if vaultEnabled() {
+		// Имя пользователя зашифровано и без мастер-пароля не показывается
+		username = ""
+	}
+	metrics := &report.Metrics{
+		Username:            username,
+		Profile:             stats.CurrentProfile,
+		PasswordsGenerated:  stats.PasswordsGenerated,
+		FirstUsed:           stats.FirstUsed,
+		LastUsed:            stats.LastUsed,
+		EntropyBits:         configEntropy(),
+		AverageGenerationMs: stats.AverageGenerationTime,
+		Argon: report.Argon{
+			Time:      cfg.ArgonTime,
+			MemoryKiB: cfg.ArgonMemory,
+			Threads:   cfg.ArgonThreads,
+			KeyLen:    cfg.ArgonKeyLen,
+		},
+		Algorithm:   cfg.Algorithm,
+		Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+		Version:     Version,
+		ColorOutput: cfg.ColorOutput,
+	}
+	if stats.FirstUsed != nil && stats.LastUsed != nil {
+		metrics.ActiveDays = activeDays(*stats.FirstUsed, *stats.LastUsed)
+		if metrics.ActiveDays > 0 {
+			metrics.AveragePerDay = float64(stats.PasswordsGenerated) / float64(metrics.ActiveDays)
+		}
+	}
+	return metrics
+}
+
+// generationReport собирает документ генерации без анализа и буфера обмена
+func generationReport(password *security.SecureString, service, username string, opts pgen.Options) report.Generation {
+	document := report.Generation{
+		Service:   service,
+		Username:  username,
+		Password:  password.String(),
+		Length:    utf8.RuneCount(password.Bytes()),
+		Mode:      string(opts.Mode),
+		Counter:   opts.Counter,
+		Algorithm: string(opts.Algorithm),
+	}
+	if opts.Mode == pgen.ModeTemplate {
+		document.Type = string(opts.Type)
+	}
+	return document
+}
+
+// writeGenerationReport выводит документ генерации интерактивного режима.
+// Буфер обмена очищается уже после вывода документа, чтобы читающий stdout не
+// ждал таймаута.
+func writeGenerationReport(cmd *cobra.Command, password *security.SecureString, service, username string, opts pgen.Options, gen *pgen.Generator, strength *validator.PasswordStrength, messages *i18n.Messages) {
+	document := generationReport(password, service, username, opts)
+	document.MasterStrength = report.NewStrength(strength)
+	if showInfoFlag || cfg.ShowPasswordInfo {
+		if info := analyzeGenerated(password.String(), opts, gen.Alphabet(), messages); info != nil {
+			document.Info = report.NewInfo(info)
+		}
+	}
+
+	var done <-chan bool
+	if copyFlag || cfg.DefaultCopy {
+		timeout := effectiveClearTimeout(cmd)
+		var err error
+		done, err = clipboard.CopyToClipboardWithTimeout(password.String(), time.Duration(timeout)*time.Second)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", colors.ErrorMsg(messages.Errors.ClipboardError+":"), err)
+		} else {
+			document.Copied = true
+			if timeout > 0 {
+				document.ClearAfter = timeout
+			}
+		}
+	}
+
+	writeReport(document, messages)
+	if done != nil {
+		<-done
+	}
+}
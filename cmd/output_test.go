@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+func TestGenerationReport(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	cfg.Username = "testuser"
+
+	password := security.NewSecureString("9459")
+	defer password.Clear()
+
+	opts := pgen.DefaultOptions()
+	opts.Mode = pgen.ModeTemplate
+	opts.Type = pgen.TypePIN
+	opts.Counter = 2
+
+	document := generationReport(password, "github.com", cfg.Username, opts)
+	if document.Password != "9459" || document.Length != 4 || document.Username != "testuser" {
+		t.Errorf("generationReport() = %+v", document)
+	}
+	if document.Mode != "template" || document.Type != "pin" || document.Counter != 2 {
+		t.Errorf("Параметры генерации не перенесены: %+v", document)
+	}
+
+	// Тип из конфигурации не выводится, если фраза заменила шаблон
+	opts.Mode = pgen.ModePassphrase
+	if document := generationReport(password, "github.com", cfg.Username, opts); document.Type != "" {
+		t.Errorf("Type = %q, ожидается пустой для парольной фразы", document.Type)
+	}
+}
+
+func TestAnalyzeGenerated(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	opts := pgen.DefaultOptions()
+	opts.Mode = pgen.ModePassphrase
+	info := analyzeGenerated("equinox-ashy-dollhouse-fountain-tracing-antarctic", opts, "", messages)
+	if info == nil || info.Words != opts.Passphrase.Words || info.WordlistSize != 7776 {
+		t.Errorf("analyzeGenerated() для фразы = %+v", info)
+	}
+
+	opts.Passphrase.Wordlist = "missing"
+	if info := analyzeGenerated("a-b-c", opts, "", messages); info != nil {
+		t.Errorf("analyzeGenerated() с неизвестным словарём = %+v, ожидается nil", info)
+	}
+}
+
+func TestMetricsReport(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+
+	first := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	last := first.Add(72 * time.Hour)
+	cfg.ProfileStats.FirstUsed = &first
+	cfg.ProfileStats.LastUsed = &last
+	cfg.ProfileStats.PasswordsGenerated = 8
+
+	metrics := metricsReport()
+	if metrics.ActiveDays != 4 || metrics.AveragePerDay != 2 {
+		t.Errorf("Активные дни %d, в среднем %.1f, ожидается 4 и 2", metrics.ActiveDays, metrics.AveragePerDay)
+	}
+	if metrics.Argon.MemoryKiB != cfg.ArgonMemory || metrics.EntropyBits != configEntropy() {
+		t.Errorf("metricsReport() = %+v", metrics)
+	}
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/report"
+	"github.com/MaksymLeiber/pgen/internal/vault"
+)
+
+// Флаги профилей
+var (
+	profileFlag     string
+	profileUserFlag string
+	profileCopyFlag bool
+	profileYesFlag  bool
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "",
+	Long:  "",
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runProfileCreateCommand,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runProfileUseCommand,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runProfileListCommand,
+}
+
+var profileRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runProfileRmCommand,
+}
+
+var profileRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "",
+	Args:  cobra.ExactArgs(2),
+	Run:   runProfileRenameCommand,
+}
+
+func init() {
+	profileCreateCmd.Flags().StringVarP(&profileUserFlag, "user", "", "", "")
+	profileCreateCmd.Flags().BoolVarP(&profileCopyFlag, "copy", "", false, "")
+	profileRmCmd.Flags().BoolVarP(&profileYesFlag, "yes", "y", false, "")
+
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileRmCmd)
+	profileCmd.AddCommand(profileRenameCmd)
+}
+
+// updateProfileCommandTexts обновляет тексты команд профилей
+func updateProfileCommandTexts(messages *i18n.Messages) {
+	profileCmd.Short = messages.ProfileShort
+	profileCmd.Long = messages.ProfileLong
+	profileCreateCmd.Short = messages.ProfileCreateShort
+	profileUseCmd.Short = messages.ProfileUseShort
+	profileListCmd.Short = messages.ProfileListShort
+	profileRmCmd.Short = messages.ProfileRmShort
+	profileRenameCmd.Short = messages.ProfileRenameShort
+
+	if flag := rootCmd.PersistentFlags().Lookup("profile"); flag != nil {
+		flag.Usage = messages.ProfileFlagDesc
+	}
+	if flag := profileCreateCmd.Flags().Lookup("user"); flag != nil {
+		flag.Usage = messages.ProfileUserFlagDesc
+	}
+	if flag := profileCreateCmd.Flags().Lookup("copy"); flag != nil {
+		flag.Usage = messages.ProfileCopyFlagDesc
+	}
+	if flag := profileRmCmd.Flags().Lookup("yes"); flag != nil {
+		flag.Usage = messages.ProfileYesFlagDesc
+	}
+}
+
+// detectProfileFromArgs возвращает профиль из флага --profile. Флаг нужен
+// до разбора аргументов: от профиля зависит загружаемая конфигурация.
+func detectProfileFromArgs() string {
+	for i, arg := range os.Args {
+		if arg == "--profile" && i+1 < len(os.Args) {
+			return os.Args[i+1]
+		}
+		if strings.HasPrefix(arg, "--profile=") {
+			return strings.TrimPrefix(arg, "--profile=")
+		}
+	}
+	return ""
+}
+
+// selectProfile выбирает профиль запуска: из флага --profile, а без него
+// активный профиль
+func selectProfile() (string, error) {
+	name := detectProfileFromArgs()
+	if name == "" {
+		active, err := config.ActiveProfile()
+		if err != nil {
+			return active, err
+		}
+		name = active
+	}
+	return name, config.UseProfile(name)
+}
+
+// isProfileCommand сообщает, запущена ли команда профилей. Они работают
+// и с недоступным профилем, чтобы его можно было сменить.
+func isProfileCommand() bool {
+	found, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && (found == profileCmd || found.Parent() == profileCmd)
+}
+
+func runProfileCreateCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	name := args[0]
+
+	created := config.DefaultConfig()
+	if profileCopyFlag {
+		copied := *cfg
+		created = &copied
+	}
+	if cmd.Flags().Changed("user") {
+		username := strings.TrimSpace(profileUserFlag)
+		if username == "" {
+			exitWithCode(exitUsage, messages.ConfigUsernameEmpty)
+		}
+		created.Username = username
+	}
+	if err := config.CreateProfile(name, created, messages); err != nil {
+		exitWithError(getProfileErrorText(err, name, messages))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "profile_create", Key: name}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.ProfileCreated, name, name)))
+}
+
+func runProfileUseCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	name := args[0]
+	if err := config.ValidateProfileName(name); err != nil {
+		exitWithError(getProfileErrorText(err, name, messages))
+	}
+	if err := config.SetActiveProfile(name); err != nil {
+		exitWithError(getProfileErrorText(err, name, messages))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "profile_use", Key: name}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.ProfileSwitched, name)))
+}
+
+func runProfileListCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	names, err := config.Profiles()
+	if err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.ProfileError, err))
+	}
+	active, _ := config.ActiveProfile()
+
+	profiles := make([]report.Profile, 0, len(names))
+	for _, name := range names {
+		profileCfg, err := config.LoadProfile(name, messages)
+		if err != nil {
+			exitWithCode(exitConfig, err.Error())
+		}
+		entry := report.Profile{
+			Name:               name,
+			Active:             name == active,
+			Current:            name == config.CurrentProfile(),
+			Username:           profileCfg.Username,
+			Algorithm:          profileCfg.Algorithm,
+			PasswordsGenerated: profileCfg.ProfileStats.PasswordsGenerated,
+		}
+		if profileVaultEnabled(name) {
+			// Имя пользователя профиля зашифровано
+			entry.Username = ""
+		}
+		profiles = append(profiles, entry)
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(profiles, messages)
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\n", messages.ProfileColumnName, messages.ProfileColumnUser,
+		messages.ProfileColumnAlgorithm, messages.ProfileColumnPasswords)
+	for _, entry := range profiles {
+		marker := " "
+		if entry.Active {
+			marker = "*"
+		}
+		username := entry.Username
+		if username == "" {
+			username = messages.VaultHiddenUser
+		}
+		fmt.Fprintf(writer, "%s %s\t%s\t%s\t%s\n", marker, entry.Name, username, entry.Algorithm,
+			strconv.FormatInt(entry.PasswordsGenerated, 10))
+	}
+	writer.Flush()
+}
+
+// profileVaultEnabled сообщает, есть ли у профиля зашифрованное хранилище
+func profileVaultEnabled(name string) bool {
+	dir, err := config.ProfileDir(name)
+	return err == nil && vault.Exists(filepath.Join(dir, vault.FileName))
+}
+
+func runProfileRmCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	name := args[0]
+	if name == config.DefaultProfile {
+		exitWithError(messages.ProfileDefaultError)
+	}
+	if !config.ProfileExists(name) {
+		exitWithError(fmt.Sprintf(messages.ProfileNotFound, name))
+	}
+
+	if !profileYesFlag && !confirmAction(fmt.Sprintf(messages.ProfileRmConfirm, name), messages.ProfileRmConfirmRequired) {
+		fmt.Fprintln(os.Stderr, colors.InfoMsg(messages.ProfileRmCancelled))
+		return
+	}
+
+	if err := config.RemoveProfile(name); err != nil {
+		exitWithError(getProfileErrorText(err, name, messages))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "profile_rm", Key: name}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.ProfileRemoved, name)))
+}
+
+func runProfileRenameCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	oldName, newName := args[0], args[1]
+
+	if err := config.RenameProfile(oldName, newName, messages); err != nil {
+		name := oldName
+		if err.Error() == "profile_exists" || err.Error() == "profile_name_invalid" {
+			name = newName
+		}
+		exitWithError(getProfileErrorText(err, name, messages))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "profile_rename", Key: oldName, Value: newName}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.ProfileRenamed, oldName, newName)))
+}
+
+// getProfileErrorText возвращает текст ошибки профиля на соответствующем языке
+func getProfileErrorText(err error, name string, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "profile_not_found":
+		return fmt.Sprintf(messages.ProfileNotFound, name)
+	case "profile_exists":
+		return fmt.Sprintf(messages.ProfileExists, name)
+	case "profile_name_invalid":
+		return fmt.Sprintf(messages.ProfileNameInvalid, name)
+	case "profile_default":
+		return messages.ProfileDefaultError
+	case "profile_active":
+		return fmt.Sprintf(messages.ProfileActiveError, name)
+	default:
+		return fmt.Sprintf("%s %v", messages.ProfileError, err)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+)
+
+func TestDetectProfileFromArgs(t *testing.T) {
+	savedArgs := os.Args
+	defer func() { os.Args = savedArgs }()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"Без флага", []string{"pgen", "get", "github.com"}, ""},
+		{"Флаг через пробел", []string{"pgen", "--profile", "work", "get", "github.com"}, "work"},
+		{"Флаг через равно", []string{"pgen", "get", "--profile=work", "github.com"}, "work"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+			if got := detectProfileFromArgs(); got != tt.expected {
+				t.Errorf("detectProfileFromArgs() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatTitleWithProfile(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	messages := &i18n.Messages{ProfileLabel: "profile:"}
+
+	cfg = config.DefaultConfig()
+	cfg.ProfileStats.CurrentProfile = "work"
+	if got := formatTitleWithUser("PGen", "alice", messages); !strings.Contains(got, "[work:alice]") {
+		t.Errorf("formatTitleWithUser() = %q, ожидается профиль и имя пользователя", got)
+	}
+	if got := formatTitleWithUser("PGen", "user", messages); !strings.Contains(got, "[work]") {
+		t.Errorf("formatTitleWithUser() = %q, ожидается имя профиля", got)
+	}
+
+	cfg.ProfileStats.CurrentProfile = config.DefaultProfile
+	if got := formatTitleWithUser("PGen", "alice", messages); !strings.Contains(got, "[alice]") {
+		t.Errorf("formatTitleWithUser() = %q, для default профиль не показывается", got)
+	}
+}
+
+func TestGetProfileErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Не найден", errors.New("profile_not_found"), fmt.Sprintf(messages.ProfileNotFound, "work")},
+		{"Уже есть", errors.New("profile_exists"), fmt.Sprintf(messages.ProfileExists, "work")},
+		{"Недопустимое имя", errors.New("profile_name_invalid"), fmt.Sprintf(messages.ProfileNameInvalid, "work")},
+		{"Профиль default", errors.New("profile_default"), messages.ProfileDefaultError},
+		{"Активный", errors.New("profile_active"), fmt.Sprintf(messages.ProfileActiveError, "work")},
+		{"Другая ошибка", errors.New("boom"), messages.ProfileError + " boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getProfileErrorText(tt.err, "work", messages); got != tt.expected {
+				t.Errorf("getProfileErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
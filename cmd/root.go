@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"os/signal"
@@ -13,7 +14,9 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/MaksymLeiber/pgen/internal/analyzer"
@@ -22,9 +25,14 @@ import (
 	"github.com/MaksymLeiber/pgen/internal/config"
 	"github.com/MaksymLeiber/pgen/internal/generator"
 	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/identicon"
 	"github.com/MaksymLeiber/pgen/internal/input"
 	"github.com/MaksymLeiber/pgen/internal/installer"
+	"github.com/MaksymLeiber/pgen/internal/report"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
 	"github.com/MaksymLeiber/pgen/internal/validator"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
 )
 
 var (
@@ -36,10 +44,25 @@ var (
 	aboutFlag     bool
 	showInfoFlag  bool
 	metricFlag    bool
+	requireFlag   string
+	counterFlag   int
+	typeFlag      string
+	algorithmFlag string
 	installFlag   bool
 	uninstallFlag bool
 	Version       string
 	cfg           *config.Config
+	configLoadErr error // ошибка загрузки конфигурации, cfg при этом по умолчанию
+)
+
+// Флаги парольных фраз
+var (
+	passphraseFlag bool
+	wordsFlag      int
+	separatorFlag  string
+	wordlistFlag   string
+	capitalizeFlag bool
+	digitFlag      bool
 )
 
 var rootCmd = &cobra.Command{
@@ -54,21 +77,53 @@ func Execute() {
 	var err error
 	// Используем стандартный язык по умолчанию для загрузки конфигурации
 	defaultMessages := i18n.GetMessages(i18n.DetectLanguage(""), Version)
+	profileName, profileErr := selectProfile()
 	cfg, err = config.Load(defaultMessages)
 	if err != nil {
+		configLoadErr = err
 		cfg = config.DefaultConfig()
 	}
 
 	// Добавляем команды управления конфигурацией
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(shellCmd)
+	rootCmd.AddCommand(siteCmd)
+	rootCmd.AddCommand(vaultCmd)
+	rootCmd.AddCommand(profileCmd)
+	rootCmd.AddCommand(masterCmd)
+	rootCmd.AddCommand(calibrateCmd)
 
 	lang := detectLanguageFromArgs()
 	messages := i18n.GetMessages(lang, Version)
 	updateCommandTexts(rootCmd, messages)
 	updateConfigCommandTexts(messages)
+	updateBatchCommandTexts(messages)
+	updateGetCommandTexts(messages)
+	updateAgentCommandTexts(messages)
+	updateShellCommandTexts(messages)
+	updateSiteCommandTexts(messages)
+	updateVaultCommandTexts(messages)
+	updateProfileCommandTexts(messages)
+	updateMasterCommandTexts(messages)
+	updateCalibrateCommandTexts(messages)
+	updateDriftCommandTexts(messages)
+	updateServiceCommandTexts(messages)
+
+	// Конфигурация другого профиля дала бы другие пароли: без выбранного
+	// профиля работают только команды профилей
+	if profileErr != nil && !isProfileCommand() {
+		exitWithCode(exitConfig, getProfileErrorText(profileErr, profileName, messages))
+	}
 
-	err = rootCmd.Execute()
+	executed, err := rootCmd.ExecuteC()
 	if err != nil {
+		// Ошибки разбора аргументов get получают свой код завершения для скриптов
+		if executed == getCmd {
+			os.Exit(exitUsage)
+		}
 		os.Exit(1)
 	}
 }
@@ -76,18 +131,30 @@ func Execute() {
 func init() {
 	// Персистентные флаги (доступны во всех подкомандах)
 	rootCmd.PersistentFlags().StringVarP(&langFlag, "lang", "l", "", "")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "", report.FormatText, "")
+	rootCmd.PersistentFlags().StringVarP(&profileFlag, "profile", "", "", "")
 
 	// Локальные флаги (только для корневой команды)
-	rootCmd.Flags().IntVarP(&lengthFlag, "length", "n", 16, "")
 	rootCmd.Flags().BoolVarP(&copyFlag, "copy", "c", false, "")
 	rootCmd.Flags().IntVarP(&clearTimeout, "clear-timeout", "t", 45, "")
 	rootCmd.Flags().BoolVarP(&versionFlag, "version", "v", false, "")
 	rootCmd.Flags().BoolVarP(&aboutFlag, "about", "a", false, "")
 	rootCmd.Flags().BoolVarP(&showInfoFlag, "info", "i", false, "")
 	rootCmd.Flags().BoolVarP(&metricFlag, "metric", "m", false, "")
+	addGenerationFlags(rootCmd)
 	rootCmd.Flags().BoolVarP(&installFlag, "install", "", false, "")
 	rootCmd.Flags().BoolVarP(&uninstallFlag, "uninstall", "", false, "")
 
+	// Формат вывода проверяется до запуска любой команды, чтобы она не успела
+	// ничего изменить
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := report.ParseFormat(outputFlag); err != nil {
+			messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+			return errors.New(messages.OutputUnknown + " " + messages.OutputValues)
+		}
+		return nil
+	}
+
 	rootCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		// Определяем эффективную длину
 		length := lengthFlag
@@ -97,14 +164,12 @@ func init() {
 
 		if err := generator.ValidateLength(length); err != nil {
 			messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
-			switch err.Error() {
-			case "length_too_short":
-				return errors.New(messages.Errors.LengthTooShort)
-			case "length_too_long":
-				return errors.New(messages.Errors.LengthTooLong)
-			default:
-				return err
-			}
+			return errors.New(getLengthErrorText(err, messages))
+		}
+
+		if err := generator.ValidateCounter(counterFlag); err != nil {
+			messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+			return errors.New(getCounterErrorText(err, messages))
 		}
 		return nil
 	}
@@ -123,10 +188,25 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}()
 
-	// Форматируем заголовок с информацией о пользователе
-	titleWithUser := formatTitleWithUser(messages.AppTitle, cfg.Username, messages)
-	fmt.Println(colors.TitleMsg(titleWithUser))
-	fmt.Println(colors.SubtleMsg(messages.AppSubtitle + "\n"))
+	jsonOutput := isJSONOutput(messages)
+	prompt := promptWriter(jsonOutput)
+	validateUserFlag(cmd, messages)
+
+	if jsonOutput && versionFlag {
+		writeReport(report.Version{Version: Version}, messages)
+		return
+	}
+	if jsonOutput && metricFlag {
+		writeReport(metricsReport(), messages)
+		return
+	}
+
+	if !jsonOutput {
+		// Форматируем заголовок с информацией о пользователе
+		titleWithUser := formatTitleWithUser(messages.AppTitle, displayUsername(messages), messages)
+		fmt.Println(colors.TitleMsg(titleWithUser))
+		fmt.Println(colors.SubtleMsg(messages.AppSubtitle + "\n"))
+	}
 
 	if versionFlag {
 		fmt.Println(colors.InfoMsg(messages.Version))
@@ -153,7 +233,7 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	fmt.Print(colors.PromptMsg(messages.EnterMasterPassword + " "))
+	fmt.Fprint(prompt, colors.PromptMsg(messages.EnterMasterPassword+" "))
 	masterPassword, err := input.ReadPasswordWithStarsAndMessages(&input.InputMessages{
 		UserCanceled:  messages.Errors.UserCanceled,
 		InputCanceled: messages.Errors.InputCanceled,
@@ -170,9 +250,18 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 
 	// Проверка силы мастер-пароля
 	strength := validator.ValidatePasswordStrength(masterPassword.String(), messages)
-	displayPasswordStrength(strength, messages)
+	if !jsonOutput {
+		displayPasswordStrength(strength, messages)
+	}
+
+	verifyMaster(masterPassword, messages)
+	unlockVault(masterPassword, messages)
+	defer closeVault()
+	unlockServiceRecord(masterPassword, messages)
+	defer closeServiceRecord()
+	printIdenticon(prompt, masterPassword, messages)
 
-	fmt.Print(colors.PromptMsg(messages.EnterServiceName + " "))
+	fmt.Fprint(prompt, colors.PromptMsg(messages.EnterServiceName+" "))
 	serviceName, err := input.ReadLine()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s %v\n", colors.ErrorMsg(messages.Errors.GenerationError+":"), err)
@@ -184,29 +273,39 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Print(colors.SubtleMsg(messages.GeneratingPassword + "\n"))
+	serviceName, err = resolveService(prompt, serviceName, messages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorMsg(messages.Errors.GenerationError+":"), getGeneratorErrorText(err, messages))
+		os.Exit(1)
+	}
+	serviceName = confirmService(prompt, serviceName, true, messages)
+
+	fmt.Fprint(prompt, colors.SubtleMsg(messages.GeneratingPassword+"\n"))
 
-	// Определяем эффективную длину пароля
-	length := lengthFlag
-	if !cmd.Flags().Changed("length") {
-		length = cfg.DefaultLength // Используем из конфигурации
+	s := lookupSite(serviceName, messages)
+	username := siteUsername(s)
+	if s != nil && !jsonOutput {
+		fmt.Println(colors.SubtleMsg(messages.SiteApplied))
+		printSiteUsername(os.Stdout, username, messages)
 	}
 
-	// Создаем генератор с конфигурацией
-	gen := generator.NewPasswordGeneratorWithConfig(length, generator.ArgonConfig{
-		Time:    cfg.ArgonTime,
-		Memory:  cfg.ArgonMemory,
-		Threads: cfg.ArgonThreads,
-		KeyLen:  cfg.ArgonKeyLen,
-	})
+	opts, err := resolveGeneratorOptions(cmd, s)
+	if err != nil {
+		exitWithError(getGeneratorErrorText(err, messages))
+	}
+
+	gen, err := pgen.New(opts)
+	if err != nil {
+		exitWithError(getGeneratorErrorText(err, messages))
+	}
 
 	// Измеряем время генерации пароля
 	startTime := time.Now()
-	password, err := gen.GeneratePassword(masterPassword, serviceName, cfg.Username, messages)
+	password, err := generatePassword(gen, masterPassword, serviceName, username)
 	generationTime := time.Since(startTime).Milliseconds()
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s %v\n", colors.ErrorMsg(messages.Errors.GenerationError+":"), err)
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorMsg(messages.Errors.GenerationError+":"), getGeneratorErrorText(err, messages))
 		os.Exit(1)
 	}
 
@@ -218,24 +317,26 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 		// Логируем ошибку, но не прерываем работу
 		fmt.Fprintf(os.Stderr, "%s %v\n", colors.SubtleMsg(messages.StatSaveError), err)
 	}
+	rememberServices(messages, serviceName)
 
 	// Очищаем мастер-пароль из памяти после использования
 	defer masterPassword.Clear()
 
-	fmt.Printf("\n%s %s\n", colors.InfoMsg(messages.PasswordGenerated), colors.GeneratedMsg(password.String()))
-	fmt.Printf("%s %s\n", colors.SubtleMsg(messages.LengthLabel), colors.SubtleMsg(fmt.Sprintf("%d %s", password.Len(), messages.CharactersLabel)))
+	if jsonOutput {
+		writeGenerationReport(cmd, password, serviceName, username, opts, gen, strength, messages)
+		return
+	}
+
+	fmt.Println()
+	printGenerated(password, opts, messages)
 
 	// Показ информации о пароле
 	if showInfoFlag || cfg.ShowPasswordInfo {
-		displayPasswordInfo(password.String(), messages)
+		printGeneratedInfo(password, opts, gen.Alphabet(), messages)
 	}
 
 	if copyFlag || cfg.DefaultCopy {
-		// Определяем эффективный таймаут
-		effectiveTimeout := clearTimeout
-		if !cmd.Flags().Changed("clear-timeout") {
-			effectiveTimeout = cfg.DefaultClearTimeout
-		}
+		effectiveTimeout := effectiveClearTimeout(cmd)
 
 		// Используем настраиваемый таймаут для очистки
 		timeoutDuration := time.Duration(effectiveTimeout) * time.Second
@@ -259,6 +360,40 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 	fmt.Println(colors.SubtleMsg("\n" + messages.GetRandomTip()))
 }
 
+// printGenerated печатает сгенерированный пароль с длиной, типом шаблона и счётчиком
+func printGenerated(password *security.SecureString, opts pgen.Options, messages *i18n.Messages) {
+	fmt.Printf("%s %s\n", colors.InfoMsg(messages.PasswordGenerated), colors.GeneratedMsg(password.String()))
+	fmt.Printf("%s %s\n", colors.SubtleMsg(messages.LengthLabel), colors.SubtleMsg(fmt.Sprintf("%d %s", utf8.RuneCount(password.Bytes()), messages.CharactersLabel)))
+	if opts.Mode == pgen.ModeTemplate {
+		fmt.Printf("%s %s\n", colors.SubtleMsg(messages.TypeLabel), colors.SubtleMsg(string(opts.Type)))
+	}
+	if opts.Counter > 1 {
+		fmt.Printf("%s %s\n", colors.SubtleMsg(messages.CounterLabel), colors.SubtleMsg(strconv.FormatUint(uint64(opts.Counter), 10)))
+	}
+}
+
+// printGeneratedInfo печатает анализ сгенерированного пароля или парольной фразы
+func printGeneratedInfo(password *security.SecureString, opts pgen.Options, alphabet string, messages *i18n.Messages) {
+	info := analyzeGenerated(password.String(), opts, alphabet, messages)
+	if info == nil {
+		return
+	}
+	if opts.Mode == pgen.ModePassphrase {
+		printPassphraseInfo(info, messages)
+	} else {
+		printPasswordInfo(info, messages)
+	}
+}
+
+// effectiveClearTimeout возвращает таймаут очистки буфера обмена: флаг имеет
+// приоритет над конфигурацией
+func effectiveClearTimeout(cmd *cobra.Command) int {
+	if cmd.Flags().Changed("clear-timeout") {
+		return clearTimeout
+	}
+	return cfg.DefaultClearTimeout
+}
+
 // runInstallation выполняет установку приложения в системные пути
 func runInstallation(messages *i18n.Messages) {
 	fmt.Println(colors.InfoMsg(messages.InstallCheckingPath))
@@ -413,9 +548,17 @@ func updateCommandTexts(cmd *cobra.Command, messages *i18n.Messages) {
 `)
 	}
 
+	updateFlagTexts(cmd, messages)
+}
+
+// updateFlagTexts обновляет описания флагов команды
+func updateFlagTexts(cmd *cobra.Command, messages *i18n.Messages) {
 	if flag := cmd.Flag("lang"); flag != nil {
 		flag.Usage = messages.Flags.LangDesc
 	}
+	if flag := cmd.Flag("output"); flag != nil {
+		flag.Usage = messages.OutputFlagDesc
+	}
 	if flag := cmd.Flag("length"); flag != nil {
 		flag.Usage = messages.Flags.LengthDesc
 	}
@@ -444,7 +587,36 @@ func updateCommandTexts(cmd *cobra.Command, messages *i18n.Messages) {
 	if flag := cmd.Flag("uninstall"); flag != nil {
 		flag.Usage = messages.Flags.UninstallDesc
 	}
-
+	if flag := cmd.Flag("require"); flag != nil {
+		flag.Usage = messages.RequireFlagDesc
+	}
+	if flag := cmd.Flag("counter"); flag != nil {
+		flag.Usage = messages.CounterFlagDesc
+	}
+	if flag := cmd.Flag("passphrase"); flag != nil {
+		flag.Usage = messages.PassphraseFlagDesc
+	}
+	if flag := cmd.Flag("words"); flag != nil {
+		flag.Usage = messages.WordsFlagDesc
+	}
+	if flag := cmd.Flag("separator"); flag != nil {
+		flag.Usage = messages.SeparatorFlagDesc
+	}
+	if flag := cmd.Flag("wordlist"); flag != nil {
+		flag.Usage = messages.WordlistFlagDesc
+	}
+	if flag := cmd.Flag("capitalize"); flag != nil {
+		flag.Usage = messages.CapitalizeFlagDesc
+	}
+	if flag := cmd.Flag("digit"); flag != nil {
+		flag.Usage = messages.DigitFlagDesc
+	}
+	if flag := cmd.Flag("type"); flag != nil {
+		flag.Usage = messages.TypeFlagDesc
+	}
+	if flag := cmd.Flag("algorithm"); flag != nil {
+		flag.Usage = messages.AlgorithmFlagDesc
+	}
 }
 
 func detectLanguageFromArgs() i18n.Language {
@@ -463,6 +635,21 @@ func detectLanguageFromArgs() i18n.Language {
 	return i18n.DetectLanguage("")
 }
 
+// printIdenticon выводит отпечаток мастер-пароля сразу после ввода.
+// Цвет используется только при включённом color_output и цветном терминале.
+func printIdenticon(w io.Writer, masterPassword *security.SecureString, messages *i18n.Messages) {
+	style, err := identicon.ParseStyle(cfg.Identicon)
+	if err != nil || style == identicon.StyleOff {
+		return
+	}
+	master := masterPassword.Bytes()
+	icon := identicon.New(master, defaultUsername())
+	security.ZeroMemory(master)
+
+	colored := cfg.ColorOutput && !color.NoColor
+	fmt.Fprintf(w, "%s %s\n", colors.SubtleMsg(messages.IdenticonLabel), icon.Render(style, colored))
+}
+
 // displayPasswordStrength отображает информацию о силе мастер-пароля
 func displayPasswordStrength(strength *validator.PasswordStrength, messages *i18n.Messages) {
 	fmt.Printf("%s ", colors.SubtleMsg(messages.MasterPasswordStrength))
@@ -496,10 +683,8 @@ func displayPasswordStrength(strength *validator.PasswordStrength, messages *i18
 	}
 }
 
-// displayPasswordInfo отображает информацию о сгенерированном пароле
-func displayPasswordInfo(password string, messages *i18n.Messages) {
-	info := analyzer.AnalyzePassword(password, messages)
-
+// printPasswordInfo выводит результат анализа пароля
+func printPasswordInfo(info *analyzer.PasswordInfo, messages *i18n.Messages) {
 	fmt.Printf("\n%s\n", colors.InfoMsg(messages.PasswordInfo))
 	fmt.Printf("%s %s (%s)\n", colors.SubtleMsg(messages.Charset), colors.SubtleMsg(info.Charset), messages.CharactersLabel)
 	fmt.Printf("%s %.1f %s\n", colors.SubtleMsg(messages.Entropy), info.Entropy, messages.BitsLabel)
@@ -514,6 +699,70 @@ func displayPasswordInfo(password string, messages *i18n.Messages) {
 	fmt.Printf("%s %s\n", colors.SubtleMsg("📝"), colors.SubtleMsg(messages.CrackAssumptions))
 }
 
+// printPassphraseInfo выводит результат анализа парольной фразы
+func printPassphraseInfo(info *analyzer.PasswordInfo, messages *i18n.Messages) {
+	fmt.Printf("\n%s\n", colors.InfoMsg(messages.PasswordInfo))
+	fmt.Printf("%s %d (%s: %d)\n", colors.SubtleMsg(messages.WordsLabel), info.Words, messages.WordlistLabel, info.WordlistSize)
+	fmt.Printf("%s %.1f %s\n", colors.SubtleMsg(messages.Entropy), info.Entropy, messages.BitsLabel)
+	fmt.Printf("%s %s\n", colors.SubtleMsg(messages.TimeToCrack), colors.SubtleMsg(info.TimeToCrack.HumanTime))
+	fmt.Printf("%s %s\n", colors.SubtleMsg(messages.Strength), colors.SubtleMsg(info.Strength))
+	fmt.Printf("%s %s\n", colors.SubtleMsg("📝"), colors.SubtleMsg(messages.CrackAssumptions))
+}
+
+// resolvePassphraseOptions собирает параметры парольной фразы: настройки сервиса
+// имеют приоритет над конфигурацией, флаги - над настройками сервиса
+func resolvePassphraseOptions(cmd *cobra.Command, s *site.Site) generator.PassphraseOptions {
+	opts := sitePassphraseOptions(s)
+	if cmd.Flags().Changed("words") {
+		opts.Words = wordsFlag
+	}
+	if cmd.Flags().Changed("separator") {
+		opts.Separator = separatorFlag
+	}
+	if cmd.Flags().Changed("wordlist") {
+		opts.Wordlist = wordlistFlag
+	}
+	if cmd.Flags().Changed("capitalize") {
+		opts.Capitalize = capitalizeFlag
+	}
+	if cmd.Flags().Changed("digit") {
+		opts.Digit = digitFlag
+	}
+	return opts
+}
+
+// sitePassphraseOptions возвращает параметры фразы из конфигурации,
+// дополненные настройками сайта
+func sitePassphraseOptions(s *site.Site) generator.PassphraseOptions {
+	return derivationPassphraseOptions(cfg.Derivation(), s)
+}
+
+// derivationPassphraseOptions возвращает параметры фразы из настроек, от
+// которых зависят пароли, дополненные настройками сайта
+func derivationPassphraseOptions(d config.Derivation, s *site.Site) generator.PassphraseOptions {
+	opts := generator.PassphraseOptions{
+		Words:      d.PassphraseWords,
+		Separator:  d.PassphraseSeparator,
+		Wordlist:   d.PassphraseWordlist,
+		Capitalize: d.PassphraseCapitalize,
+		Digit:      d.PassphraseDigit,
+	}
+	if s != nil {
+		if s.Words > 0 {
+			opts.Words = s.Words
+		}
+		if s.Separator != "" {
+			opts.Separator = s.Separator
+		}
+		if s.Wordlist != "" {
+			opts.Wordlist = s.Wordlist
+		}
+		opts.Capitalize = opts.Capitalize || s.Capitalize
+		opts.Digit = opts.Digit || s.Digit
+	}
+	return opts
+}
+
 // getIssueText возвращает текст проблемы на соответствующем языке
 func getIssueText(issue string, messages *i18n.Messages) string {
 	switch issue {
@@ -536,6 +785,36 @@ func getIssueText(issue string, messages *i18n.Messages) string {
 	}
 }
 
+// getRequiredClassesErrorText возвращает текст ошибки обязательных классов на соответствующем языке
+func getRequiredClassesErrorText(err error, messages *i18n.Messages) string {
+	switch pgen.ErrorCode(err) {
+	case "required_class_unavailable":
+		return messages.RequiredClassUnavailable
+	case "required_classes_too_many":
+		return messages.RequiredClassesTooMany
+	default:
+		return err.Error()
+	}
+}
+
+// getPassphraseErrorText возвращает текст ошибки параметров парольной фразы на соответствующем языке
+func getPassphraseErrorText(err error, messages *i18n.Messages) string {
+	switch pgen.ErrorCode(err) {
+	case "words_too_few":
+		return messages.WordsTooFew
+	case "words_too_many":
+		return messages.WordsTooMany
+	case "separator_too_long":
+		return messages.SeparatorTooLong
+	case "separator_invalid":
+		return messages.SeparatorInvalid
+	case "wordlist_unknown":
+		return messages.WordlistUnknown
+	default:
+		return err.Error()
+	}
+}
+
 // getSuggestionText возвращает текст рекомендации на соответствующем языке
 func getSuggestionText(suggestion string, messages *i18n.Messages) string {
 	switch suggestion {
@@ -573,6 +852,10 @@ var configShowCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		lang := detectLanguageFromArgs()
 		messages := i18n.GetMessages(lang, Version)
+		if isJSONOutput(messages) {
+			writeReport(cfg, messages)
+			return
+		}
 		data, _ := json.MarshalIndent(cfg, "", "  ")
 		fmt.Printf("%s %s\n%s\n", colors.InfoMsg("📋"), messages.ConfigCurrentConfig, string(data))
 	},
@@ -586,14 +869,22 @@ var configSetCmd = &cobra.Command{
 		lang := detectLanguageFromArgs()
 		messages := i18n.GetMessages(lang, Version)
 		key, value := args[0], args[1]
+		old := cfg.Derivation()
 		if err := setConfigValue(key, value, messages); err != nil {
 			fmt.Fprintf(os.Stderr, "%s %s %v\n", colors.ErrorMsg("❌"), messages.ConfigInvalidKey, err)
 			os.Exit(1)
 		}
+		if !guardDerivationChange(old, configForceFlag, messages) {
+			return
+		}
 		if err := cfg.Save(messages); err != nil {
 			fmt.Fprintf(os.Stderr, "%s %s %v\n", colors.ErrorMsg("❌"), messages.ConfigErrorSaving, err)
 			os.Exit(1)
 		}
+		if isJSONOutput(messages) {
+			writeReport(report.ConfigChange{Action: "set", Key: key, Value: value}, messages)
+			return
+		}
 		fmt.Printf("%s %s %s = %s\n", colors.SuccessMsg("✓"), messages.ConfigUpdated, key, value)
 	},
 }
@@ -604,11 +895,19 @@ var configResetCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		lang := detectLanguageFromArgs()
 		messages := i18n.GetMessages(lang, Version)
+		old := cfg.Derivation()
 		cfg = config.DefaultConfig()
+		if !guardDerivationChange(old, configForceFlag, messages) {
+			return
+		}
 		if err := cfg.Save(messages); err != nil {
 			fmt.Fprintf(os.Stderr, "%s %s %v\n", colors.ErrorMsg("❌"), messages.ConfigErrorSaving, err)
 			os.Exit(1)
 		}
+		if isJSONOutput(messages) {
+			writeReport(report.ConfigChange{Action: "reset"}, messages)
+			return
+		}
 		fmt.Printf("%s %s\n", colors.SuccessMsg("✓"), messages.ConfigReset_)
 	},
 }
@@ -625,6 +924,10 @@ var configExportCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "%s %s %v\n", colors.ErrorMsg("❌"), messages.ConfigErrorExporting, err)
 			os.Exit(1)
 		}
+		if isJSONOutput(messages) {
+			writeReport(report.ConfigChange{Action: "export", File: filename}, messages)
+			return
+		}
 		fmt.Printf("%s %s %s\n", colors.SuccessMsg("✓"), messages.ConfigExported, filename)
 	},
 }
@@ -642,11 +945,19 @@ var configImportCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "%s %s %v\n", colors.ErrorMsg("❌"), messages.ConfigErrorImporting, err)
 			os.Exit(1)
 		}
+		old := cfg.Derivation()
 		cfg = importedCfg
+		if !guardDerivationChange(old, configForceFlag, messages) {
+			return
+		}
 		if err := cfg.Save(messages); err != nil {
 			fmt.Fprintf(os.Stderr, "%s %s %v\n", colors.ErrorMsg("❌"), messages.ConfigErrorSavingImported, err)
 			os.Exit(1)
 		}
+		if isJSONOutput(messages) {
+			writeReport(report.ConfigChange{Action: "import", File: filename}, messages)
+			return
+		}
 		fmt.Printf("%s %s %s\n", colors.SuccessMsg("✓"), messages.ConfigImported, filename)
 	},
 }
@@ -693,10 +1004,68 @@ func setConfigValue(key, value string, messages *i18n.Messages) error {
 		}
 		cfg.DefaultLanguage = value
 	case "character_set":
-		if value != "alphanumeric" && value != "alphanumeric_symbols" && value != "symbols_only" {
+		if _, err := generator.ResolveCharset(value); err != nil {
 			return fmt.Errorf("%s", messages.ConfigCharsetValues)
 		}
 		cfg.CharacterSet = value
+	case "required_classes":
+		classes, err := generator.ParseRequiredClasses(value)
+		if err != nil {
+			return fmt.Errorf("%s", messages.ConfigRequiredClassesValues)
+		}
+		cfg.RequiredClasses = generator.FormatRequiredClasses(classes)
+	case "algorithm":
+		if _, err := generator.ResolveAlgorithm(value); err != nil {
+			return fmt.Errorf("%s", messages.ConfigAlgorithmValues)
+		}
+		cfg.Algorithm = value
+	case "service_canonical":
+		if _, err := generator.ParseServiceCanonical(value); err != nil || value == "" {
+			return fmt.Errorf("%s", messages.ConfigServiceCanonicalValues)
+		}
+		cfg.ServiceCanonical = value
+	case "service_record":
+		if value != "plain" && value != "blinded" && value != "off" {
+			return fmt.Errorf("%s", messages.ConfigServiceRecordValues)
+		}
+		cfg.ServiceRecord = value
+	case "password_type":
+		passwordType, err := generator.ParsePasswordType(value)
+		if err != nil {
+			return fmt.Errorf("%s", messages.ConfigPasswordTypeValues)
+		}
+		cfg.PasswordType = string(passwordType)
+	case "passphrase_words":
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s %v", messages.ConfigInvalidPassphraseWords, err)
+		}
+		if err := generator.ValidatePassphraseWords(val); err != nil {
+			return fmt.Errorf("%s", getPassphraseErrorText(err, messages))
+		}
+		cfg.PassphraseWords = val
+	case "passphrase_separator":
+		if err := generator.ValidateSeparator(value); err != nil {
+			return fmt.Errorf("%s", getPassphraseErrorText(err, messages))
+		}
+		cfg.PassphraseSeparator = value
+	case "passphrase_wordlist":
+		if _, err := generator.LoadWordlist(value); err != nil {
+			return fmt.Errorf("%s", messages.WordlistUnknown)
+		}
+		cfg.PassphraseWordlist = value
+	case "passphrase_capitalize":
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s %v", messages.ConfigInvalidPassphraseCapitalize, err)
+		}
+		cfg.PassphraseCapitalize = val
+	case "passphrase_digit":
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s %v", messages.ConfigInvalidPassphraseDigit, err)
+		}
+		cfg.PassphraseDigit = val
 	case "default_copy":
 		val, err := strconv.ParseBool(value)
 		if err != nil {
@@ -724,7 +1093,15 @@ func setConfigValue(key, value string, messages *i18n.Messages) error {
 			return fmt.Errorf("%s %v", messages.ConfigInvalidColorOutput, err)
 		}
 		cfg.ColorOutput = val
+	case "identicon":
+		if _, err := identicon.ParseStyle(value); err != nil {
+			return fmt.Errorf("%s", messages.ConfigIdenticonValues)
+		}
+		cfg.Identicon = value
 	case "username":
+		if vaultEnabled() {
+			return fmt.Errorf("%s", messages.VaultUsernameHint)
+		}
 		// Простая валидация - не пустая строка и не только пробелы
 		trimmedValue := strings.TrimSpace(value)
 		if trimmedValue == "" {
@@ -753,6 +1130,14 @@ func formatTitleWithUser(appTitle, username string, messages *i18n.Messages) str
 	if username == "" || username == "user" {
 		userDisplay = "default"
 	}
+	// Именованный профиль показывается перед именем пользователя
+	if profile := cfg.ProfileStats.CurrentProfile; profile != "" && profile != config.DefaultProfile {
+		if userDisplay == "default" {
+			userDisplay = profile
+		} else {
+			userDisplay = profile + ":" + userDisplay
+		}
+	}
 
 	// Создаем красивый формат с локализованным префиксом и счетчиком
 	// Используем фиксированную ширину для заголовка
@@ -800,7 +1185,7 @@ func displayDetailedMetrics(messages *i18n.Messages) {
 	fmt.Println()
 
 	// 1. Статистика профиля
-	fmt.Printf(colors.InfoMsg(messages.ProfileStatistics), cfg.Username)
+	fmt.Printf(colors.InfoMsg(messages.ProfileStatistics), displayUsername(messages))
 	fmt.Println()
 	fmt.Printf(colors.SubtleMsg(messages.PasswordsGenerated), cfg.ProfileStats.PasswordsGenerated)
 	fmt.Println()
@@ -817,7 +1202,7 @@ func displayDetailedMetrics(messages *i18n.Messages) {
 
 	// Вычисляем активные дни
 	if cfg.ProfileStats.FirstUsed != nil && cfg.ProfileStats.LastUsed != nil {
-		activeDays := int(cfg.ProfileStats.LastUsed.Sub(*cfg.ProfileStats.FirstUsed).Hours()/24) + 1
+		activeDays := activeDays(*cfg.ProfileStats.FirstUsed, *cfg.ProfileStats.LastUsed)
 		fmt.Printf(colors.SubtleMsg(messages.ActiveDays), activeDays)
 		fmt.Println()
 
@@ -834,9 +1219,7 @@ func displayDetailedMetrics(messages *i18n.Messages) {
 	fmt.Println(colors.InfoMsg(messages.SecurityMetrics))
 
 	// Рассчитываем реальную энтропию на основе параметров PGen
-	alphabetSize := calculateAlphabetSize(cfg.CharacterSet)
-	passwordLength := cfg.DefaultLength
-	realEntropy := float64(passwordLength) * math.Log2(float64(alphabetSize))
+	realEntropy := configEntropy()
 
 	fmt.Printf(colors.SubtleMsg(messages.AverageEntropy), realEntropy)
 	fmt.Println()
@@ -886,22 +1269,31 @@ func displayDetailedMetrics(messages *i18n.Messages) {
 	fmt.Println()
 	fmt.Printf(colors.SubtleMsg(messages.VersionInfo), Version)
 	fmt.Println()
+	fmt.Printf(colors.SubtleMsg(messages.AlgorithmInfo), cfg.Algorithm)
+	fmt.Println()
 	fmt.Printf(colors.SubtleMsg(messages.ProfileInfo), cfg.ProfileStats.CurrentProfile)
 	fmt.Println()
 	fmt.Printf(colors.SubtleMsg(messages.ColorOutputInfo), cfg.ColorOutput)
 	fmt.Println()
 }
 
+// activeDays возвращает количество дней использования профиля, включая первый
+func activeDays(firstUsed, lastUsed time.Time) int {
+	return int(lastUsed.Sub(firstUsed).Hours()/24) + 1
+}
+
+// configEntropy возвращает энтропию пароля с длиной и набором символов из конфигурации
+func configEntropy() float64 {
+	alphabetSize := calculateAlphabetSize(cfg.CharacterSet)
+	return float64(cfg.DefaultLength) * math.Log2(float64(alphabetSize))
+}
+
 // calculateAlphabetSize возвращает размер алфавита на основе настроек набора символов
 func calculateAlphabetSize(characterSet string) int {
-	switch characterSet {
-	case "alphanumeric":
-		return 62 // A-Z(26) + a-z(26) + 0-9(10) = 62
-	case "alphanumeric_symbols":
-		return 94 // A-Z(26) + a-z(26) + 0-9(10) + спецсимволы(32) = 94
-	case "symbols_only":
-		return 32 // Только спецсимволы: !@#$%^&*()_+-=[]{}|;:,.<>?
-	default:
-		return 94 // По умолчанию alphanumeric_symbols
+	charset, err := generator.ResolveCharset(characterSet)
+	if err != nil {
+		// По умолчанию alphanumeric_symbols
+		return generator.DefaultCharset().Size()
 	}
+	return charset.Size()
 }
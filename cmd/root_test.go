@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"math"
 	"os"
 	"runtime"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/MaksymLeiber/pgen/internal/config"
 	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
 	"github.com/MaksymLeiber/pgen/internal/validator"
 )
 
@@ -302,7 +305,7 @@ func TestSetConfigValue(t *testing.T) {
 		ConfigInvalidDefaultLength: "Неверное значение default_length",
 		ConfigLengthRange:          "Длина должна быть от 4 до 128",
 		ConfigLanguageValues:       "Язык должен быть ru, en или auto",
-		ConfigCharsetValues:        "Набор символов должен быть alphanumeric, alphanumeric_symbols или symbols_only",
+		ConfigCharsetValues:        "Недопустимый набор символов",
 		ConfigInvalidDefaultCopy:   "Неверное значение default_copy",
 		ConfigInvalidClearTimeout:  "Неверное значение clear_timeout",
 		ConfigTimeoutRange:         "Таймаут должен быть >= 0",
@@ -311,6 +314,20 @@ func TestSetConfigValue(t *testing.T) {
 		ConfigInvalidUsername:      "Неверное значение username",
 		ConfigUsernameEmpty:        "Имя пользователя не может быть пустым",
 		ConfigUnknownKey:           "Неизвестный ключ",
+
+		ConfigRequiredClassesValues: "Недопустимые классы символов",
+
+		ConfigInvalidPassphraseWords:      "Неверное значение passphrase_words",
+		ConfigInvalidPassphraseCapitalize: "Неверное значение passphrase_capitalize",
+		ConfigInvalidPassphraseDigit:      "Неверное значение passphrase_digit",
+		WordsTooFew:                       "Слишком мало слов",
+		WordsTooMany:                      "Слишком много слов",
+		SeparatorTooLong:                  "Слишком длинный разделитель",
+		SeparatorInvalid:                  "Недопустимый разделитель",
+		WordlistUnknown:                   "Неизвестный словарь",
+
+		ConfigPasswordTypeValues: "Недопустимый шаблон пароля",
+		ConfigAlgorithmValues:    "Недопустимая версия алгоритма",
 	}
 
 	// Инициализируем глобальную переменную cfg для тестов
@@ -388,6 +405,24 @@ func TestSetConfigValue(t *testing.T) {
 			value:     "invalid_set",
 			wantError: true,
 		},
+		{
+			name:      "Пользовательский character_set",
+			key:       "character_set",
+			value:     "custom:abcdef0123",
+			wantError: false,
+		},
+		{
+			name:      "character_set с исключением символов",
+			key:       "character_set",
+			value:     "exclude:0O1lI",
+			wantError: false,
+		},
+		{
+			name:      "Слишком маленький пользовательский character_set",
+			key:       "character_set",
+			value:     "custom:aaaa",
+			wantError: true,
+		},
 		{
 			name:      "Валидный default_copy",
 			key:       "default_copy",
@@ -436,6 +471,126 @@ func TestSetConfigValue(t *testing.T) {
 			value:     "  maksym  ",
 			wantError: false,
 		},
+		{
+			name:      "Валидный required_classes",
+			key:       "required_classes",
+			value:     "symbol,digit,upper",
+			wantError: false,
+		},
+		{
+			name:      "Сброс required_classes",
+			key:       "required_classes",
+			value:     "none",
+			wantError: false,
+		},
+		{
+			name:      "Невалидный required_classes",
+			key:       "required_classes",
+			value:     "upper,emoji",
+			wantError: true,
+		},
+		{
+			name:      "Валидный passphrase_words",
+			key:       "passphrase_words",
+			value:     "5",
+			wantError: false,
+		},
+		{
+			name:      "Слишком мало слов",
+			key:       "passphrase_words",
+			value:     "2",
+			wantError: true,
+		},
+		{
+			name:      "Невалидный passphrase_words",
+			key:       "passphrase_words",
+			value:     "many",
+			wantError: true,
+		},
+		{
+			name:      "Валидный passphrase_separator",
+			key:       "passphrase_separator",
+			value:     ".",
+			wantError: false,
+		},
+		{
+			name:      "Буквы в passphrase_separator",
+			key:       "passphrase_separator",
+			value:     "and",
+			wantError: true,
+		},
+		{
+			name:      "Валидный passphrase_wordlist",
+			key:       "passphrase_wordlist",
+			value:     "ru",
+			wantError: false,
+		},
+		{
+			name:      "Неизвестный passphrase_wordlist",
+			key:       "passphrase_wordlist",
+			value:     "de",
+			wantError: true,
+		},
+		{
+			name:      "Валидный passphrase_capitalize",
+			key:       "passphrase_capitalize",
+			value:     "true",
+			wantError: false,
+		},
+		{
+			name:      "Невалидный passphrase_digit",
+			key:       "passphrase_digit",
+			value:     "maybe",
+			wantError: true,
+		},
+		{
+			name:      "Алгоритм v1",
+			key:       "algorithm",
+			value:     "v1",
+			wantError: false,
+		},
+		{
+			name:      "Двухэтапный алгоритм v3",
+			key:       "algorithm",
+			value:     "v3",
+			wantError: false,
+		},
+		{
+			name:      "Неизвестный алгоритм",
+			key:       "algorithm",
+			value:     "v9",
+			wantError: true,
+		},
+		{
+			name:      "Скрытый список сервисов",
+			key:       "service_record",
+			value:     "blinded",
+			wantError: false,
+		},
+		{
+			name:      "Неизвестный режим списка сервисов",
+			key:       "service_record",
+			value:     "hashed",
+			wantError: true,
+		},
+		{
+			name:      "Валидный password_type",
+			key:       "password_type",
+			value:     "pin",
+			wantError: false,
+		},
+		{
+			name:      "Сброс password_type",
+			key:       "password_type",
+			value:     "none",
+			wantError: false,
+		},
+		{
+			name:      "Невалидный password_type",
+			key:       "password_type",
+			value:     "huge",
+			wantError: true,
+		},
 		{
 			name:      "Неизвестный ключ",
 			key:       "unknown_key",
@@ -457,6 +612,70 @@ func TestSetConfigValue(t *testing.T) {
 	}
 }
 
+func TestSetConfigValueRequiredClassesCanonical(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+	cfg = config.DefaultConfig()
+
+	if err := setConfigValue("required_classes", "symbol, digit,upper", messages); err != nil {
+		t.Fatalf("setConfigValue() ошибка: %v", err)
+	}
+	if cfg.RequiredClasses != "upper,digit,symbol" {
+		t.Errorf("RequiredClasses = %q, ожидается %q", cfg.RequiredClasses, "upper,digit,symbol")
+	}
+
+	if err := setConfigValue("required_classes", "none", messages); err != nil {
+		t.Fatalf("setConfigValue() ошибка: %v", err)
+	}
+	if cfg.RequiredClasses != "" {
+		t.Errorf("RequiredClasses = %q, ожидается пустая строка", cfg.RequiredClasses)
+	}
+}
+
+func TestGetRequiredClassesErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		err      string
+		expected string
+	}{
+		{"required_class_unavailable", messages.RequiredClassUnavailable},
+		{"required_classes_too_many", messages.RequiredClassesTooMany},
+		{"other_error", "other_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			if got := getRequiredClassesErrorText(errors.New(tt.err), messages); got != tt.expected {
+				t.Errorf("getRequiredClassesErrorText(%q) = %q, ожидается %q", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetPassphraseErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		err      string
+		expected string
+	}{
+		{"words_too_few", messages.WordsTooFew},
+		{"words_too_many", messages.WordsTooMany},
+		{"separator_too_long", messages.SeparatorTooLong},
+		{"separator_invalid", messages.SeparatorInvalid},
+		{"wordlist_unknown", messages.WordlistUnknown},
+		{"other_error", "other_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			if got := getPassphraseErrorText(errors.New(tt.err), messages); got != tt.expected {
+				t.Errorf("getPassphraseErrorText(%q) = %q, ожидается %q", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestNeedsElevation(t *testing.T) {
 	// Тест функции needsElevation
 	defer func() {
@@ -650,22 +869,37 @@ func TestCalculateAlphabetSize(t *testing.T) {
 		{
 			name:         "Alphanumeric with symbols",
 			characterSet: "alphanumeric_symbols",
-			expected:     94, // A-Z(26) + a-z(26) + 0-9(10) + спецсимволы(32)
+			expected:     88, // A-Z(26) + a-z(26) + 0-9(10) + спецсимволы(26)
 		},
 		{
 			name:         "Symbols only",
 			characterSet: "symbols_only",
-			expected:     32, // Только спецсимволы
+			expected:     26, // Только спецсимволы
+		},
+		{
+			name:         "Without ambiguous characters",
+			characterSet: "no_ambiguous",
+			expected:     83, // 88 без 0O1lI
+		},
+		{
+			name:         "Custom alphabet",
+			characterSet: "custom:abcdef0123",
+			expected:     10,
+		},
+		{
+			name:         "Excluded characters",
+			characterSet: "exclude:!@#",
+			expected:     85,
 		},
 		{
 			name:         "Unknown character set",
 			characterSet: "unknown",
-			expected:     94, // По умолчанию alphanumeric_symbols
+			expected:     88, // По умолчанию alphanumeric_symbols
 		},
 		{
 			name:         "Empty character set",
 			characterSet: "",
-			expected:     94, // По умолчанию alphanumeric_symbols
+			expected:     88, // По умолчанию alphanumeric_symbols
 		},
 	}
 
@@ -699,22 +933,22 @@ func TestRealEntropyCalculation(t *testing.T) {
 			name:           "16-символьный alphanumeric_symbols",
 			characterSet:   "alphanumeric_symbols",
 			passwordLength: 16,
-			expectedMin:    104.8, // 16 * log2(94) = 104.87
-			expectedMax:    104.9,
+			expectedMin:    103.3, // 16 * log2(88) = 103.35
+			expectedMax:    103.4,
 		},
 		{
 			name:           "24-символьный alphanumeric_symbols",
 			characterSet:   "alphanumeric_symbols",
 			passwordLength: 24,
-			expectedMin:    157.3, // 24 * log2(94) = 157.31
-			expectedMax:    157.4,
+			expectedMin:    155.0, // 24 * log2(88) = 155.02
+			expectedMax:    155.1,
 		},
 		{
 			name:           "12-символьный symbols_only",
 			characterSet:   "symbols_only",
 			passwordLength: 12,
-			expectedMin:    60.0, // 12 * log2(32) = 60.0
-			expectedMax:    60.1,
+			expectedMin:    56.4, // 12 * log2(26) = 56.41
+			expectedMax:    56.5,
 		},
 	}
 
@@ -874,6 +1108,33 @@ func TestStripANSI(t *testing.T) {
 	}
 }
 
+func TestPrintIdenticon(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	messages := &i18n.Messages{IdenticonLabel: "Отпечаток:"}
+	masterPassword := security.NewSecureString("master123")
+	defer masterPassword.Clear()
+
+	cfg = config.DefaultConfig()
+	cfg.Identicon = "ascii"
+	cfg.ColorOutput = false
+	var out bytes.Buffer
+	printIdenticon(&out, masterPassword, messages)
+	if got := stripANSI(out.String()); got != "Отпечаток: [*>,\n" {
+		t.Errorf("printIdenticon() = %q, ожидается ASCII отпечаток", got)
+	}
+	if strings.Contains(out.String(), "\x1b[36") {
+		t.Error("При color_output=false отпечаток не должен окрашиваться")
+	}
+
+	cfg.Identicon = "off"
+	out.Reset()
+	printIdenticon(&out, masterPassword, messages)
+	if out.Len() != 0 {
+		t.Errorf("printIdenticon() с identicon=off вывел %q", out.String())
+	}
+}
+
 func BenchmarkGetIssueText(b *testing.B) {
 	messages := &i18n.Messages{
 		Errors: struct {
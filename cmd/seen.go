@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/input"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/seen"
+	"github.com/MaksymLeiber/pgen/internal/site"
+)
+
+// maxServiceSuggestions число предлагаемых похожих имён сервиса
+const maxServiceSuggestions = 3
+
+// Ключ скрытого списка сервисов, полученный из мастер-пароля. Живёт до
+// closeServiceRecord, в файл не записывается.
+var (
+	serviceRecordBlinding *seen.Blinding
+	serviceRecordKey      []byte
+)
+
+// serviceRecordPath возвращает путь к списку использованных сервисов рядом с config.json
+func serviceRecordPath() (string, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), seen.FileName), nil
+}
+
+// loadServiceRecord читает список использованных сервисов. Возвращает nil,
+// если список не ведётся: service_record=off или включено зашифрованное
+// хранилище, рядом с которым открытый список выдал бы имена сервисов.
+// Скрытый список открывается ключом unlockServiceRecord; без ключа (пароль
+// от агента) он возвращается закрытым. При service_record=blinded открытые
+// имена сразу скрываются.
+func loadServiceRecord() (*seen.Record, string, error) {
+	if cfg.ServiceRecord == "off" || vaultEnabled() {
+		return nil, "", nil
+	}
+	path, err := serviceRecordPath()
+	if err != nil {
+		return nil, "", err
+	}
+	record, err := seen.Load(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if serviceRecordKey == nil {
+		return record, path, nil
+	}
+
+	switch {
+	case record.Blinded():
+		if bytes.Equal(record.Blinding.Salt, serviceRecordBlinding.Salt) {
+			if err := record.Unlock(serviceRecordKey); err != nil {
+				return nil, "", err
+			}
+		}
+	case cfg.ServiceRecord == "blinded":
+		plain := len(record.Names) > 0
+		if err := record.Blind(serviceRecordBlinding, serviceRecordKey); err != nil {
+			return nil, "", err
+		}
+		if plain {
+			if err := record.Save(path); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	return record, path, nil
+}
+
+// unlockServiceRecord получает из мастер-пароля ключ скрытого списка
+// сервисов: для уже скрытого списка или для service_record=blinded. Это ещё
+// один проход Argon2id, поэтому ключ получается один раз за запуск. Ошибка
+// не прерывает работу: список остаётся закрытым.
+func unlockServiceRecord(masterPassword *security.SecureString, messages *i18n.Messages) {
+	if serviceRecordKey != nil || cfg.ServiceRecord == "off" || vaultEnabled() {
+		return
+	}
+	path, err := serviceRecordPath()
+	if err != nil {
+		return
+	}
+	// Ошибку чтения списка покажет rememberServices после генерации
+	record, err := seen.Load(path)
+	if err != nil {
+		return
+	}
+
+	blinding := record.Blinding
+	if blinding == nil {
+		if cfg.ServiceRecord != "blinded" {
+			return
+		}
+		if blinding, err = seen.NewBlinding(cfg.ArgonTime, cfg.ArgonMemory, cfg.ArgonThreads); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.SubtleMsg(messages.ServiceRecordError), err)
+			return
+		}
+	}
+
+	master := masterPassword.Bytes()
+	key, err := blinding.DeriveKey(master)
+	security.ZeroMemory(master)
+	if err == nil && record.Blinded() {
+		err = record.Unlock(key)
+	}
+	if err != nil {
+		security.ZeroMemory(key)
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.SubtleMsg(messages.ServiceRecordError), getServiceRecordErrorText(err, messages))
+		return
+	}
+	serviceRecordBlinding, serviceRecordKey = blinding, key
+}
+
+// closeServiceRecord стирает ключ скрытого списка сервисов
+func closeServiceRecord() {
+	security.ZeroMemory(serviceRecordKey)
+	serviceRecordBlinding, serviceRecordKey = nil, nil
+}
+
+// serviceSuggestions возвращает известные имена, похожие на имя сервиса.
+// Сервис из списка использованных или из реестра уже известен: для него
+// результат пустой. Похожие имена ищутся среди имён списка и сервисов реестра.
+func serviceSuggestions(service string, record *seen.Record, registry *site.Registry) []string {
+	if cfg.ServiceRecord == "off" {
+		return nil
+	}
+	if record != nil && record.Contains(service) || findSite(registry, service) != nil {
+		return nil
+	}
+
+	var known []string
+	if record != nil {
+		known = record.Known()
+	}
+	for _, s := range registry.Sites {
+		if name, err := canonicalService(s.Service); err == nil {
+			known = append(known, name)
+		}
+	}
+	return seen.Suggest(service, known, maxServiceSuggestions)
+}
+
+// confirmService проверяет новое имя сервиса на опечатку: иначе опечатка
+// молча дала бы другой пароль. Известный сервис, сервис с --new и сервис без
+// похожих имён возвращаются как есть. Если вопросы разрешены и stdin -
+// терминал, пользователь выбирает похожее имя или подтверждает новый сервис;
+// иначе ответить некому, и программа завершается с подсказкой про --new.
+func confirmService(w io.Writer, service string, ask bool, messages *i18n.Messages) string {
+	if newServiceFlag || cfg.ServiceRecord == "off" {
+		return service
+	}
+	// Ошибку чтения списка покажет rememberServices после генерации
+	record, _, _ := loadServiceRecord()
+	if record != nil && record.Contains(service) {
+		return service
+	}
+	registry, _ := loadSites(messages)
+	suggestions := serviceSuggestions(service, record, registry)
+	if len(suggestions) == 0 {
+		return service
+	}
+
+	if !ask || !term.IsTerminal(int(os.Stdin.Fd())) {
+		exitWithCode(exitUsage, fmt.Sprintf(messages.ServiceTypo, service, strings.Join(suggestions, ", "))+"\n"+messages.ServiceTypoNewHint)
+	}
+
+	fmt.Fprintln(w, colors.InfoMsg(fmt.Sprintf(messages.ServiceTypoList, service)))
+	for i, suggestion := range suggestions {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, suggestion)
+	}
+	fmt.Fprint(w, colors.PromptMsg(messages.ServiceTypoChoose+" "))
+	answer, err := input.ReadLine()
+	if err != nil {
+		exitWithError(messages.Errors.UserCanceled)
+	}
+	return chooseService(service, suggestions, answer, messages)
+}
+
+// chooseService возвращает имя сервиса по ответу на вопрос об опечатке:
+// номер похожего имени или "n" для нового сервиса. Пустой или непонятный
+// ответ отменяет генерацию.
+func chooseService(service string, suggestions []string, answer string, messages *i18n.Messages) string {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "n", "н":
+		return service
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(answer)); err == nil && n >= 1 && n <= len(suggestions) {
+		return suggestions[n-1]
+	}
+	exitWithError(messages.Errors.UserCanceled)
+	return ""
+}
+
+// rememberServices добавляет имена сервисов в список использованных. Ошибка
+// не прерывает работу: пароль уже получен.
+func rememberServices(messages *i18n.Messages, services ...string) {
+	record, path, err := loadServiceRecord()
+	if err == nil && record != nil {
+		added := false
+		for _, service := range services {
+			if record.Add(service) {
+				added = true
+			}
+		}
+		if !added {
+			return
+		}
+		err = record.Save(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.SubtleMsg(messages.ServiceRecordError), getServiceRecordErrorText(err, messages))
+	}
+}
+
+// getServiceRecordErrorText возвращает текст ошибки списка сервисов на соответствующем языке
+func getServiceRecordErrorText(err error, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "record_invalid":
+		return messages.ServiceRecordInvalid
+	case "record_version":
+		return messages.ServiceRecordVersion
+	case "record_master_mismatch":
+		return messages.ServiceRecordMasterMismatch
+	default:
+		return err.Error()
+	}
+}
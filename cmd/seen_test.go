@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/seen"
+	"github.com/MaksymLeiber/pgen/internal/site"
+)
+
+func TestServiceSuggestions(t *testing.T) {
+	savedCfg, savedRaw := cfg, rawServiceFlag
+	defer func() { cfg, rawServiceFlag = savedCfg, savedRaw }()
+	cfg, rawServiceFlag = config.DefaultConfig(), false
+
+	record := seen.New()
+	record.Add("github.com")
+	registry := site.New()
+	registry.Sites = []*site.Site{{Service: "https://www.GitLab.com/users/sign_in"}}
+
+	tests := []struct {
+		name    string
+		service string
+		want    []string
+	}{
+		{"Опечатка в имени из списка", "githib.com", []string{"github.com"}},
+		{"Опечатка в имени из реестра", "gitlabb.com", []string{"gitlab.com"}},
+		{"Имя без домена", "github", []string{"github.com"}},
+		{"Имя из списка", "github.com", nil},
+		{"Имя из реестра", "gitlab.com", nil},
+		{"Ничего похожего", "example.org", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serviceSuggestions(tt.service, record, registry); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("serviceSuggestions(%q) = %v, ожидается %v", tt.service, got, tt.want)
+			}
+		})
+	}
+
+	// Без списка похожие имена ищутся только в реестре
+	if got := serviceSuggestions("githib.com", nil, registry); got != nil {
+		t.Errorf("serviceSuggestions() без списка = %v", got)
+	}
+
+	cfg.ServiceRecord = "off"
+	if got := serviceSuggestions("githib.com", record, registry); got != nil {
+		t.Errorf("serviceSuggestions() с service_record=off = %v", got)
+	}
+}
+
+func TestBareServiceNameWarning(t *testing.T) {
+	savedCfg, savedRaw := cfg, rawServiceFlag
+	defer func() { cfg, rawServiceFlag = savedCfg, savedRaw }()
+	cfg, rawServiceFlag = config.DefaultConfig(), false
+
+	record := seen.New()
+	for _, service := range []string{"github.com", "https://github.com/login", "www.github.com"} {
+		name, err := canonicalService(service)
+		if err != nil || name != "github.com" {
+			t.Fatalf("canonicalService(%q) = %q, %v", service, name, err)
+		}
+		record.Add(name)
+	}
+
+	// Имя без точки не приводится к домену, но совпадение с известным
+	// доменом не даёт молча другой пароль
+	name, err := canonicalService("GitHub")
+	if err != nil {
+		t.Fatalf("canonicalService(GitHub) ошибка: %v", err)
+	}
+	if got := serviceSuggestions(name, record, site.New()); !reflect.DeepEqual(got, []string{"github.com"}) {
+		t.Errorf("serviceSuggestions(%q) = %v, ожидается [github.com]", name, got)
+	}
+}
+
+func TestChooseService(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+	suggestions := []string{"github.com", "gitlab.com"}
+
+	tests := []struct {
+		answer string
+		want   string
+	}{
+		{"1", "github.com"},
+		{" 2 ", "gitlab.com"},
+		{"n", "githib.com"},
+		{"Н", "githib.com"},
+	}
+	for _, tt := range tests {
+		if got := chooseService("githib.com", suggestions, tt.answer, messages); got != tt.want {
+			t.Errorf("chooseService(%q) = %q, ожидается %q", tt.answer, got, tt.want)
+		}
+	}
+}
+
+func TestRememberServices(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	messages := i18n.GetMessages(i18n.English, "test")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", os.Getenv("HOME"))
+
+	rememberServices(messages, "github.com", "bank")
+	record, _, err := loadServiceRecord()
+	if err != nil {
+		t.Fatalf("loadServiceRecord() ошибка: %v", err)
+	}
+	if !reflect.DeepEqual(record.Known(), []string{"bank", "github.com"}) {
+		t.Errorf("Known() = %v", record.Known())
+	}
+
+	cfg.ServiceRecord = "off"
+	if record, _, err := loadServiceRecord(); record != nil || err != nil {
+		t.Errorf("loadServiceRecord() с service_record=off = %v, %v", record, err)
+	}
+}
+
+func TestRememberServicesVault(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	// Рядом с хранилищем открытый список выдал бы имена сервисов
+	createTestVault(t)
+	rememberServices(messages, "github.com")
+	path, err := serviceRecordPath()
+	if err != nil {
+		t.Fatalf("serviceRecordPath() ошибка: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Список сервисов создан при включённом хранилище: %v", err)
+	}
+}
+
+func TestRememberServicesBlinded(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	cfg.ArgonTime, cfg.ArgonMemory, cfg.ArgonThreads = 1, 8*1024, 1
+	messages := i18n.GetMessages(i18n.English, "test")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", os.Getenv("HOME"))
+
+	// Открытые имена скрываются при первой генерации в режиме blinded
+	rememberServices(messages, "github.com")
+	cfg.ServiceRecord = "blinded"
+	unlockServiceRecord(security.NewSecureString("master"), messages)
+	defer closeServiceRecord()
+	rememberServices(messages, "bank")
+
+	record, path, err := loadServiceRecord()
+	if err != nil {
+		t.Fatalf("loadServiceRecord() ошибка: %v", err)
+	}
+	if !record.Blinded() || !record.Contains("github.com") || !record.Contains("bank") {
+		t.Errorf("loadServiceRecord() = %+v", record)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "github.com") {
+		t.Error("Файл скрытого списка содержит имя сервиса")
+	}
+
+	// Без ключа (пароль от агента) список закрыт и не пополняется
+	closeServiceRecord()
+	rememberServices(messages, "mail")
+	if record, _, _ := loadServiceRecord(); !record.Locked() || record.Contains("github.com") {
+		t.Error("Скрытый список без мастер-пароля не закрыт")
+	}
+
+	// Другой мастер-пароль не открывает список
+	unlockServiceRecord(security.NewSecureString("other"), messages)
+	if serviceRecordKey != nil {
+		t.Error("Ключ другого мастер-пароля принят")
+	}
+
+	unlockServiceRecord(security.NewSecureString("master"), messages)
+	if record, _, _ := loadServiceRecord(); !record.Contains("bank") || record.Contains("mail") {
+		t.Errorf("Скрытый список после повторного открытия = %+v", record)
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/site"
+)
+
+// Флаги имени сервиса
+var (
+	rawServiceFlag bool   // отключает приведение имени сервиса
+	newServiceFlag bool   // подтверждает новый сервис, похожий на известный
+	userFlag       string // имя пользователя для одного запуска
+)
+
+// serviceCommands команды, генерирующие пароль по имени сервиса
+func serviceCommands() []*cobra.Command {
+	return []*cobra.Command{rootCmd, getCmd, shellCmd, batchCmd}
+}
+
+func init() {
+	for _, cmd := range serviceCommands() {
+		cmd.Flags().BoolVarP(&rawServiceFlag, "raw-service", "", false, "")
+		cmd.Flags().StringVarP(&userFlag, "user", "", "", "")
+	}
+	// batch не спрашивает об опечатках, а только предупреждает
+	for _, cmd := range []*cobra.Command{rootCmd, getCmd, shellCmd} {
+		cmd.Flags().BoolVarP(&newServiceFlag, "new", "", false, "")
+	}
+}
+
+// updateServiceCommandTexts обновляет тексты флагов --raw-service, --new и --user
+func updateServiceCommandTexts(messages *i18n.Messages) {
+	flagDescs := map[string]string{
+		"raw-service": messages.RawServiceFlagDesc,
+		"new":         messages.NewServiceFlagDesc,
+		"user":        messages.UserFlagDesc,
+	}
+	for _, cmd := range serviceCommands() {
+		for name, usage := range flagDescs {
+			if flag := cmd.Flags().Lookup(name); flag != nil {
+				flag.Usage = usage
+			}
+		}
+	}
+}
+
+// validateUserFlag проверяет --user: имя из одних пробелов молча дало бы
+// пароль для имени по умолчанию
+func validateUserFlag(cmd *cobra.Command, messages *i18n.Messages) {
+	if cmd.Flags().Changed("user") && strings.TrimSpace(userFlag) == "" {
+		exitWithCode(exitUsage, messages.ConfigUsernameEmpty)
+	}
+}
+
+// canonicalService приводит имя сервиса к виду, заданному настройкой
+// service_canonical. С --raw-service имя используется как введено.
+func canonicalService(service string) (string, error) {
+	if rawServiceFlag {
+		return service, nil
+	}
+	return generator.CanonicalizeService(service, generator.ServiceCanonical(cfg.ServiceCanonical))
+}
+
+// resolveService возвращает имя сервиса для генерации и, если оно отличается
+// от введённого, показывает его в w до генерации
+func resolveService(w io.Writer, service string, messages *i18n.Messages) (string, error) {
+	canonical, err := canonicalService(service)
+	if err != nil {
+		return "", err
+	}
+	if canonical != service {
+		fmt.Fprintln(w, colors.SubtleMsg(fmt.Sprintf(messages.ServiceCanonicalized, canonical)))
+	}
+	return canonical, nil
+}
+
+// findSite возвращает настройки сервиса из реестра. Сервис в реестре может
+// быть записан в другой форме ("https://GitHub.com/login") или до включения
+// приведения имён, поэтому сравниваются приведённые имена.
+func findSite(registry *site.Registry, canonical string) *site.Site {
+	if s := registry.Get(canonical); s != nil {
+		return s
+	}
+	for _, s := range registry.Sites {
+		if name, err := canonicalService(s.Service); err == nil && name == canonical {
+			return s
+		}
+	}
+	return nil
+}
+
+// printSiteUsername показывает имя пользователя сервиса, если оно отличается
+// от имени в заголовке: настройки сервиса из реестра задают другой логин
+func printSiteUsername(w io.Writer, username string, messages *i18n.Messages) {
+	if username != siteUsername(nil) {
+		fmt.Fprintln(w, colors.SubtleMsg(fmt.Sprintf(messages.UsernameApplied, username)))
+	}
+}
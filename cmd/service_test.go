@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/fatih/color"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/site"
+)
+
+func TestCanonicalService(t *testing.T) {
+	savedCfg, savedRaw := cfg, rawServiceFlag
+	defer func() { cfg, rawServiceFlag = savedCfg, savedRaw }()
+	cfg = config.DefaultConfig()
+
+	tests := []struct {
+		name      string
+		canonical string
+		raw       bool
+		expected  string
+	}{
+		{"Хост", "host", false, "accounts.google.com"},
+		{"Домен", "domain", false, "google.com"},
+		{"Без приведения", "off", false, "https://www.Accounts.Google.com/signin"},
+		{"--raw-service", "domain", true, "https://www.Accounts.Google.com/signin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.ServiceCanonical, rawServiceFlag = tt.canonical, tt.raw
+			got, err := canonicalService("https://www.Accounts.Google.com/signin")
+			if err != nil {
+				t.Fatalf("canonicalService() ошибка: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("canonicalService() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveService(t *testing.T) {
+	savedCfg, savedRaw, savedNoColor := cfg, rawServiceFlag, color.NoColor
+	defer func() { cfg, rawServiceFlag, color.NoColor = savedCfg, savedRaw, savedNoColor }()
+	cfg, rawServiceFlag, color.NoColor = config.DefaultConfig(), false, true
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	// Приведённое имя показывается до генерации
+	var out bytes.Buffer
+	service, err := resolveService(&out, "https://GitHub.com/login", messages)
+	if err != nil || service != "github.com" {
+		t.Fatalf("resolveService() = %q, %v", service, err)
+	}
+	if expected := fmt.Sprintf(messages.ServiceCanonicalized, "github.com") + "\n"; out.String() != expected {
+		t.Errorf("Вывод = %q, ожидается %q", out.String(), expected)
+	}
+
+	// Имя, которое не изменилось, не повторяется
+	out.Reset()
+	if _, err := resolveService(&out, "github.com", messages); err != nil || out.Len() != 0 {
+		t.Errorf("resolveService() без изменений вывел %q, ошибка %v", out.String(), err)
+	}
+
+	if _, err := resolveService(&out, "   ", messages); err == nil || err.Error() != "service_empty" {
+		t.Errorf("resolveService() из пробелов ошибка = %v, ожидается service_empty", err)
+	}
+}
+
+func TestFindSite(t *testing.T) {
+	savedCfg, savedRaw := cfg, rawServiceFlag
+	defer func() { cfg, rawServiceFlag = savedCfg, savedRaw }()
+	cfg, rawServiceFlag = config.DefaultConfig(), false
+
+	registry := site.New()
+	registry.Sites = []*site.Site{
+		{Service: "github.com", Length: 20},
+		{Service: "https://www.GitLab.com/users/sign_in", Length: 24},
+	}
+
+	if s := findSite(registry, "github.com"); s == nil || s.Length != 20 {
+		t.Errorf("findSite(github.com) = %+v", s)
+	}
+	// Сервис, записанный в реестр полным адресом, находится по хосту
+	if s := findSite(registry, "gitlab.com"); s == nil || s.Length != 24 {
+		t.Errorf("findSite(gitlab.com) = %+v", s)
+	}
+	if s := findSite(registry, "bitbucket.org"); s != nil {
+		t.Errorf("findSite(bitbucket.org) = %+v, ожидается nil", s)
+	}
+
+	// С --raw-service имена сравниваются как есть
+	rawServiceFlag = true
+	if s := findSite(registry, "gitlab.com"); s != nil {
+		t.Errorf("findSite(gitlab.com) с --raw-service = %+v, ожидается nil", s)
+	}
+}
+
+func TestPrintSiteUsername(t *testing.T) {
+	savedCfg, savedUser, savedNoColor := cfg, userFlag, color.NoColor
+	defer func() { cfg, userFlag, color.NoColor = savedCfg, savedUser, savedNoColor }()
+	cfg, userFlag, color.NoColor = config.DefaultConfig(), "", true
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	// Логин сервиса, отличный от заголовка, показывается
+	var out bytes.Buffer
+	printSiteUsername(&out, "work", messages)
+	if expected := fmt.Sprintf(messages.UsernameApplied, "work") + "\n"; out.String() != expected {
+		t.Errorf("Вывод = %q, ожидается %q", out.String(), expected)
+	}
+
+	// Логин из заголовка не повторяется
+	out.Reset()
+	printSiteUsername(&out, cfg.Username, messages)
+	userFlag = "work"
+	printSiteUsername(&out, "work", messages)
+	if out.Len() != 0 {
+		t.Errorf("Вывод = %q, ожидается пустой", out.String())
+	}
+}
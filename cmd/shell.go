@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/MaksymLeiber/pgen/internal/clipboard"
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/input"
+	"github.com/MaksymLeiber/pgen/internal/report"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/shell"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/internal/validator"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// defaultShellIdleTimeout простой, после которого сеанс стирает мастер-пароль
+const defaultShellIdleTimeout = 5 * time.Minute
+
+// shellHistoryFile файл истории имён сервисов рядом с config.json
+const shellHistoryFile = "shell_history"
+
+// Флаги интерактивного сеанса
+var (
+	shellIdleTimeoutFlag time.Duration
+	shellNoHistoryFlag   bool
+)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "",
+	Long:  "",
+	Args:  cobra.NoArgs,
+	Run:   runShellCommand,
+}
+
+func init() {
+	shellCmd.Flags().DurationVarP(&shellIdleTimeoutFlag, "idle-timeout", "", defaultShellIdleTimeout, "")
+	shellCmd.Flags().BoolVarP(&shellNoHistoryFlag, "no-history", "", false, "")
+	addMasterSourceFlags(shellCmd)
+	addGenerationFlags(shellCmd)
+}
+
+// updateShellCommandTexts обновляет тексты команды интерактивного сеанса
+func updateShellCommandTexts(messages *i18n.Messages) {
+	shellCmd.Short = messages.ShellShort
+	shellCmd.Long = messages.ShellLong
+	updateFlagTexts(shellCmd, messages)
+	updateMasterSourceFlagTexts(shellCmd, messages)
+
+	flagDescs := map[string]string{
+		"idle-timeout": messages.ShellIdleTimeoutFlagDesc,
+		"no-history":   messages.ShellNoHistoryFlagDesc,
+	}
+	for name, usage := range flagDescs {
+		if flag := shellCmd.Flags().Lookup(name); flag != nil {
+			flag.Usage = usage
+		}
+	}
+}
+
+// shellSession состояние интерактивного сеанса
+type shellSession struct {
+	cmd        *cobra.Command
+	messages   *i18n.Messages
+	out        io.Writer // сообщения сеанса; при JSON выводе stdout занят документами
+	jsonOutput bool
+
+	// Параметры сеанса без настроек сервиса; length и counter - значения
+	// :len и :counter, которые перекрывают и настройки сервиса из реестра
+	opts    pgen.Options
+	gen     *pgen.Generator
+	length  int
+	counter uint32
+
+	master     *security.SecureString
+	masterKeys map[string]*pgen.MasterKey // мастер-ключи v3 по имени пользователя
+	sites      *site.Registry
+
+	history     *shell.History
+	historyPath string
+
+	// Новое имя, похожее на известный сервис: повторный ввод подтверждает его
+	unconfirmed string
+
+	// Последний пароль для :copy и :info вместе с параметрами его генерации
+	last         *security.SecureString
+	lastOpts     pgen.Options
+	lastAlphabet string
+
+	clipboardCleared <-chan bool
+}
+
+func runShellCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	jsonOutput := isJSONOutput(messages)
+	validateUserFlag(cmd, messages)
+
+	if configLoadErr != nil {
+		exitWithCode(exitConfig, configLoadErr.Error())
+	}
+	opts, err := resolveGeneratorOptions(cmd, nil)
+	if err != nil {
+		exitWithCode(exitConfig, getGeneratorErrorText(err, messages))
+	}
+	gen, err := pgen.New(opts)
+	if err != nil {
+		exitWithCode(exitConfig, getGeneratorErrorText(err, messages))
+	}
+
+	session := &shellSession{
+		cmd:        cmd,
+		messages:   messages,
+		out:        promptWriter(jsonOutput),
+		jsonOutput: jsonOutput,
+		opts:       opts,
+		gen:        gen,
+		masterKeys: make(map[string]*pgen.MasterKey),
+		history:    shell.NewHistory(shell.DefaultHistoryLimit),
+	}
+	// С зашифрованным хранилищем открытая история выдала бы имена сервисов
+	if !shellNoHistoryFlag && !vaultEnabled() {
+		session.loadHistory()
+	}
+
+	if !jsonOutput {
+		fmt.Println(colors.TitleMsg(formatTitleWithUser(messages.AppTitle, displayUsername(messages), messages)))
+		fmt.Println(colors.SubtleMsg(messages.AppSubtitle + "\n"))
+	}
+
+	// Строки читаются одним буферизованным читателем: мастер-пароль из
+	// канала и следующие за ним команды
+	stdin := bufio.NewReader(os.Stdin)
+	session.unlock(stdin)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		for {
+			line, err := stdin.ReadString('\n')
+			if line != "" {
+				lines <- line
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var idle *time.Timer
+	var idleC <-chan time.Time
+	if shellIdleTimeoutFlag > 0 {
+		idle = time.NewTimer(shellIdleTimeoutFlag)
+		idleC = idle.C
+	}
+
+	fmt.Fprintln(session.out, colors.SubtleMsg(messages.ShellWelcome))
+	for {
+		fmt.Fprint(session.out, colors.PromptMsg(messages.ShellPrompt))
+		select {
+		case line, ok := <-lines:
+			if !ok || session.execute(line) {
+				session.close()
+				fmt.Fprintf(session.out, "\n%s\n", colors.SubtleMsg(messages.ShellBye))
+				return
+			}
+			if idle != nil {
+				idle.Reset(shellIdleTimeoutFlag)
+			}
+		case <-idleC:
+			session.close()
+			fmt.Fprintf(session.out, "\n%s\n", colors.SubtleMsg(fmt.Sprintf(messages.ShellTimeout, shellIdleTimeoutFlag)))
+			return
+		case <-signals:
+			session.close()
+			fmt.Fprintln(os.Stderr)
+			exitWithError(messages.Errors.UserCanceled)
+		}
+	}
+}
+
+// unlock получает мастер-пароль, открывает хранилище и для v3 сразу вычисляет
+// мастер-ключ пользователя по умолчанию
+func (s *shellSession) unlock(stdin *bufio.Reader) {
+	masterPassword, err := readMasterSource(s.cmd)
+	switch {
+	case err != nil && err.Error() == "master_source_missing" && term.IsTerminal(int(os.Stdin.Fd())):
+		masterPassword, err = promptMasterPassword(s.messages)
+		if err != nil {
+			exitWithCode(exitMaster, err.Error())
+		}
+	case err != nil && err.Error() == "master_source_missing":
+		// Без терминала мастер-пароль - первая строка stdin
+		masterPassword, err = input.ReadMasterPassword(stdin)
+		if err != nil {
+			exitWithCode(exitMaster, getMasterSourceErrorText(err, s.messages))
+		}
+	case err != nil:
+		exitWithCode(exitMaster, getMasterSourceErrorText(err, s.messages))
+	}
+	if masterPassword.IsEmpty() {
+		masterPassword.Clear()
+		exitWithCode(exitMaster, s.messages.Errors.EmptyMaster)
+	}
+
+	if !s.jsonOutput {
+		displayPasswordStrength(validator.ValidatePasswordStrength(masterPassword.String(), s.messages), s.messages)
+	}
+
+	s.master = masterPassword
+	verifyMaster(masterPassword, s.messages)
+	unlockVault(masterPassword, s.messages)
+	unlockServiceRecord(masterPassword, s.messages)
+	s.sites, _ = loadSites(s.messages)
+	if s.opts.Algorithm == pgen.AlgorithmV3 {
+		fmt.Fprintln(s.out, colors.SubtleMsg(s.messages.ShellDerivingKey))
+		if _, err := s.masterKey(s.gen, siteUsername(nil)); err != nil {
+			s.close()
+			exitWithCode(exitGeneration, getGeneratorErrorText(err, s.messages))
+		}
+	}
+}
+
+// masterKey возвращает мастер-ключ v3 пользователя, вычисляя его один раз за сеанс
+func (s *shellSession) masterKey(gen *pgen.Generator, username string) (*pgen.MasterKey, error) {
+	if masterKey, ok := s.masterKeys[username]; ok {
+		return masterKey, nil
+	}
+	master := s.master.Bytes()
+	defer security.ZeroMemory(master)
+	masterKey, err := gen.DeriveMasterKey(context.Background(), master, username)
+	if err != nil {
+		return nil, err
+	}
+	// Без mlock ключ всё равно стирается при выходе
+	masterKey.LockMemory()
+	s.masterKeys[username] = masterKey
+	return masterKey, nil
+}
+
+// execute выполняет строку сеанса. Возвращает true, если сеанс завершён.
+func (s *shellSession) execute(line string) bool {
+	command, err := shell.Parse(line)
+	if err != nil {
+		s.printError(getShellErrorText(err, line, s.messages))
+		return false
+	}
+
+	switch command.Kind {
+	case shell.KindService:
+		s.generate(command.Service)
+	case shell.KindRecall:
+		service, err := s.history.Get(command.Value)
+		if err != nil {
+			s.printError(getShellErrorText(err, line, s.messages))
+			return false
+		}
+		s.generate(service)
+	case shell.KindLength:
+		opts := s.opts
+		opts.Length = command.Value
+		if s.setOptions(opts) {
+			s.length = command.Value
+			fmt.Fprintln(s.out, colors.SuccessMsg(fmt.Sprintf(s.messages.ShellLengthSet, command.Value)))
+		}
+	case shell.KindCounter:
+		if err := generator.ValidateCounter(command.Value); err != nil {
+			s.printError(getCounterErrorText(err, s.messages))
+			return false
+		}
+		opts := s.opts
+		opts.Counter = uint32(command.Value)
+		if s.setOptions(opts) {
+			s.counter = opts.Counter
+			fmt.Fprintln(s.out, colors.SuccessMsg(fmt.Sprintf(s.messages.ShellCounterSet, command.Value)))
+		}
+	case shell.KindCopy:
+		s.copyLast()
+	case shell.KindInfo:
+		s.infoLast()
+	case shell.KindHistory:
+		s.printHistory()
+	case shell.KindHelp:
+		fmt.Fprintln(s.out, colors.InfoMsg(s.messages.ShellHelp))
+	case shell.KindQuit:
+		return true
+	}
+	return false
+}
+
+// setOptions применяет параметры следующих паролей, если генератор их принимает
+func (s *shellSession) setOptions(opts pgen.Options) bool {
+	gen, err := pgen.New(opts)
+	if err != nil {
+		s.printError(getGeneratorErrorText(err, s.messages))
+		return false
+	}
+	s.opts, s.gen = opts, gen
+	return true
+}
+
+// serviceGenerator возвращает генератор сервиса: для сервиса из реестра
+// параметры сеанса дополняются его настройками
+func (s *shellSession) serviceGenerator(registered *site.Site) (pgen.Options, *pgen.Generator, error) {
+	if registered == nil {
+		return s.opts, s.gen, nil
+	}
+	opts, err := resolveGeneratorOptions(s.cmd, registered)
+	if err != nil {
+		return opts, nil, err
+	}
+	if s.length > 0 {
+		opts.Length = s.length
+	}
+	if s.counter > 0 {
+		opts.Counter = s.counter
+	}
+	gen, err := pgen.New(opts)
+	return opts, gen, err
+}
+
+// generate генерирует и печатает пароль сервиса
+func (s *shellSession) generate(service string) {
+	service, err := resolveService(s.out, service, s.messages)
+	if err != nil {
+		s.printError(getGeneratorErrorText(err, s.messages))
+		return
+	}
+	if !s.confirmService(service) {
+		return
+	}
+	registered := findSite(s.sites, service)
+	username := siteUsername(registered)
+	opts, gen, err := s.serviceGenerator(registered)
+	if err != nil {
+		s.printError(getGeneratorErrorText(err, s.messages))
+		return
+	}
+
+	startTime := time.Now()
+	var password *security.SecureString
+	if opts.Algorithm == pgen.AlgorithmV3 {
+		var masterKey *pgen.MasterKey
+		if masterKey, err = s.masterKey(gen, username); err == nil {
+			password, err = generateWithMasterKey(gen, masterKey, service)
+		}
+	} else {
+		password, err = generatePassword(gen, s.master, service, username)
+	}
+	if err != nil {
+		s.printError(getGeneratorErrorText(err, s.messages))
+		return
+	}
+
+	cfg.IncrementPasswordCount(time.Since(startTime).Milliseconds())
+	if err := cfg.Save(s.messages); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", colors.SubtleMsg(s.messages.StatSaveError), err)
+	}
+
+	s.history.Add(service)
+	rememberServices(s.messages, service)
+	if s.last != nil {
+		s.last.Clear()
+	}
+	s.last, s.lastOpts, s.lastAlphabet = password, opts, gen.Alphabet()
+
+	if s.jsonOutput {
+		writeReport(generationReport(password, service, username, opts), s.messages)
+	} else {
+		if registered != nil {
+			fmt.Println(colors.SubtleMsg(s.messages.SiteApplied))
+			printSiteUsername(os.Stdout, username, s.messages)
+		}
+		printGenerated(password, opts, s.messages)
+		if cfg.ShowPasswordInfo {
+			printGeneratedInfo(password, opts, s.lastAlphabet, s.messages)
+		}
+	}
+	if cfg.DefaultCopy {
+		s.copyLast()
+	}
+}
+
+// confirmService проверяет новое имя сервиса на опечатку. Вопрос в сеансе
+// не задаётся: строки уже читаются как команды, поэтому похожие имена только
+// показываются, а новый сервис подтверждается повторным вводом имени.
+func (s *shellSession) confirmService(service string) bool {
+	if newServiceFlag || service == s.unconfirmed {
+		s.unconfirmed = ""
+		return true
+	}
+	record, _, _ := loadServiceRecord()
+	suggestions := serviceSuggestions(service, record, s.sites)
+	if len(suggestions) == 0 {
+		s.unconfirmed = ""
+		return true
+	}
+	s.unconfirmed = service
+	s.printError(fmt.Sprintf(s.messages.ServiceTypo, service, strings.Join(suggestions, ", ")))
+	fmt.Fprintln(s.out, colors.SubtleMsg(s.messages.ServiceTypoShellHint))
+	return false
+}
+
+// copyLast копирует последний пароль в буфер обмена. Очистка буфера идёт в
+// фоне, сеанс не ждёт её.
+func (s *shellSession) copyLast() {
+	if s.last == nil {
+		s.printError(s.messages.ShellNoPassword)
+		return
+	}
+	timeout := effectiveClearTimeout(s.cmd)
+	cleared, err := clipboard.CopyToClipboardWithTimeout(s.last.String(), time.Duration(timeout)*time.Second)
+	if err != nil {
+		s.printError(fmt.Sprintf("%s %v", s.messages.Errors.ClipboardError+":", err))
+		return
+	}
+	s.clipboardCleared = cleared
+	fmt.Fprintf(s.out, "%s %s\n", colors.SuccessMsg("✓"), colors.SuccessMsg(s.messages.CopiedToClipboard))
+	if timeout > 0 {
+		fmt.Fprintf(s.out, "%s %s %ds\n", colors.SubtleMsg("⏱"), colors.SubtleMsg(s.messages.ClipboardWillClear), timeout)
+	}
+}
+
+// infoLast печатает анализ последнего пароля
+func (s *shellSession) infoLast() {
+	if s.last == nil {
+		s.printError(s.messages.ShellNoPassword)
+		return
+	}
+	if s.jsonOutput {
+		if info := analyzeGenerated(s.last.String(), s.lastOpts, s.lastAlphabet, s.messages); info != nil {
+			writeReport(report.NewInfo(info), s.messages)
+		}
+		return
+	}
+	printGeneratedInfo(s.last, s.lastOpts, s.lastAlphabet, s.messages)
+}
+
+// printHistory печатает пронумерованную историю имён сервисов
+func (s *shellSession) printHistory() {
+	entries := s.history.Entries()
+	if len(entries) == 0 {
+		fmt.Fprintln(s.out, colors.SubtleMsg(s.messages.ShellHistoryEmpty))
+		return
+	}
+	for i, service := range entries {
+		fmt.Fprintf(s.out, "%s %s\n", colors.SubtleMsg(fmt.Sprintf("%3d", i+1)), service)
+	}
+}
+
+// printError печатает ошибку команды, не завершая сеанс
+func (s *shellSession) printError(text string) {
+	fmt.Fprintln(os.Stderr, colors.ErrorMsg(text))
+}
+
+// loadHistory читает историю сеансов. Без истории сеанс работает как обычно.
+func (s *shellSession) loadHistory() {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return
+	}
+	s.historyPath = filepath.Join(filepath.Dir(configPath), shellHistoryFile)
+	if history, err := shell.LoadHistory(s.historyPath, shell.DefaultHistoryLimit); err == nil {
+		s.history = history
+	}
+}
+
+// close стирает секреты, очищает не очищенный ещё буфер обмена и сохраняет историю
+func (s *shellSession) close() {
+	if s.master != nil {
+		s.master.Clear()
+	}
+	for username, masterKey := range s.masterKeys {
+		masterKey.Clear()
+		delete(s.masterKeys, username)
+	}
+	closeVault()
+	closeServiceRecord()
+	if s.last != nil {
+		s.last.Clear()
+	}
+
+	if s.clipboardCleared != nil {
+		select {
+		case <-s.clipboardCleared:
+		default:
+			clipboard.ClearClipboard()
+		}
+	}
+
+	if s.historyPath != "" {
+		if err := s.history.Save(s.historyPath); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", colors.SubtleMsg(s.messages.ShellHistorySaveError), err)
+		}
+	}
+}
+
+// getShellErrorText возвращает текст ошибки команды сеанса на соответствующем языке
+func getShellErrorText(err error, line string, messages *i18n.Messages) string {
+	line = strings.TrimSpace(line)
+	switch err.Error() {
+	case "command_unknown":
+		return fmt.Sprintf(messages.ShellUnknownCommand, line)
+	case "argument_missing":
+		return fmt.Sprintf(messages.ShellArgumentMissing, line)
+	case "argument_invalid":
+		return fmt.Sprintf(messages.ShellArgumentInvalid, line)
+	case "history_entry_missing":
+		return fmt.Sprintf(messages.ShellHistoryMissing, line)
+	default:
+		return err.Error()
+	}
+}
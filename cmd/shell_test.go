@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/shell"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+func TestGetShellErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Неизвестная команда", errors.New("command_unknown"), "Unknown command :dump, list commands with :help"},
+		{"Нет аргумента", errors.New("argument_missing"), "Command :dump needs a numeric argument"},
+		{"Неверный аргумент", errors.New("argument_invalid"), "Invalid argument in :dump"},
+		{"Нет записи истории", errors.New("history_entry_missing"), "The history has no entry :dump"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getShellErrorText(tt.err, " :dump\n", messages); got != tt.expected {
+				t.Errorf("getShellErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShellSessionExecute(t *testing.T) {
+	opts := pgen.DefaultOptions()
+	gen, err := pgen.New(opts)
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+	session := &shellSession{
+		cmd:      shellCmd,
+		messages: i18n.GetMessages(i18n.English, "test"),
+		out:      io.Discard,
+		opts:     opts,
+		gen:      gen,
+		sites:    site.New(),
+		history:  shell.NewHistory(shell.DefaultHistoryLimit),
+	}
+
+	for _, line := range []string{":len 24", ":counter 3", ":copy", ":info", ":history", "!1", ":help"} {
+		if session.execute(line) {
+			t.Errorf("execute(%q) завершил сеанс", line)
+		}
+	}
+	if session.opts.Length != 24 || session.opts.Counter != 3 {
+		t.Errorf("Параметры сеанса: длина %d, счётчик %d, ожидается 24 и 3", session.opts.Length, session.opts.Counter)
+	}
+
+	// Недопустимые значения не меняют параметры сеанса
+	session.execute(":len 2")
+	session.execute(":counter 0")
+	if session.opts.Length != 24 || session.opts.Counter != 3 {
+		t.Errorf("Недопустимые значения применены: длина %d, счётчик %d", session.opts.Length, session.opts.Counter)
+	}
+	if session.gen.Options().Length != 24 {
+		t.Errorf("Генератор не пересоздан: длина %d", session.gen.Options().Length)
+	}
+
+	if !session.execute(":quit") {
+		t.Error("execute(:quit) не завершил сеанс")
+	}
+}
@@ -0,0 +1,438 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/report"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// Флаги команд реестра сервисов
+var (
+	siteUserFlag    string
+	siteCharsetFlag string
+	siteNoteFlag    string
+	siteUnsetFlag   []string
+)
+
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "",
+	Long:  "",
+}
+
+var siteAddCmd = &cobra.Command{
+	Use:   "add <service>",
+	Short: "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runSiteAddCommand,
+}
+
+var siteEditCmd = &cobra.Command{
+	Use:   "edit <service>",
+	Short: "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runSiteEditCommand,
+}
+
+var siteRmCmd = &cobra.Command{
+	Use:   "rm <service>",
+	Short: "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runSiteRmCommand,
+}
+
+var siteLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runSiteLsCommand,
+}
+
+var siteShowCmd = &cobra.Command{
+	Use:   "show <service>",
+	Short: "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runSiteShowCommand,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{siteAddCmd, siteEditCmd} {
+		addGenerationFlags(cmd)
+		cmd.Flags().StringVarP(&siteUserFlag, "user", "", "", "")
+		cmd.Flags().StringVarP(&siteCharsetFlag, "charset", "", "", "")
+		cmd.Flags().StringVarP(&siteNoteFlag, "note", "", "", "")
+	}
+	siteEditCmd.Flags().StringSliceVarP(&siteUnsetFlag, "unset", "", nil, "")
+
+	for _, cmd := range []*cobra.Command{siteAddCmd, siteEditCmd, siteRmCmd, siteLsCmd, siteShowCmd} {
+		addMasterSourceFlags(cmd)
+	}
+
+	siteCmd.AddCommand(siteAddCmd)
+	siteCmd.AddCommand(siteEditCmd)
+	siteCmd.AddCommand(siteRmCmd)
+	siteCmd.AddCommand(siteLsCmd)
+	siteCmd.AddCommand(siteShowCmd)
+}
+
+// updateSiteCommandTexts обновляет тексты команд реестра сервисов
+func updateSiteCommandTexts(messages *i18n.Messages) {
+	siteCmd.Short = messages.SiteShort
+	siteCmd.Long = messages.SiteLong
+	siteAddCmd.Short = messages.SiteAddShort
+	siteEditCmd.Short = messages.SiteEditShort
+	siteRmCmd.Short = messages.SiteRmShort
+	siteLsCmd.Short = messages.SiteLsShort
+	siteShowCmd.Short = messages.SiteShowShort
+
+	flagDescs := map[string]string{
+		"user":    messages.SiteUserFlagDesc,
+		"charset": messages.SiteCharsetFlagDesc,
+		"note":    messages.SiteNoteFlagDesc,
+		"unset":   fmt.Sprintf(messages.SiteUnsetFlagDesc, strings.Join(site.Fields, ", ")),
+	}
+	for _, cmd := range []*cobra.Command{siteAddCmd, siteEditCmd, siteRmCmd, siteLsCmd, siteShowCmd} {
+		updateMasterSourceFlagTexts(cmd, messages)
+	}
+	for _, cmd := range []*cobra.Command{siteAddCmd, siteEditCmd} {
+		updateFlagTexts(cmd, messages)
+		for name, usage := range flagDescs {
+			if flag := cmd.Flags().Lookup(name); flag != nil {
+				flag.Usage = usage
+			}
+		}
+	}
+}
+
+// sitesPath возвращает путь к реестру сервисов рядом с config.json
+func sitesPath() (string, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), site.FileName), nil
+}
+
+// loadSites читает реестр сервисов из sites.json или из открытого хранилища.
+// Ошибка завершает программу: молча пропущенные настройки сервиса дали бы
+// другой пароль.
+func loadSites(messages *i18n.Messages) (*site.Registry, string) {
+	if openedVault != nil {
+		sites, err := openedVault.Sites()
+		if err != nil {
+			exitWithCode(exitConfig, getVaultErrorText(err, messages))
+		}
+		registry := site.New()
+		registry.Sites = sites
+		path, _ := vaultPath()
+		return registry, path
+	}
+	if vaultEnabled() {
+		// Реестр зашифрован, а мастер-пароль ещё не получен
+		exitWithCode(exitMaster, messages.VaultAgentUnsupported)
+	}
+
+	path, err := sitesPath()
+	if err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.SiteLoadError, err))
+	}
+	registry, err := site.Load(path)
+	if err != nil {
+		exitWithCode(exitConfig, getSiteErrorText(err, "", messages))
+	}
+	return registry, path
+}
+
+// lookupSite возвращает настройки сервиса из реестра или nil. В хранилище
+// сначала расшифровывается только запись этого сервиса, остальные - лишь если
+// сервис записан под другой формой имени.
+func lookupSite(service string, messages *i18n.Messages) *site.Site {
+	if openedVault != nil {
+		s, err := openedVault.Site(service)
+		if err != nil {
+			exitWithCode(exitConfig, getVaultErrorText(err, messages))
+		}
+		if s != nil {
+			return s
+		}
+	}
+	registry, _ := loadSites(messages)
+	return findSite(registry, service)
+}
+
+// saveSites записывает реестр сервисов в sites.json или в открытое хранилище
+func saveSites(registry *site.Registry, path string, messages *i18n.Messages) {
+	if openedVault != nil {
+		err := openedVault.SetSites(registry.Sites)
+		if err == nil {
+			err = openedVault.Save(path)
+		}
+		if err != nil {
+			exitWithError(fmt.Sprintf("%s %v", messages.VaultSaveError, err))
+		}
+		return
+	}
+	if err := registry.Save(path); err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.SiteSaveError, err))
+	}
+}
+
+func runSiteAddCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	unlockVaultFromFlags(cmd, messages)
+	defer closeVault()
+	registry, path := loadSites(messages)
+
+	s := &site.Site{Service: args[0]}
+	applySiteFlags(cmd, s)
+	validateSite(cmd, s, messages)
+	if err := registry.Add(s); err != nil {
+		exitWithError(getSiteErrorText(err, args[0], messages))
+	}
+	saveSites(registry, path, messages)
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "site_add", Key: s.Service, File: path}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.SiteAdded, s.Service)))
+}
+
+func runSiteEditCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	unlockVaultFromFlags(cmd, messages)
+	defer closeVault()
+	registry, path := loadSites(messages)
+
+	existing := registry.Get(args[0])
+	if existing == nil {
+		exitWithError(fmt.Sprintf(messages.SiteNotFound, args[0]))
+	}
+	s := *existing
+	for _, field := range siteUnsetFlag {
+		if err := s.Unset(strings.TrimSpace(field)); err != nil {
+			exitWithCode(exitUsage, getSiteErrorText(err, field, messages))
+		}
+	}
+	applySiteFlags(cmd, &s)
+	validateSite(cmd, &s, messages)
+	if err := registry.Update(&s); err != nil {
+		exitWithError(getSiteErrorText(err, args[0], messages))
+	}
+	saveSites(registry, path, messages)
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "site_edit", Key: s.Service, File: path}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.SiteUpdated, s.Service)))
+}
+
+func runSiteRmCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	unlockVaultFromFlags(cmd, messages)
+	defer closeVault()
+	registry, path := loadSites(messages)
+
+	if err := registry.Remove(args[0]); err != nil {
+		exitWithError(getSiteErrorText(err, args[0], messages))
+	}
+	saveSites(registry, path, messages)
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "site_rm", Key: args[0], File: path}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.SiteRemoved, args[0])))
+}
+
+func runSiteLsCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	unlockVaultFromFlags(cmd, messages)
+	defer closeVault()
+	registry, _ := loadSites(messages)
+
+	if isJSONOutput(messages) {
+		writeReport(registry.Sites, messages)
+		return
+	}
+	if len(registry.Sites) == 0 {
+		fmt.Println(colors.SubtleMsg(messages.SiteEmpty))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", messages.SiteColumnService, messages.SiteColumnUser,
+		messages.SiteColumnLength, messages.SiteColumnCounter, messages.SiteColumnMode, messages.SiteColumnNote)
+	for _, s := range registry.Sites {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Service, siteUsername(s),
+			siteValue(s.Length), siteValue(int(s.Counter)), siteMode(s), s.Note)
+	}
+	writer.Flush()
+}
+
+func runSiteShowCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	unlockVaultFromFlags(cmd, messages)
+	defer closeVault()
+	registry, _ := loadSites(messages)
+
+	s := registry.Get(args[0])
+	if s == nil {
+		exitWithError(fmt.Sprintf(messages.SiteNotFound, args[0]))
+	}
+	if isJSONOutput(messages) {
+		writeReport(s, messages)
+		return
+	}
+
+	fmt.Println(colors.TitleMsg(s.Service))
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"user", s.Username},
+		{"length", siteValue(s.Length)},
+		{"counter", siteValue(int(s.Counter))},
+		{"charset", s.Charset},
+		{"require", s.RequiredClasses},
+		{"type", s.Type},
+		{"algorithm", s.Algorithm},
+		{"passphrase", siteFlag(s.Passphrase)},
+		{"words", siteValue(s.Words)},
+		{"separator", strconv.Quote(s.Separator)},
+		{"wordlist", s.Wordlist},
+		{"capitalize", siteFlag(s.Capitalize)},
+		{"digit", siteFlag(s.Digit)},
+		{"note", s.Note},
+	}
+	for _, field := range fields {
+		if field.value == "" || field.value == `""` {
+			continue
+		}
+		fmt.Printf("  %s %s\n", colors.SubtleMsg(field.name+":"), field.value)
+	}
+	fmt.Println(colors.SubtleMsg(messages.SiteDefaultsInfo))
+}
+
+// applySiteFlags переносит в настройки сервиса только явно заданные флаги
+func applySiteFlags(cmd *cobra.Command, s *site.Site) {
+	flags := cmd.Flags()
+	if flags.Changed("user") {
+		s.Username = siteUserFlag
+	}
+	if flags.Changed("length") {
+		s.Length = lengthFlag
+	}
+	if flags.Changed("counter") && counterFlag > 0 {
+		s.Counter = uint32(counterFlag)
+	}
+	if flags.Changed("charset") {
+		s.Charset = siteCharsetFlag
+	}
+	if flags.Changed("require") {
+		s.RequiredClasses = requireFlag
+	}
+	if flags.Changed("type") {
+		s.Type, s.Passphrase = typeFlag, false
+	}
+	if flags.Changed("algorithm") {
+		s.Algorithm = algorithmFlag
+	}
+	if flags.Changed("passphrase") {
+		s.Passphrase = passphraseFlag
+		if passphraseFlag {
+			s.Type = ""
+		}
+	}
+	if flags.Changed("words") {
+		s.Words = wordsFlag
+	}
+	if flags.Changed("separator") {
+		s.Separator = separatorFlag
+	}
+	if flags.Changed("wordlist") {
+		s.Wordlist = wordlistFlag
+	}
+	if flags.Changed("capitalize") {
+		s.Capitalize = capitalizeFlag
+	}
+	if flags.Changed("digit") {
+		s.Digit = digitFlag
+	}
+	if flags.Changed("note") {
+		s.Note = siteNoteFlag
+	}
+}
+
+// validateSite проверяет, что с настройками сервиса генератор создаётся, чтобы
+// ошибка обнаружилась при сохранении, а не при генерации
+func validateSite(cmd *cobra.Command, s *site.Site, messages *i18n.Messages) {
+	opts, err := resolveGeneratorOptions(cmd, s)
+	if err == nil {
+		_, err = pgen.New(opts)
+	}
+	if err != nil {
+		exitWithCode(exitUsage, getGeneratorErrorText(err, messages))
+	}
+}
+
+// siteValue форматирует числовую настройку, пустая строка - не задана
+func siteValue(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+// siteFlag форматирует логическую настройку
+func siteFlag(value bool) string {
+	if !value {
+		return ""
+	}
+	return "true"
+}
+
+// siteMode возвращает режим генерации сервиса для списка
+func siteMode(s *site.Site) string {
+	switch {
+	case s.Passphrase:
+		return string(pgen.ModePassphrase)
+	case s.Type != "":
+		return s.Type
+	default:
+		return ""
+	}
+}
+
+// getSiteErrorText возвращает текст ошибки реестра сервисов на соответствующем языке
+func getSiteErrorText(err error, name string, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "site_exists":
+		return fmt.Sprintf(messages.SiteExists, name)
+	case "site_not_found":
+		return fmt.Sprintf(messages.SiteNotFound, name)
+	case "site_field_unknown":
+		return fmt.Sprintf(messages.SiteFieldUnknown, name, strings.Join(site.Fields, ", "))
+	case "service_empty":
+		return messages.Errors.EmptyService
+	case "registry_invalid":
+		return messages.SiteInvalid
+	case "registry_version":
+		return messages.SiteVersion
+	default:
+		return fmt.Sprintf("%s %v", messages.SiteLoadError, err)
+	}
+}
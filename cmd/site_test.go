@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MaksymLeiber/pgen/internal/batch"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// newSiteTestCommand создаёт команду с флагами site add
+func newSiteTestCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	addGenerationFlags(cmd)
+	cmd.Flags().StringVar(&siteUserFlag, "user", "", "")
+	cmd.Flags().StringVar(&siteCharsetFlag, "charset", "", "")
+	cmd.Flags().StringVar(&siteNoteFlag, "note", "", "")
+	return cmd
+}
+
+func TestApplySiteFlags(t *testing.T) {
+	cmd := newSiteTestCommand()
+	defer addGenerationFlags(&cobra.Command{Use: "reset"})
+
+	s := &site.Site{Service: "github.com", Length: 20, Note: "старая"}
+	cmd.Flags().Set("user", "alice")
+	cmd.Flags().Set("counter", "2")
+	cmd.Flags().Set("type", "pin")
+	applySiteFlags(cmd, s)
+
+	if s.Username != "alice" || s.Counter != 2 || s.Type != "pin" {
+		t.Errorf("Флаги не перенесены: пользователь %q, счётчик %d, тип %q", s.Username, s.Counter, s.Type)
+	}
+	if s.Length != 20 || s.Note != "старая" {
+		t.Errorf("Незаданные флаги изменили настройки: длина %d, заметка %q", s.Length, s.Note)
+	}
+
+	cmd.Flags().Set("passphrase", "true")
+	applySiteFlags(cmd, s)
+	if !s.Passphrase || s.Type != "" {
+		t.Errorf("--passphrase должен заменить тип: фраза %v, тип %q", s.Passphrase, s.Type)
+	}
+}
+
+func TestResolveGeneratorOptionsWithSite(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	cfg.DefaultLength = 20
+
+	cmd := &cobra.Command{Use: "test"}
+	addGenerationFlags(cmd)
+	defer addGenerationFlags(&cobra.Command{Use: "reset"})
+
+	s := &site.Site{Service: "github.com", Length: 24, Counter: 3, Type: "pin"}
+	opts, err := resolveGeneratorOptions(cmd, s)
+	if err != nil {
+		t.Fatalf("resolveGeneratorOptions() ошибка: %v", err)
+	}
+	if opts.Length != 24 || opts.Counter != 3 || opts.Type != pgen.TypePIN {
+		t.Errorf("Настройки сервиса не применены: длина %d, счётчик %d, тип %q", opts.Length, opts.Counter, opts.Type)
+	}
+
+	cmd.Flags().Set("length", "30")
+	cmd.Flags().Set("counter", "1")
+	opts, err = resolveGeneratorOptions(cmd, s)
+	if err != nil {
+		t.Fatalf("resolveGeneratorOptions() ошибка: %v", err)
+	}
+	if opts.Length != 30 || opts.Counter != 1 {
+		t.Errorf("Флаги должны перекрывать сервис: длина %d, счётчик %d", opts.Length, opts.Counter)
+	}
+
+	if _, err := resolveGeneratorOptions(cmd, &site.Site{Service: "bad", Type: "unknown"}); err == nil {
+		t.Error("resolveGeneratorOptions() с неизвестным типом сервиса должна вернуть ошибку")
+	}
+}
+
+func TestSiteUsername(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	cfg.Username = "user"
+
+	if got := siteUsername(nil); got != "user" {
+		t.Errorf("siteUsername(nil) = %q, ожидается user", got)
+	}
+	if got := siteUsername(&site.Site{Service: "github.com"}); got != "user" {
+		t.Errorf("siteUsername() без имени = %q, ожидается user", got)
+	}
+	if got := siteUsername(&site.Site{Service: "github.com", Username: "alice"}); got != "alice" {
+		t.Errorf("siteUsername() = %q, ожидается alice", got)
+	}
+
+	// --user перекрывает и реестр, и конфигурацию
+	savedUser := userFlag
+	defer func() { userFlag = savedUser }()
+	userFlag = " bob "
+	if got := siteUsername(&site.Site{Service: "github.com", Username: "alice"}); got != "bob" {
+		t.Errorf("siteUsername() с --user = %q, ожидается bob", got)
+	}
+	if got := siteUsername(nil); got != "bob" {
+		t.Errorf("siteUsername(nil) с --user = %q, ожидается bob", got)
+	}
+}
+
+func TestApplySiteDefaults(t *testing.T) {
+	registry := site.New()
+	registry.Add(&site.Site{Service: "github.com", Length: 24, Counter: 2, Username: "alice"})
+
+	entries := []batch.Entry{
+		{Service: "github.com"},
+		{Service: "github.com", Length: 12, Counter: 5, Username: "bob"},
+		{Service: "gitlab.com"},
+	}
+	applySiteDefaults(entries, registry, true)
+
+	if e := entries[0]; e.Length != 24 || e.Counter != 2 || e.Username != "alice" {
+		t.Errorf("Настройки сервиса не применены: %+v", e)
+	}
+	if e := entries[1]; e.Length != 12 || e.Counter != 5 || e.Username != "bob" {
+		t.Errorf("Значения строки должны перекрывать сервис: %+v", e)
+	}
+	if e := entries[2]; e.Length != 0 || e.Counter != 0 || e.Username != "" {
+		t.Errorf("Сервис вне реестра изменён: %+v", e)
+	}
+
+	entries = []batch.Entry{{Service: "github.com"}}
+	applySiteDefaults(entries, registry, false)
+	if entries[0].Length != 0 || entries[0].Counter != 2 {
+		t.Errorf("С флагом --length длина сервиса не должна применяться: %+v", entries[0])
+	}
+}
+
+func TestGetSiteErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Уже есть", errors.New("site_exists"), fmt.Sprintf(messages.SiteExists, "github.com")},
+		{"Не найден", errors.New("site_not_found"), fmt.Sprintf(messages.SiteNotFound, "github.com")},
+		{"Пустое имя", errors.New("service_empty"), messages.Errors.EmptyService},
+		{"Повреждён", errors.New("registry_invalid"), messages.SiteInvalid},
+		{"Версия", errors.New("registry_version"), messages.SiteVersion},
+		{"Другая ошибка", errors.New("boom"), messages.SiteLoadError + " boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getSiteErrorText(tt.err, "github.com", messages); got != tt.expected {
+				t.Errorf("getSiteErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
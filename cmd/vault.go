@@ -0,0 +1,391 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/MaksymLeiber/pgen/internal/colors"
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/input"
+	"github.com/MaksymLeiber/pgen/internal/report"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/internal/vault"
+)
+
+// Открытое зашифрованное хранилище текущего запуска. Пока оно не открыто,
+// имя пользователя и реестр сервисов недоступны.
+var (
+	openedVault   *vault.Vault
+	vaultUsername string
+)
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "",
+	Long:  "",
+}
+
+var vaultInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runVaultInitCommand,
+}
+
+var vaultStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runVaultStatusCommand,
+}
+
+var vaultUserCmd = &cobra.Command{
+	Use:   "user <name>",
+	Short: "",
+	Args:  cobra.ExactArgs(1),
+	Run:   runVaultUserCommand,
+}
+
+var vaultDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "",
+	Args:  cobra.NoArgs,
+	Run:   runVaultDisableCommand,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{vaultInitCmd, vaultUserCmd, vaultDisableCmd} {
+		addMasterSourceFlags(cmd)
+	}
+
+	vaultCmd.AddCommand(vaultInitCmd)
+	vaultCmd.AddCommand(vaultStatusCmd)
+	vaultCmd.AddCommand(vaultUserCmd)
+	vaultCmd.AddCommand(vaultDisableCmd)
+}
+
+// updateVaultCommandTexts обновляет тексты команд хранилища
+func updateVaultCommandTexts(messages *i18n.Messages) {
+	vaultCmd.Short = messages.VaultShort
+	vaultCmd.Long = messages.VaultLong
+	vaultInitCmd.Short = messages.VaultInitShort
+	vaultStatusCmd.Short = messages.VaultStatusShort
+	vaultUserCmd.Short = messages.VaultUserShort
+	vaultDisableCmd.Short = messages.VaultDisableShort
+
+	for _, cmd := range []*cobra.Command{vaultInitCmd, vaultUserCmd, vaultDisableCmd} {
+		updateMasterSourceFlagTexts(cmd, messages)
+	}
+}
+
+// vaultPath возвращает путь к хранилищу рядом с config.json
+func vaultPath() (string, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), vault.FileName), nil
+}
+
+// vaultEnabled сообщает, создано ли зашифрованное хранилище
+func vaultEnabled() bool {
+	path, err := vaultPath()
+	return err == nil && vault.Exists(path)
+}
+
+// unlockVault открывает хранилище, если оно создано. Неверный мастер-пароль
+// завершает программу: с чужим именем пользователя пароли были бы другими.
+func unlockVault(masterPassword *security.SecureString, messages *i18n.Messages) {
+	if openedVault != nil || !vaultEnabled() {
+		return
+	}
+	path, _ := vaultPath()
+
+	master := masterPassword.Bytes()
+	v, err := vault.Open(path, master)
+	security.ZeroMemory(master)
+	if err != nil {
+		if err.Error() == "master_mismatch" {
+			exitWithCode(exitMaster, messages.VaultMasterMismatch)
+		}
+		exitWithCode(exitConfig, getVaultErrorText(err, messages))
+	}
+
+	username, err := v.Username()
+	if err != nil {
+		v.Close()
+		exitWithCode(exitConfig, getVaultErrorText(err, messages))
+	}
+	openedVault, vaultUsername = v, username
+}
+
+// unlockVaultFromFlags открывает хранилище мастер-паролем из источника или
+// терминала. Без хранилища мастер-пароль не запрашивается.
+func unlockVaultFromFlags(cmd *cobra.Command, messages *i18n.Messages) {
+	if !vaultEnabled() {
+		return
+	}
+	masterPassword := readMasterOrPrompt(cmd, messages)
+	defer masterPassword.Clear()
+	unlockVault(masterPassword, messages)
+}
+
+// closeVault стирает ключи хранилища и имя пользователя
+func closeVault() {
+	if openedVault != nil {
+		openedVault.Close()
+	}
+	openedVault, vaultUsername = nil, ""
+}
+
+// defaultUsername возвращает имя пользователя для сервисов без своего имени:
+// из открытого хранилища, а без него - из конфигурации
+func defaultUsername() string {
+	if openedVault != nil && vaultUsername != "" {
+		return vaultUsername
+	}
+	return cfg.Username
+}
+
+// displayUsername возвращает имя пользователя для заголовка: из флага --user
+// или имя по умолчанию. До открытия хранилища имя по умолчанию скрыто.
+func displayUsername(messages *i18n.Messages) string {
+	if openedVault == nil && vaultEnabled() && strings.TrimSpace(userFlag) == "" {
+		return messages.VaultHiddenUser
+	}
+	return siteUsername(nil)
+}
+
+func runVaultInitCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	path, err := vaultPath()
+	if err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.VaultSaveError, err))
+	}
+	if vault.Exists(path) {
+		exitWithError(messages.VaultExists)
+	}
+	registry, registryPath := loadSites(messages)
+
+	masterPassword := readConfirmedMaster(cmd, messages)
+	defer masterPassword.Clear()
+
+	master := masterPassword.Bytes()
+	v, err := vault.Create(master, vault.KDFParams{
+		Time:    cfg.ArgonTime,
+		Memory:  cfg.ArgonMemory,
+		Threads: cfg.ArgonThreads,
+	})
+	security.ZeroMemory(master)
+	if err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.VaultSaveError, err))
+	}
+	defer v.Close()
+
+	err = v.SetUsername(cfg.Username)
+	if err == nil {
+		err = v.SetSites(registry.Sites)
+	}
+	if err == nil {
+		err = v.Save(path)
+	}
+	if err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.VaultSaveError, err))
+	}
+
+	// Открытые копии удаляются только после записи хранилища. История
+	// сеанса pgen shell тоже состоит из имён сервисов.
+	historyPath := filepath.Join(filepath.Dir(path), shellHistoryFile)
+	for _, plaintext := range []string{registryPath, historyPath} {
+		if err := os.Remove(plaintext); err != nil && !errors.Is(err, os.ErrNotExist) {
+			exitWithError(fmt.Sprintf("%s %v", messages.SiteSaveError, err))
+		}
+	}
+	cfg.Username = config.DefaultConfig().Username
+	if err := cfg.Save(messages); err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.ConfigErrorSaving, err))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "vault_init", File: path}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(fmt.Sprintf(messages.VaultCreated, len(registry.Sites))))
+}
+
+// readConfirmedMaster читает мастер-пароль, от которого зависят сохраняемые
+// данные: хранилище или проверка мастер-пароля. Введённый в терминале пароль
+// запрашивается дважды: опечатка закрыла бы хранилище или испортила проверку.
+func readConfirmedMaster(cmd *cobra.Command, messages *i18n.Messages) *security.SecureString {
+	masterPassword, err := readMasterSource(cmd)
+	if err == nil {
+		if masterPassword.IsEmpty() {
+			exitWithCode(exitMaster, messages.Errors.EmptyMaster)
+		}
+		return masterPassword
+	}
+	if err.Error() != "master_source_missing" {
+		exitWithCode(exitMaster, getMasterSourceErrorText(err, messages))
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		exitWithCode(exitMaster, messages.AgentMasterMissing)
+	}
+
+	masterPassword = readMasterOrPrompt(cmd, messages)
+	fmt.Fprint(os.Stderr, colors.PromptMsg(messages.VaultConfirmMaster+" "))
+	confirmation, err := input.ReadPasswordWithStarsAndMessages(&input.InputMessages{
+		UserCanceled:  messages.Errors.UserCanceled,
+		InputCanceled: messages.Errors.InputCanceled,
+	})
+	if err != nil {
+		masterPassword.Clear()
+		exitWithCode(exitMaster, err.Error())
+	}
+	defer confirmation.Clear()
+	if !masterPassword.SecureCompare(confirmation) {
+		masterPassword.Clear()
+		exitWithCode(exitMaster, messages.VaultConfirmMismatch)
+	}
+	return masterPassword
+}
+
+func runVaultStatusCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	path, err := vaultPath()
+	if err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.VaultLoadError, err))
+	}
+	jsonOutput := isJSONOutput(messages)
+
+	if !vault.Exists(path) {
+		if jsonOutput {
+			writeReport(report.Vault{}, messages)
+			return
+		}
+		fmt.Println(colors.SubtleMsg(messages.VaultStatusDisabled))
+		return
+	}
+
+	info, err := vault.Inspect(path)
+	if err != nil {
+		exitWithCode(exitConfig, getVaultErrorText(err, messages))
+	}
+	if jsonOutput {
+		writeReport(report.Vault{
+			Enabled: true,
+			File:    path,
+			Sites:   info.Sites,
+			Argon: &report.Argon{
+				Time:      info.KDF.Time,
+				MemoryKiB: info.KDF.Memory,
+				Threads:   info.KDF.Threads,
+				KeyLen:    32,
+			},
+		}, messages)
+		return
+	}
+	fmt.Printf("%s %s\n", colors.InfoMsg(messages.VaultStatusEnabled), path)
+	fmt.Println(colors.SubtleMsg(fmt.Sprintf(messages.VaultStatusSites, info.Sites)))
+	fmt.Println(colors.SubtleMsg(fmt.Sprintf(messages.VaultStatusKDF, info.KDF.Time, info.KDF.Memory, info.KDF.Threads)))
+}
+
+func runVaultUserCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	username := strings.TrimSpace(args[0])
+	if username == "" {
+		exitWithCode(exitUsage, messages.ConfigUsernameEmpty)
+	}
+	if !vaultEnabled() {
+		exitWithError(messages.VaultNotEnabled)
+	}
+	unlockVaultFromFlags(cmd, messages)
+	defer closeVault()
+
+	// Имя из хранилища входит в соль так же, как username в конфигурации
+	old := cfg.Derivation()
+	old.Username = vaultUsername
+	configUsername := cfg.Username
+	cfg.Username = username
+	confirmed := guardDerivationChange(old, configForceFlag, messages)
+	cfg.Username = configUsername
+	if !confirmed {
+		return
+	}
+
+	path, _ := vaultPath()
+	err := openedVault.SetUsername(username)
+	if err == nil {
+		err = openedVault.Save(path)
+	}
+	if err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.VaultSaveError, err))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "vault_user", File: path}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(messages.VaultUserSet))
+}
+
+func runVaultDisableCommand(cmd *cobra.Command, args []string) {
+	messages := i18n.GetMessages(detectLanguageFromArgs(), Version)
+	if !vaultEnabled() {
+		exitWithError(messages.VaultNotEnabled)
+	}
+	unlockVaultFromFlags(cmd, messages)
+	defer closeVault()
+
+	sites, err := openedVault.Sites()
+	if err != nil {
+		exitWithCode(exitConfig, getVaultErrorText(err, messages))
+	}
+	registry := site.New()
+	registry.Sites = sites
+	registryPath, err := sitesPath()
+	if err == nil {
+		err = registry.Save(registryPath)
+	}
+	if err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.SiteSaveError, err))
+	}
+
+	cfg.Username = defaultUsername()
+	if err := cfg.Save(messages); err != nil {
+		exitWithCode(exitConfig, fmt.Sprintf("%s %v", messages.ConfigErrorSaving, err))
+	}
+	path, _ := vaultPath()
+	if err := os.Remove(path); err != nil {
+		exitWithError(fmt.Sprintf("%s %v", messages.VaultSaveError, err))
+	}
+
+	if isJSONOutput(messages) {
+		writeReport(report.ConfigChange{Action: "vault_disable", File: path}, messages)
+		return
+	}
+	fmt.Println(colors.SuccessMsg(messages.VaultDisabled))
+}
+
+// getVaultErrorText возвращает текст ошибки хранилища на соответствующем языке
+func getVaultErrorText(err error, messages *i18n.Messages) string {
+	switch err.Error() {
+	case "master_mismatch":
+		return messages.VaultMasterMismatch
+	case "vault_not_found":
+		return messages.VaultNotEnabled
+	case "vault_invalid":
+		return messages.VaultInvalid
+	case "vault_version":
+		return messages.VaultVersion
+	default:
+		return fmt.Sprintf("%s %v", messages.VaultLoadError, err)
+	}
+}
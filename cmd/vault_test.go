@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/config"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
+	"github.com/MaksymLeiber/pgen/internal/vault"
+)
+
+// createTestVault создаёт хранилище в каталоге конфигурации временного HOME
+func createTestVault(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", os.Getenv("HOME"))
+
+	path, err := vaultPath()
+	if err != nil {
+		t.Fatalf("vaultPath() ошибка: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("Ошибка создания каталога: %v", err)
+	}
+
+	v, err := vault.Create([]byte("testmaster"), vault.KDFParams{Time: 1, Memory: 8 * 1024, Threads: 1})
+	if err != nil {
+		t.Fatalf("vault.Create() ошибка: %v", err)
+	}
+	defer v.Close()
+	if err := v.SetUsername("alice"); err != nil {
+		t.Fatalf("SetUsername() ошибка: %v", err)
+	}
+	if err := v.SetSites([]*site.Site{{Service: "github.com", Length: 24}}); err != nil {
+		t.Fatalf("SetSites() ошибка: %v", err)
+	}
+	if err := v.Save(path); err != nil {
+		t.Fatalf("Save() ошибка: %v", err)
+	}
+}
+
+func TestUnlockVault(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	createTestVault(t)
+	if !vaultEnabled() {
+		t.Fatal("vaultEnabled() = false после создания хранилища")
+	}
+	if got := displayUsername(messages); got != messages.VaultHiddenUser {
+		t.Errorf("displayUsername() до открытия = %q, ожидается %q", got, messages.VaultHiddenUser)
+	}
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+	unlockVault(masterPassword, messages)
+	defer closeVault()
+
+	if got := defaultUsername(); got != "alice" {
+		t.Errorf("defaultUsername() = %q, ожидается alice", got)
+	}
+	if got := siteUsername(nil); got != "alice" {
+		t.Errorf("siteUsername(nil) = %q, ожидается имя из хранилища", got)
+	}
+	s := lookupSite("github.com", messages)
+	if s == nil || s.Length != 24 {
+		t.Errorf("lookupSite() = %+v, ожидается сервис из хранилища", s)
+	}
+	registry, _ := loadSites(messages)
+	if len(registry.Sites) != 1 {
+		t.Errorf("loadSites() вернул %d сервисов, ожидается 1", len(registry.Sites))
+	}
+
+	closeVault()
+	if got := defaultUsername(); got != cfg.Username {
+		t.Errorf("defaultUsername() после закрытия = %q, ожидается %q", got, cfg.Username)
+	}
+}
+
+func TestSetConfigUsernameWithVault(t *testing.T) {
+	savedCfg := cfg
+	defer func() { cfg = savedCfg }()
+	cfg = config.DefaultConfig()
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	createTestVault(t)
+	if err := setConfigValue("username", "bob", messages); err == nil || err.Error() != messages.VaultUsernameHint {
+		t.Errorf("setConfigValue(username) ошибка = %v, ожидается подсказка pgen vault user", err)
+	}
+	if cfg.Username == "bob" {
+		t.Error("Имя пользователя не должно попадать в открытую конфигурацию")
+	}
+}
+
+func TestGetVaultErrorText(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Неверный мастер-пароль", errors.New("master_mismatch"), messages.VaultMasterMismatch},
+		{"Нет хранилища", errors.New("vault_not_found"), messages.VaultNotEnabled},
+		{"Повреждён", errors.New("vault_invalid"), messages.VaultInvalid},
+		{"Версия", errors.New("vault_version"), messages.VaultVersion},
+		{"Другая ошибка", errors.New("boom"), messages.VaultLoadError + " boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getVaultErrorText(tt.err, messages); got != tt.expected {
+				t.Errorf("getVaultErrorText() = %q, ожидается %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDisplayUsernameUserFlag(t *testing.T) {
+	savedCfg, savedUser := cfg, userFlag
+	defer func() { cfg, userFlag = savedCfg, savedUser }()
+	cfg = config.DefaultConfig()
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	// Имя из --user известно до открытия хранилища и показывается в заголовке
+	createTestVault(t)
+	userFlag = "work"
+	if got := displayUsername(messages); got != "work" {
+		t.Errorf("displayUsername() с --user = %q, ожидается work", got)
+	}
+	userFlag = ""
+	if got := displayUsername(messages); got != messages.VaultHiddenUser {
+		t.Errorf("displayUsername() = %q, ожидается %q", got, messages.VaultHiddenUser)
+	}
+}
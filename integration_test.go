@@ -87,6 +87,13 @@ func TestMainIntegration(t *testing.T) {
 			expectedError:  true,
 			timeout:        5 * time.Second,
 		},
+		{
+			name:           "Нулевой счётчик",
+			args:           []string{"--counter", "0"},
+			expectedOutput: []string{},
+			expectedError:  true,
+			timeout:        5 * time.Second,
+		},
 	}
 
 	for _, tt := range tests {
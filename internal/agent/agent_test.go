@@ -0,0 +1,371 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// fastOptions параметры с быстрым Argon2 для тестов
+func fastOptions(algorithm pgen.Algorithm) pgen.Options {
+	opts := pgen.DefaultOptions()
+	opts.Algorithm = algorithm
+	opts.Argon = pgen.ArgonParams{Time: 1, Memory: 64 * 1024, Threads: 1, KeyLen: 32}
+	return opts
+}
+
+// socketPath возвращает путь сокета в приватном каталоге теста
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "pgen")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatalf("Ошибка создания каталога: %v", err)
+	}
+	return filepath.Join(dir, "agent.sock")
+}
+
+// startAgent запускает агент на временном сокете и останавливает его после теста
+func startAgent(t *testing.T, opts ServerOptions) (*Server, string) {
+	t.Helper()
+	if !peerCredentialsSupported {
+		t.Skip("Проверка собеседника не поддерживается на этой платформе")
+	}
+
+	path := socketPath(t)
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() ошибка: %v", err)
+	}
+
+	server := NewServer(opts)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		server.Stop()
+		if err := <-served; err != nil {
+			t.Errorf("Serve() ошибка: %v", err)
+		}
+	})
+	return server, path
+}
+
+// expected генерирует пароль без агента
+func expected(t *testing.T, opts pgen.Options, service string) string {
+	t.Helper()
+	gen, err := pgen.New(opts)
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+	password, err := gen.Generate(context.Background(), []byte("testmaster"), service, "testuser")
+	if err != nil {
+		t.Fatalf("Generate() ошибка: %v", err)
+	}
+	return string(password)
+}
+
+func getRequest(opts pgen.Options, service string) Request {
+	return Request{Op: OpGet, Service: service, Username: "testuser", Params: NewParams(opts)}
+}
+
+func TestAgentLifecycle(t *testing.T) {
+	_, path := startAgent(t, ServerOptions{})
+
+	response, err := Call(path, Request{Op: OpStatus})
+	if err != nil || !response.Status.Locked {
+		t.Fatalf("status: %+v, ошибка %v, ожидается заблокированный агент", response, err)
+	}
+
+	opts := fastOptions(pgen.AlgorithmV2)
+	if _, err := Call(path, getRequest(opts, "github.com")); err == nil || err.Error() != "agent_locked" {
+		t.Errorf("get до unlock ошибка = %v, ожидается agent_locked", err)
+	}
+	if _, err := Call(path, Request{Op: OpUnlock}); err == nil || err.Error() != "master_empty" {
+		t.Errorf("unlock без пароля ошибка = %v, ожидается master_empty", err)
+	}
+	if _, err := Call(path, Request{Op: OpUnlock, Master: []byte("testmaster")}); err != nil {
+		if err.Error() == "memory_lock_failed" {
+			t.Skip("mlock недоступен в окружении")
+		}
+		t.Fatalf("unlock ошибка: %v", err)
+	}
+
+	response, err = Call(path, getRequest(opts, "github.com"))
+	if err != nil {
+		t.Fatalf("get ошибка: %v", err)
+	}
+	if string(response.Password) != expected(t, opts, "github.com") {
+		t.Errorf("Пароль агента %q не совпадает с локальной генерацией", response.Password)
+	}
+
+	if _, err := Call(path, Request{Op: OpLock}); err != nil {
+		t.Fatalf("lock ошибка: %v", err)
+	}
+	if _, err := Call(path, getRequest(opts, "github.com")); err == nil || err.Error() != "agent_locked" {
+		t.Errorf("get после lock ошибка = %v, ожидается agent_locked", err)
+	}
+}
+
+func TestAgentMasterKeyCache(t *testing.T) {
+	server, path := startAgent(t, ServerOptions{})
+	if err := server.Unlock([]byte("testmaster")); err != nil {
+		t.Skipf("mlock недоступен в окружении: %v", err)
+	}
+
+	opts := fastOptions(pgen.AlgorithmV3)
+	for _, service := range []string{"github.com", "gitlab.com"} {
+		response, err := Call(path, getRequest(opts, service))
+		if err != nil {
+			t.Fatalf("get %s ошибка: %v", service, err)
+		}
+		if string(response.Password) != expected(t, opts, service) {
+			t.Errorf("Пароль %s из кэша ключей не совпадает с локальной генерацией", service)
+		}
+	}
+
+	response, err := Call(path, Request{Op: OpStatus})
+	if err != nil {
+		t.Fatalf("status ошибка: %v", err)
+	}
+	if response.Status.CachedKeys != 1 {
+		t.Errorf("CachedKeys = %d, ожидается 1 ключ на пользователя", response.Status.CachedKeys)
+	}
+
+	server.Lock()
+	if response, _ := Call(path, Request{Op: OpStatus}); response.Status.CachedKeys != 0 {
+		t.Errorf("После lock осталось ключей: %d", response.Status.CachedKeys)
+	}
+}
+
+func TestAgentTimeouts(t *testing.T) {
+	t.Run("Простой блокирует агент", func(t *testing.T) {
+		server, _ := startAgent(t, ServerOptions{IdleTimeout: 50 * time.Millisecond})
+		if err := server.Unlock([]byte("testmaster")); err != nil {
+			t.Skipf("mlock недоступен в окружении: %v", err)
+		}
+		deadline := time.Now().Add(2 * time.Second)
+		for !server.Locked() {
+			if time.Now().After(deadline) {
+				t.Fatal("Агент не заблокировался после простоя")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	t.Run("Время жизни останавливает агент", func(t *testing.T) {
+		server, path := startAgent(t, ServerOptions{MaxLifetime: 50 * time.Millisecond})
+		select {
+		case <-server.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("Агент не остановился по времени жизни")
+		}
+		if _, err := Call(path, Request{Op: OpStatus}); err == nil || err.Error() != "agent_not_running" {
+			t.Errorf("status после остановки ошибка = %v, ожидается agent_not_running", err)
+		}
+	})
+}
+
+func TestAgentStop(t *testing.T) {
+	server, path := startAgent(t, ServerOptions{})
+	if _, err := Call(path, Request{Op: OpStop}); err != nil {
+		t.Fatalf("stop ошибка: %v", err)
+	}
+	select {
+	case <-server.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Агент не остановился")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Сокет не удалён после остановки: %v", err)
+	}
+}
+
+func TestAgentProtocolErrors(t *testing.T) {
+	_, path := startAgent(t, ServerOptions{})
+
+	// Сырой клиент: версия протокола проставляется вручную
+	raw := func(request any) Response {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("Dial() ошибка: %v", err)
+		}
+		defer conn.Close()
+		json.NewEncoder(conn).Encode(request)
+		var response Response
+		if err := json.NewDecoder(conn).Decode(&response); err != nil {
+			t.Fatalf("Ошибка чтения ответа: %v", err)
+		}
+		return response
+	}
+
+	tests := []struct {
+		name    string
+		request any
+		code    string
+	}{
+		{"Другая версия", Request{Version: 99, Op: OpStatus}, "protocol_version"},
+		{"Неизвестная операция", Request{Version: ProtocolVersion, Op: "dump"}, "unknown_op"},
+		{"Не JSON", "status", "bad_request"},
+		{"get без параметров", Request{Version: ProtocolVersion, Op: OpGet, Service: "github.com"}, "bad_request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if response := raw(tt.request); response.OK || response.Error != tt.code {
+				t.Errorf("Ответ %+v, ожидается ошибка %s", response, tt.code)
+			}
+		})
+	}
+
+	opts := fastOptions(pgen.AlgorithmV2)
+	opts.Length = 2
+	if _, err := Call(path, getRequest(opts, "github.com")); err == nil || err.Error() != "length_too_short" {
+		t.Errorf("get с длиной 2 ошибка = %v, ожидается length_too_short", err)
+	}
+}
+
+func TestListen(t *testing.T) {
+	if !peerCredentialsSupported {
+		t.Skip("Проверка собеседника не поддерживается на этой платформе")
+	}
+
+	t.Run("Права сокета", func(t *testing.T) {
+		// Каталог сокета создаётся самим Listen
+		path := filepath.Join(t.TempDir(), "pgen", "agent.sock")
+		listener, err := Listen(path)
+		if err != nil {
+			t.Fatalf("Listen() ошибка: %v", err)
+		}
+		defer listener.Close()
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat() ошибка: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Права сокета %o, ожидается 600", info.Mode().Perm())
+		}
+		if _, err := Listen(path); err == nil || err.Error() != "agent_running" {
+			t.Errorf("Повторный Listen() ошибка = %v, ожидается agent_running", err)
+		}
+	})
+
+	t.Run("Оставшийся сокет", func(t *testing.T) {
+		path := socketPath(t)
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatalf("Ошибка записи файла: %v", err)
+		}
+		listener, err := Listen(path)
+		if err != nil {
+			t.Fatalf("Listen() поверх оставшегося сокета ошибка: %v", err)
+		}
+		listener.Close()
+	})
+
+	t.Run("Открытый каталог", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Права каталога на Windows не проверяются")
+		}
+		dir := t.TempDir()
+		if err := os.Chmod(dir, 0o755); err != nil {
+			t.Fatalf("Ошибка chmod: %v", err)
+		}
+		if _, err := Listen(filepath.Join(dir, "agent.sock")); err == nil || err.Error() != "socket_dir_insecure" {
+			t.Errorf("Listen() ошибка = %v, ожидается socket_dir_insecure", err)
+		}
+	})
+}
+
+func TestCallSocketChecks(t *testing.T) {
+	if !peerCredentialsSupported {
+		t.Skip("Проверка собеседника не поддерживается на этой платформе")
+	}
+	_, path := startAgent(t, ServerOptions{})
+	unlock := Request{Op: OpUnlock, Master: []byte("testmaster")}
+
+	t.Run("Каталог текущего пользователя", func(t *testing.T) {
+		if _, err := Call(path, Request{Op: OpStatus}); err != nil {
+			t.Errorf("Call() ошибка: %v", err)
+		}
+	})
+
+	t.Run("Отсутствующий каталог", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing", "agent.sock")
+		if _, err := Call(missing, unlock); err == nil || err.Error() != "agent_not_running" {
+			t.Errorf("Call() ошибка = %v, ожидается agent_not_running", err)
+		}
+	})
+
+	t.Run("Символическая ссылка на каталог", func(t *testing.T) {
+		link := filepath.Join(t.TempDir(), "pgen")
+		if err := os.Symlink(filepath.Dir(path), link); err != nil {
+			t.Skipf("Символические ссылки недоступны: %v", err)
+		}
+		if _, err := Call(filepath.Join(link, "agent.sock"), unlock); err == nil || err.Error() != "socket_dir_insecure" {
+			t.Errorf("Call() ошибка = %v, ожидается socket_dir_insecure", err)
+		}
+	})
+
+	t.Run("Открытый каталог", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.Chmod(dir, 0o755); err != nil {
+			t.Fatalf("Ошибка chmod: %v", err)
+		}
+		// Чужой сокет в открытом каталоге не должен получить мастер-пароль
+		listener, err := net.Listen("unix", filepath.Join(dir, "agent.sock"))
+		if err != nil {
+			t.Fatalf("Listen() ошибка: %v", err)
+		}
+		defer listener.Close()
+		accepted := make(chan struct{})
+		go func() {
+			if conn, err := listener.Accept(); err == nil {
+				conn.Close()
+				close(accepted)
+			}
+		}()
+
+		if _, err := Call(filepath.Join(dir, "agent.sock"), unlock); err == nil || err.Error() != "socket_dir_insecure" {
+			t.Errorf("Call() ошибка = %v, ожидается socket_dir_insecure", err)
+		}
+		select {
+		case <-accepted:
+			t.Error("Call() подключился к сокету в открытом каталоге")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func TestParamsRoundTrip(t *testing.T) {
+	opts := fastOptions(pgen.AlgorithmV3)
+	opts.RequiredClasses = []pgen.CharClass{pgen.ClassDigit}
+	opts.Counter = 4
+
+	passphrase := opts
+	passphrase.Mode = pgen.ModePassphrase
+	passphrase.Passphrase.Words = 7
+	passphrase.Passphrase.Digit = true
+
+	template := opts
+	template.Mode = pgen.ModeTemplate
+	template.Type = pgen.TypePIN
+
+	for _, original := range []pgen.Options{opts, passphrase, template} {
+		data, err := json.Marshal(NewParams(original))
+		if err != nil {
+			t.Fatalf("Marshal() ошибка: %v", err)
+		}
+		var params Params
+		if err := json.Unmarshal(data, &params); err != nil {
+			t.Fatalf("Unmarshal() ошибка: %v", err)
+		}
+		if expected(t, params.Options(), "github.com") != expected(t, original, "github.com") {
+			t.Errorf("Параметры режима %s изменились при передаче: %s", original.Mode, data)
+		}
+	}
+}
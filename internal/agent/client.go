@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// Call отправляет запрос агенту и возвращает ответ. Недоступный агент даёт
+// agent_not_running, отказ агента - ошибку с его кодом. Запрос с мастер-паролем
+// уходит только агенту текущего пользователя: каталог сокета проверяется как в
+// Listen, а UID собеседника - после подключения (agent_peer_foreign).
+func Call(socketPath string, request Request) (*Response, error) {
+	request.Version = ProtocolVersion
+
+	if err := checkSocketDir(filepath.Dir(socketPath)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.New("agent_not_running")
+		}
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return nil, errors.New("agent_not_running")
+	}
+	defer conn.Close()
+	uid, err := peerUID(conn)
+	if err != nil {
+		return nil, err
+	}
+	if uid != os.Getuid() {
+		return nil, errors.New("agent_peer_foreign")
+	}
+	conn.SetDeadline(time.Now().Add(connectionTimeout))
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	defer security.SecureWipe(data)
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	line, err := bufio.NewReader(io.LimitReader(conn, maxMessageSize)).ReadBytes('\n')
+	defer security.SecureWipe(line)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	var response Response
+	if err := json.Unmarshal(line, &response); err != nil {
+		return nil, errors.New("bad_response")
+	}
+	if !response.OK {
+		return &response, errors.New(response.Error)
+	}
+	return &response, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package agent
+
+import "os/exec"
+
+// Detach на этой платформе оставляет параметры запуска без изменений
+func Detach(cmd *exec.Cmd) {}
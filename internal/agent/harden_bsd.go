@@ -0,0 +1,8 @@
+//go:build unix && !linux
+
+package agent
+
+// disableTracing на этой платформе ограничивается запретом core файлов
+func disableTracing() error {
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package agent
+
+// Harden на этой платформе ничего не делает
+func Harden() error {
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package agent
+
+import "golang.org/x/sys/unix"
+
+// Harden запрещает дамп памяти процесса агента, чтобы секреты не попали в core файл
+func Harden() error {
+	if err := unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{Cur: 0, Max: 0}); err != nil {
+		return err
+	}
+	return disableTracing()
+}
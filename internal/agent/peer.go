@@ -0,0 +1,21 @@
+package agent
+
+import (
+	"errors"
+	"net"
+)
+
+// withFd вызывает f с дескриптором UNIX соединения
+func withFd(conn net.Conn, f func(fd int)) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return errors.New("peer_denied")
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	return raw.Control(func(fd uintptr) {
+		f(int(fd))
+	})
+}
@@ -0,0 +1,27 @@
+//go:build darwin
+
+package agent
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// peerCredentialsSupported сообщает, умеет ли платформа проверять собеседника
+const peerCredentialsSupported = true
+
+// peerUID возвращает UID процесса на другой стороне соединения (LOCAL_PEERCRED)
+func peerUID(conn net.Conn) (int, error) {
+	var cred *unix.Xucred
+	var credErr error
+	if err := withFd(conn, func(fd int) {
+		cred, credErr = unix.GetsockoptXucred(fd, unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	}); err != nil {
+		return -1, err
+	}
+	if credErr != nil {
+		return -1, credErr
+	}
+	return int(cred.Uid), nil
+}
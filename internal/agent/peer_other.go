@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package agent
+
+import (
+	"errors"
+	"net"
+)
+
+// peerCredentialsSupported сообщает, умеет ли платформа проверять собеседника
+const peerCredentialsSupported = false
+
+// peerUID на этой платформе не реализован, поэтому агент отклоняет все соединения
+func peerUID(conn net.Conn) (int, error) {
+	return -1, errors.New("peer_credentials_unsupported")
+}
@@ -0,0 +1,170 @@
+// Package agent реализует агент pgen: фоновый процесс, который хранит мастер-пароль
+// и мастер-ключи v3 в закреплённой памяти и генерирует пароли по запросам через
+// UNIX сокет, чтобы не вводить мастер-пароль и не ждать Argon2 при каждом вызове.
+//
+// Протокол. Соединение обслуживает один запрос: клиент пишет JSON объект Request
+// и перевод строки, агент отвечает одним JSON объектом Response с переводом строки
+// и закрывает соединение. Поля []byte передаются в base64, как принято в
+// encoding/json.
+//
+//	→ {"version":1,"op":"get","service":"github.com","username":"user","params":{...}}
+//	← {"ok":true,"password":"WVlhQWxjRllGT2RYa2okVg=="}
+//	← {"ok":false,"error":"agent_locked"}
+//
+// Операции:
+//
+//	status  состояние агента (Response.Status)
+//	get     пароль сервиса: service, username и параметры генерации params
+//	unlock  принять мастер-пароль master и разблокировать агент
+//	lock    стереть мастер-пароль и мастер-ключи, агент продолжает работать
+//	stop    стереть секреты и завершить агент
+//
+// Коды ошибок: protocol_version, bad_request, unknown_op, peer_denied,
+// agent_locked, master_empty, memory_lock_failed, а для get также коды pgen.ErrorCode.
+//
+// Сокет создаётся с правами 0600 в каталоге с правами 0700, агент дополнительно
+// сверяет UID собеседника (SO_PEERCRED, LOCAL_PEERCRED) со своим. Клиент
+// делает то же с другой стороны: до отправки запроса проверяет каталог сокета
+// и UID агента, чтобы мастер-пароль не ушёл сокету другого пользователя.
+package agent
+
+import (
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// ProtocolVersion версия протокола. Агент отклоняет запросы другой версии.
+const ProtocolVersion = 1
+
+// maxMessageSize ограничивает размер запроса и ответа
+const maxMessageSize = 64 * 1024
+
+// Операции протокола
+const (
+	OpStatus = "status"
+	OpGet    = "get"
+	OpUnlock = "unlock"
+	OpLock   = "lock"
+	OpStop   = "stop"
+)
+
+// Request запрос к агенту
+type Request struct {
+	Version  int     `json:"version"`
+	Op       string  `json:"op"`
+	Service  string  `json:"service,omitempty"`
+	Username string  `json:"username,omitempty"`
+	Params   *Params `json:"params,omitempty"`
+	Master   []byte  `json:"master,omitempty"`
+}
+
+// Response ответ агента
+type Response struct {
+	OK       bool    `json:"ok"`
+	Error    string  `json:"error,omitempty"`
+	Password []byte  `json:"password,omitempty"`
+	Status   *Status `json:"status,omitempty"`
+}
+
+// Status состояние агента
+type Status struct {
+	Locked             bool  `json:"locked"`
+	PID                int   `json:"pid"`
+	IdleTimeoutSeconds int64 `json:"idle_timeout_seconds"`
+	ExpiresInSeconds   int64 `json:"expires_in_seconds"`
+	CachedKeys         int   `json:"cached_keys"`
+}
+
+// Params параметры генерации в запросе get. Клиент передаёт уже разрешённые
+// настройки, поэтому агент выдаёт тот же пароль, что и локальная генерация.
+type Params struct {
+	Length          int               `json:"length"`
+	Charset         string            `json:"charset"`
+	RequiredClasses []string          `json:"required_classes,omitempty"`
+	Counter         uint32            `json:"counter"`
+	Algorithm       string            `json:"algorithm"`
+	Argon           ArgonParams       `json:"argon"`
+	Mode            string            `json:"mode"`
+	Passphrase      *PassphraseParams `json:"passphrase,omitempty"`
+	Type            string            `json:"type,omitempty"`
+}
+
+// ArgonParams параметры Argon2id
+type ArgonParams struct {
+	Time      uint32 `json:"time"`
+	MemoryKiB uint32 `json:"memory_kib"`
+	Threads   uint8  `json:"threads"`
+	KeyLen    uint32 `json:"key_len"`
+}
+
+// PassphraseParams параметры парольной фразы
+type PassphraseParams struct {
+	Words      int    `json:"words"`
+	Separator  string `json:"separator"`
+	Wordlist   string `json:"wordlist"`
+	Capitalize bool   `json:"capitalize"`
+	Digit      bool   `json:"digit"`
+}
+
+// NewParams переводит параметры генератора в параметры запроса
+func NewParams(opts pgen.Options) *Params {
+	params := &Params{
+		Length:    opts.Length,
+		Charset:   opts.Charset,
+		Counter:   opts.Counter,
+		Algorithm: string(opts.Algorithm),
+		Argon: ArgonParams{
+			Time:      opts.Argon.Time,
+			MemoryKiB: opts.Argon.Memory,
+			Threads:   opts.Argon.Threads,
+			KeyLen:    opts.Argon.KeyLen,
+		},
+		Mode: string(opts.Mode),
+	}
+	for _, class := range opts.RequiredClasses {
+		params.RequiredClasses = append(params.RequiredClasses, string(class))
+	}
+	switch opts.Mode {
+	case pgen.ModePassphrase:
+		params.Passphrase = &PassphraseParams{
+			Words:      opts.Passphrase.Words,
+			Separator:  opts.Passphrase.Separator,
+			Wordlist:   opts.Passphrase.Wordlist,
+			Capitalize: opts.Passphrase.Capitalize,
+			Digit:      opts.Passphrase.Digit,
+		}
+	case pgen.ModeTemplate:
+		params.Type = string(opts.Type)
+	}
+	return params
+}
+
+// Options переводит параметры запроса в параметры генератора
+func (p *Params) Options() pgen.Options {
+	opts := pgen.Options{
+		Length:    p.Length,
+		Charset:   p.Charset,
+		Counter:   p.Counter,
+		Algorithm: pgen.Algorithm(p.Algorithm),
+		Argon: pgen.ArgonParams{
+			Time:    p.Argon.Time,
+			Memory:  p.Argon.MemoryKiB,
+			Threads: p.Argon.Threads,
+			KeyLen:  p.Argon.KeyLen,
+		},
+		Mode: pgen.Mode(p.Mode),
+		Type: pgen.PasswordType(p.Type),
+	}
+	for _, class := range p.RequiredClasses {
+		opts.RequiredClasses = append(opts.RequiredClasses, pgen.CharClass(class))
+	}
+	if p.Passphrase != nil {
+		opts.Passphrase = pgen.PassphraseOptions{
+			Words:      p.Passphrase.Words,
+			Separator:  p.Passphrase.Separator,
+			Wordlist:   p.Passphrase.Wordlist,
+			Capitalize: p.Passphrase.Capitalize,
+			Digit:      p.Passphrase.Digit,
+		}
+	}
+	return opts
+}
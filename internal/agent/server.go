@@ -0,0 +1,325 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/pkg/pgen"
+)
+
+// connectionTimeout ограничивает обслуживание одного соединения с запасом на Argon2
+const connectionTimeout = 2 * time.Minute
+
+// ServerOptions параметры агента. Нулевые значения отключают ограничение.
+type ServerOptions struct {
+	IdleTimeout time.Duration // блокировка после простоя без запросов get
+	MaxLifetime time.Duration // завершение агента после запуска
+}
+
+// Server агент, обслуживающий запросы на UNIX сокете
+type Server struct {
+	opts    ServerOptions
+	uid     int
+	started time.Time
+
+	mu        sync.Mutex
+	master    *security.SecureString
+	keys      map[string]*pgen.MasterKey
+	epoch     uint64 // меняется при каждой блокировке, чтобы не сохранить устаревший ключ
+	idleTimer *time.Timer
+	lifeTimer *time.Timer
+	listener  net.Listener
+
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+// NewServer создаёт заблокированный агент
+func NewServer(opts ServerOptions) *Server {
+	return &Server{
+		opts:    opts,
+		uid:     os.Getuid(),
+		started: time.Now(),
+		keys:    make(map[string]*pgen.MasterKey),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Unlock копирует мастер-пароль в закреплённую память и разблокирует агент
+func (s *Server) Unlock(master []byte) error {
+	if len(master) == 0 {
+		return errors.New("master_empty")
+	}
+	locked, err := security.NewLockedSecureString(master)
+	if err != nil {
+		return errors.New("memory_lock_failed")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clearLocked()
+	s.master = locked
+	s.touchLocked()
+	return nil
+}
+
+// Lock стирает мастер-пароль и мастер-ключи
+func (s *Server) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clearLocked()
+}
+
+// Locked сообщает, заблокирован ли агент
+func (s *Server) Locked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.master == nil
+}
+
+// Serve принимает соединения, пока агент не остановлен. Возвращает nil после Stop.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	s.listener = listener
+	if s.opts.MaxLifetime > 0 {
+		s.lifeTimer = time.AfterFunc(s.opts.MaxLifetime, s.Stop)
+	}
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.stopped:
+				return nil
+			default:
+				return err
+			}
+		}
+		go s.handle(conn)
+	}
+}
+
+// Stop стирает секреты, закрывает сокет и завершает Serve
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		s.mu.Lock()
+		s.clearLocked()
+		if s.lifeTimer != nil {
+			s.lifeTimer.Stop()
+		}
+		listener := s.listener
+		s.mu.Unlock()
+
+		close(s.stopped)
+		if listener != nil {
+			listener.Close()
+		}
+	})
+}
+
+// Done закрывается после остановки агента
+func (s *Server) Done() <-chan struct{} {
+	return s.stopped
+}
+
+// handle обслуживает одно соединение: один запрос и один ответ
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(connectionTimeout))
+
+	response := s.respond(conn)
+	defer security.SecureWipe(response.Password)
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	defer security.SecureWipe(data)
+	conn.Write(append(data, '\n'))
+}
+
+// respond читает запрос и выполняет его
+func (s *Server) respond(conn net.Conn) Response {
+	if uid, err := peerUID(conn); err != nil || uid != s.uid {
+		return failure("peer_denied")
+	}
+
+	var request Request
+	line, err := bufio.NewReader(io.LimitReader(conn, maxMessageSize)).ReadBytes('\n')
+	defer security.SecureWipe(line)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return failure("bad_request")
+	}
+	if err := json.Unmarshal(line, &request); err != nil {
+		return failure("bad_request")
+	}
+	defer security.SecureWipe(request.Master)
+
+	if request.Version != ProtocolVersion {
+		return failure("protocol_version")
+	}
+
+	switch request.Op {
+	case OpStatus:
+		return Response{OK: true, Status: s.status()}
+	case OpGet:
+		return s.get(request)
+	case OpUnlock:
+		if err := s.Unlock(request.Master); err != nil {
+			return failure(err.Error())
+		}
+		return Response{OK: true}
+	case OpLock:
+		s.Lock()
+		return Response{OK: true}
+	case OpStop:
+		// Ответ уходит до закрытия сокета
+		go s.Stop()
+		return Response{OK: true}
+	default:
+		return failure("unknown_op")
+	}
+}
+
+// get генерирует пароль сервиса. Argon2 выполняется без удержания блокировки,
+// чтобы status и lock отвечали сразу.
+func (s *Server) get(request Request) Response {
+	if request.Params == nil || request.Service == "" {
+		return failure("bad_request")
+	}
+	gen, err := pgen.New(request.Params.Options())
+	if err != nil {
+		return failure(pgen.ErrorCode(err))
+	}
+
+	s.mu.Lock()
+	if s.master == nil {
+		s.mu.Unlock()
+		return failure("agent_locked")
+	}
+	master := s.master.Bytes()
+	epoch := s.epoch
+	s.touchLocked()
+	s.mu.Unlock()
+	defer security.SecureWipe(master)
+
+	ctx := context.Background()
+	var password []byte
+	if gen.Options().Algorithm == pgen.AlgorithmV3 {
+		password, err = s.generateWithMasterKey(ctx, gen, master, request, epoch)
+	} else {
+		password, err = gen.Generate(ctx, master, request.Service, request.Username)
+	}
+	if err != nil {
+		return failure(pgen.ErrorCode(err))
+	}
+	return Response{OK: true, Password: password}
+}
+
+// generateWithMasterKey генерирует пароль v3 из мастер-ключа пользователя. Ключ
+// вычисляется один раз и хранится, пока агент не заблокируют.
+func (s *Server) generateWithMasterKey(ctx context.Context, gen *pgen.Generator, master []byte, request Request, epoch uint64) ([]byte, error) {
+	argon := gen.Options().Argon
+	cacheKey := fmt.Sprintf("%s|%d|%d|%d|%d", request.Username, argon.Time, argon.Memory, argon.Threads, argon.KeyLen)
+
+	s.mu.Lock()
+	_, cached := s.keys[cacheKey]
+	s.mu.Unlock()
+
+	if !cached {
+		derived, err := gen.DeriveMasterKey(ctx, master, request.Username)
+		if err != nil {
+			return nil, err
+		}
+		if err := derived.LockMemory(); err != nil {
+			derived.Clear()
+			return nil, errors.New("memory_lock_failed")
+		}
+
+		s.mu.Lock()
+		if _, ok := s.keys[cacheKey]; ok || s.epoch != epoch {
+			// Параллельный запрос успел раньше или агент заблокировали
+			derived.Clear()
+		} else {
+			s.keys[cacheKey] = derived
+		}
+		s.mu.Unlock()
+	}
+
+	// HMAC быстрый, поэтому выполняется под блокировкой: lock не сотрёт ключ
+	// во время генерации
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.epoch != epoch {
+		return nil, errors.New("agent_locked")
+	}
+	return gen.GenerateWithMasterKey(ctx, s.keys[cacheKey], request.Service)
+}
+
+// status возвращает состояние агента
+func (s *Server) status() *Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &Status{
+		Locked:             s.master == nil,
+		PID:                os.Getpid(),
+		IdleTimeoutSeconds: int64(s.opts.IdleTimeout / time.Second),
+		CachedKeys:         len(s.keys),
+	}
+	if s.opts.MaxLifetime > 0 {
+		status.ExpiresInSeconds = int64(time.Until(s.started.Add(s.opts.MaxLifetime)) / time.Second)
+	}
+	return status
+}
+
+// touchLocked перезапускает таймер простоя. Вызывается под s.mu.
+func (s *Server) touchLocked() {
+	if s.opts.IdleTimeout <= 0 {
+		return
+	}
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+	}
+	epoch := s.epoch
+	s.idleTimer = time.AfterFunc(s.opts.IdleTimeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// Таймер от прошлой разблокировки не трогает новую
+		if s.epoch == epoch {
+			s.clearLocked()
+		}
+	})
+}
+
+// clearLocked стирает секреты. Вызывается под s.mu.
+func (s *Server) clearLocked() {
+	s.epoch++
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+		s.idleTimer = nil
+	}
+	if s.master != nil {
+		s.master.Clear()
+		s.master = nil
+	}
+	for cacheKey, masterKey := range s.keys {
+		masterKey.Clear()
+		delete(s.keys, cacheKey)
+	}
+}
+
+// failure возвращает ответ с кодом ошибки
+func failure(code string) Response {
+	return Response{OK: false, Error: code}
+}
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SocketEnv переменная окружения с путём к сокету агента
+const SocketEnv = "PGEN_AGENT_SOCK"
+
+// dialTimeout ограничивает подключение к агенту
+const dialTimeout = 2 * time.Second
+
+// DefaultSocketPath возвращает путь к сокету агента текущего пользователя:
+// PGEN_AGENT_SOCK, затем $XDG_RUNTIME_DIR/pgen/agent.sock, затем временный каталог
+func DefaultSocketPath() string {
+	if path := os.Getenv(SocketEnv); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "pgen", "agent.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("pgen-%d", os.Getuid()), "agent.sock")
+}
+
+// Listen создаёт сокет агента с правами 0600. Каталог сокета создаётся с
+// правами 0700 и должен принадлежать текущему пользователю. Оставшийся от
+// завершившегося агента сокет удаляется, работающий агент даёт agent_running.
+func Listen(path string) (net.Listener, error) {
+	if !peerCredentialsSupported {
+		return nil, errors.New("peer_credentials_unsupported")
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := checkSocketDir(dir); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Lstat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, dialTimeout); err == nil {
+			conn.Close()
+			return nil, errors.New("agent_running")
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// checkSocketDir проверяет, что каталог сокета не символическая ссылка, имеет
+// права 0700 и принадлежит текущему пользователю: иначе сокет мог подложить
+// другой пользователь.
+func checkSocketDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || info.Mode().Perm()&0o077 != 0 || !ownedByCurrentUser(info) {
+		return errors.New("socket_dir_insecure")
+	}
+	return nil
+}
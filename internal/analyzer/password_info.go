@@ -18,6 +18,43 @@ type PasswordInfo struct {
 	TimeToCrack CrackTime
 	Composition CharComposition
 	Strength    string
+	Level       StrengthLevel
+
+	// Для парольных фраз: количество слов и размер словаря
+	Words        int
+	WordlistSize int
+}
+
+// StrengthLevel уровень силы пароля
+type StrengthLevel int
+
+const (
+	StrengthVeryWeak StrengthLevel = iota
+	StrengthWeak
+	StrengthFair
+	StrengthGood
+	StrengthStrong
+	StrengthVeryStrong
+)
+
+// Code возвращает стабильный код уровня силы
+func (l StrengthLevel) Code() string {
+	switch l {
+	case StrengthVeryWeak:
+		return "very_weak"
+	case StrengthWeak:
+		return "weak"
+	case StrengthFair:
+		return "fair"
+	case StrengthGood:
+		return "good"
+	case StrengthStrong:
+		return "strong"
+	case StrengthVeryStrong:
+		return "very_strong"
+	default:
+		return "unknown"
+	}
 }
 
 // CharComposition состав символов в пароле
@@ -53,7 +90,66 @@ func AnalyzePassword(password string, messages *i18n.Messages) *PasswordInfo {
 	info.TimeToCrack = estimateCrackTime(info.Entropy, messages)
 
 	// Определяем общую силу
-	info.Strength = determineStrength(info.Entropy, info.Composition, messages)
+	info.Level = strengthLevel(info.Entropy, info.Composition)
+	info.Strength = strengthText(info.Level, messages)
+
+	return info
+}
+
+// AnalyzePasswordWithAlphabet анализирует пароль, сгенерированный из известного алфавита.
+// Энтропия считается по реальному размеру алфавита, а не по найденным в пароле классам.
+func AnalyzePasswordWithAlphabet(password, alphabet string, messages *i18n.Messages) *PasswordInfo {
+	info := &PasswordInfo{
+		Length:      len(password),
+		Composition: analyzeComposition(password),
+	}
+
+	info.Charset, _ = detectCharset(alphabet)
+	info.CharsetSize = len(alphabet)
+	info.Entropy = calculateEntropy(info.Length, info.CharsetSize)
+	info.TimeToCrack = estimateCrackTime(info.Entropy, messages)
+	info.Level = strengthLevel(info.Entropy, info.Composition)
+	info.Strength = strengthText(info.Level, messages)
+
+	return info
+}
+
+// AnalyzePasswordWithEntropy анализирует пароль с заранее известной энтропией,
+// например шаблонный, где классы символов заданы позициями шаблона
+func AnalyzePasswordWithEntropy(password string, entropy float64, messages *i18n.Messages) *PasswordInfo {
+	info := &PasswordInfo{
+		Length:      len(password),
+		Composition: analyzeComposition(password),
+		Entropy:     entropy,
+	}
+
+	info.Charset, info.CharsetSize = detectCharset(password)
+	info.TimeToCrack = estimateCrackTime(info.Entropy, messages)
+	info.Level = levelFromEntropy(info.Entropy)
+	info.Strength = strengthText(info.Level, messages)
+
+	return info
+}
+
+// AnalyzePassphrase анализирует парольную фразу. Энтропия считается как
+// слова × log₂(размер словаря), плюс выбор позиции и значения вставленной цифры.
+func AnalyzePassphrase(passphrase string, words, wordlistSize int, withDigit bool, messages *i18n.Messages) *PasswordInfo {
+	info := &PasswordInfo{
+		Length:       len([]rune(passphrase)),
+		Composition:  analyzeComposition(passphrase),
+		Words:        words,
+		WordlistSize: wordlistSize,
+	}
+
+	info.Charset, info.CharsetSize = detectCharset(passphrase)
+	info.Entropy = calculateEntropy(words, wordlistSize)
+	if withDigit && info.Entropy > 0 {
+		info.Entropy += math.Log2(float64(10 * words))
+	}
+	info.TimeToCrack = estimateCrackTime(info.Entropy, messages)
+	// Разнообразие символов не влияет на силу фразы, важен только размер словаря
+	info.Level = levelFromEntropy(info.Entropy)
+	info.Strength = strengthText(info.Level, messages)
 
 	return info
 }
@@ -191,22 +287,26 @@ func formatTime(seconds float64, messages *i18n.Messages) string {
 	return messages.TimeMoreThanTrillion
 }
 
-// determineStrength определяет общую силу пароля
-func determineStrength(entropy float64, comp CharComposition, messages *i18n.Messages) string {
-	// Базовая оценка по энтропии
-	var strengthByEntropy string
+// levelFromEntropy оценивает силу только по энтропии
+func levelFromEntropy(entropy float64) StrengthLevel {
 	switch {
 	case entropy < 30:
-		strengthByEntropy = messages.StrengthVeryWeak
+		return StrengthVeryWeak
 	case entropy < 50:
-		strengthByEntropy = messages.StrengthWeak
+		return StrengthWeak
 	case entropy < 70:
-		strengthByEntropy = messages.StrengthFair
+		return StrengthFair
 	case entropy < 90:
-		strengthByEntropy = messages.StrengthGood
+		return StrengthGood
 	default:
-		strengthByEntropy = messages.StrengthVeryStrong
+		return StrengthVeryStrong
 	}
+}
+
+// strengthLevel определяет общую силу пароля
+func strengthLevel(entropy float64, comp CharComposition) StrengthLevel {
+	// Базовая оценка по энтропии
+	levelByEntropy := levelFromEntropy(entropy)
 
 	// Корректировка на основе разнообразия символов
 	diversity := 0
@@ -224,30 +324,51 @@ func determineStrength(entropy float64, comp CharComposition, messages *i18n.Mes
 	}
 
 	if diversity >= 3 {
-		return strengthByEntropy
+		return levelByEntropy
 	} else if diversity == 2 {
 		// Понижаем на один уровень
-		switch strengthByEntropy {
-		case messages.StrengthVeryStrong:
-			return messages.StrengthStrong
-		case messages.StrengthStrong:
-			return messages.StrengthFair
-		case messages.StrengthFair:
-			return messages.StrengthWeak
+		switch levelByEntropy {
+		case StrengthVeryStrong:
+			return StrengthStrong
+		case StrengthStrong:
+			return StrengthFair
+		case StrengthFair:
+			return StrengthWeak
 		default:
-			return strengthByEntropy
+			return levelByEntropy
 		}
 	} else {
 		// Понижаем на два уровня
-		switch strengthByEntropy {
-		case messages.StrengthVeryStrong:
-			return messages.StrengthFair
-		case messages.StrengthStrong:
-			return messages.StrengthWeak
-		case messages.StrengthFair:
-			return messages.StrengthVeryWeak
+		switch levelByEntropy {
+		case StrengthVeryStrong:
+			return StrengthFair
+		case StrengthStrong:
+			return StrengthWeak
 		default:
-			return messages.StrengthVeryWeak
+			return StrengthVeryWeak
 		}
 	}
 }
+
+// determineStrength определяет общую силу пароля на языке сообщений
+func determineStrength(entropy float64, comp CharComposition, messages *i18n.Messages) string {
+	return strengthText(strengthLevel(entropy, comp), messages)
+}
+
+// strengthText возвращает название уровня силы на языке сообщений
+func strengthText(level StrengthLevel, messages *i18n.Messages) string {
+	switch level {
+	case StrengthVeryWeak:
+		return messages.StrengthVeryWeak
+	case StrengthWeak:
+		return messages.StrengthWeak
+	case StrengthFair:
+		return messages.StrengthFair
+	case StrengthGood:
+		return messages.StrengthGood
+	case StrengthStrong:
+		return messages.StrengthStrong
+	default:
+		return messages.StrengthVeryStrong
+	}
+}
@@ -456,6 +456,117 @@ func TestPasswordInfoFields(t *testing.T) {
 	}
 }
 
+func TestAnalyzePasswordWithAlphabet(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name            string
+		password        string
+		alphabet        string
+		expectedSize    int
+		expectedEntropy float64
+	}{
+		{
+			name:            "Только цифры",
+			password:        "12345678",
+			alphabet:        "0123456789",
+			expectedSize:    10,
+			expectedEntropy: 8 * math.Log2(10),
+		},
+		{
+			name:            "Пароль без символов из полного алфавита",
+			password:        "abcdefgh",
+			alphabet:        "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!@#$%^&*()_+-=[]{}|;:,.<>?",
+			expectedSize:    88,
+			expectedEntropy: 8 * math.Log2(88),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := AnalyzePasswordWithAlphabet(tt.password, tt.alphabet, messages)
+
+			if info.CharsetSize != tt.expectedSize {
+				t.Errorf("Размер набора символов = %d, ожидается %d", info.CharsetSize, tt.expectedSize)
+			}
+			if math.Abs(info.Entropy-tt.expectedEntropy) > 0.001 {
+				t.Errorf("Энтропия = %v, ожидается %v", info.Entropy, tt.expectedEntropy)
+			}
+			if info.Charset == "" {
+				t.Error("Набор символов не должен быть пустым")
+			}
+		})
+	}
+}
+
+func TestAnalyzePasswordWithEntropy(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	// PIN-код: энтропия задаётся шаблоном, а не составом символов
+	info := AnalyzePasswordWithEntropy("4821", 4*math.Log2(10), messages)
+
+	if math.Abs(info.Entropy-4*math.Log2(10)) > 0.001 {
+		t.Errorf("Энтропия = %v, ожидается %v", info.Entropy, 4*math.Log2(10))
+	}
+	if info.Length != 4 || info.Composition.Numbers != 4 {
+		t.Errorf("Длина = %d, цифр = %d, ожидается 4 и 4", info.Length, info.Composition.Numbers)
+	}
+	if info.Strength != messages.StrengthVeryWeak {
+		t.Errorf("Сила = %q, ожидается %q", info.Strength, messages.StrengthVeryWeak)
+	}
+}
+
+func TestAnalyzePassphrase(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	tests := []struct {
+		name            string
+		passphrase      string
+		words           int
+		wordlistSize    int
+		withDigit       bool
+		expectedEntropy float64
+	}{
+		{
+			name:            "Шесть слов EFF",
+			passphrase:      "abacus-zoom-tiger-lunar-paddle-frost",
+			words:           6,
+			wordlistSize:    7776,
+			expectedEntropy: 6 * math.Log2(7776),
+		},
+		{
+			name:            "Русский словарь с цифрой",
+			passphrase:      "кот-лес-мост7-рука",
+			words:           4,
+			wordlistSize:    1024,
+			withDigit:       true,
+			expectedEntropy: 4*10 + math.Log2(40),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := AnalyzePassphrase(tt.passphrase, tt.words, tt.wordlistSize, tt.withDigit, messages)
+
+			if math.Abs(info.Entropy-tt.expectedEntropy) > 0.001 {
+				t.Errorf("Энтропия = %v, ожидается %v", info.Entropy, tt.expectedEntropy)
+			}
+			if info.Words != tt.words || info.WordlistSize != tt.wordlistSize {
+				t.Errorf("Слов = %d, словарь = %d, ожидается %d и %d", info.Words, info.WordlistSize, tt.words, tt.wordlistSize)
+			}
+			if info.Length != len([]rune(tt.passphrase)) {
+				t.Errorf("Длина = %d, ожидается %d", info.Length, len([]rune(tt.passphrase)))
+			}
+		})
+	}
+
+	// Сила фразы определяется энтропией, а не разнообразием символов
+	info := AnalyzePassphrase("abacus-zoom-tiger-lunar-paddle-frost", 6, 7776, false, messages)
+	if info.Strength != messages.StrengthGood {
+		t.Errorf("Сила = %q, ожидается %q", info.Strength, messages.StrengthGood)
+	}
+}
+
 func TestRealWorldPasswords(t *testing.T) {
 	messages := i18n.GetMessages(i18n.English, "test")
 
@@ -576,3 +687,24 @@ func BenchmarkFormatTime(b *testing.B) {
 		formatTime(seconds, messages)
 	}
 }
+
+func TestStrengthLevel(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	alphabet := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!@#$%^&*()_+-=[]{}|;:,.<>?"
+	info := AnalyzePasswordWithAlphabet("Y|o3ku[p<_WSL+v1", alphabet, messages)
+	if info.Level != StrengthVeryStrong || info.Level.Code() != "very_strong" {
+		t.Errorf("Level.Code() = %q, ожидается very_strong", info.Level.Code())
+	}
+	if info.Strength != strengthText(info.Level, messages) {
+		t.Errorf("Strength = %q не соответствует уровню %q", info.Strength, info.Level.Code())
+	}
+
+	// Низкое разнообразие понижает уровень на два шага
+	if level := strengthLevel(100, CharComposition{Lowercase: 20, Total: 20}); level != StrengthFair {
+		t.Errorf("strengthLevel() = %q, ожидается fair", level.Code())
+	}
+	if StrengthLevel(99).Code() != "unknown" {
+		t.Errorf("Неизвестный уровень должен иметь код unknown")
+	}
+}
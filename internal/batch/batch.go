@@ -0,0 +1,309 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// Форматы вывода
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// Параметры строки, переопределяющие значения по умолчанию
+const (
+	overrideLength   = "length"
+	overrideCounter  = "counter"
+	overrideUsername = "user"
+)
+
+// Entry строка списка сервисов. Нулевые значения заменяются значениями по умолчанию.
+type Entry struct {
+	Line     int
+	Service  string
+	Length   int
+	Counter  uint32
+	Username string
+}
+
+// ParseError ошибка разбора строки списка сервисов
+type ParseError struct {
+	Line int
+	Code string
+}
+
+func (e *ParseError) Error() string {
+	return e.Code
+}
+
+// Result результат генерации для одной строки
+type Result struct {
+	Entry    Entry
+	Password *security.SecureString
+	Err      error
+}
+
+// Record строка вывода
+type Record struct {
+	Service  string `json:"service"`
+	Username string `json:"username"`
+	Length   int    `json:"length"`
+	Counter  uint32 `json:"counter"`
+	Password string `json:"password,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Labels заголовки столбцов таблицы
+type Labels struct {
+	Service  string
+	Username string
+	Counter  string
+	Password string
+	Error    string
+}
+
+// Parse читает список сервисов: по одному в строке, затем необязательные
+// параметры length=N, counter=N и user=NAME. Пустые строки и строки,
+// начинающиеся с #, пропускаются.
+func Parse(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entry, err := parseLine(text)
+		if err != nil {
+			return nil, &ParseError{Line: line, Code: err.Error()}
+		}
+		entry.Line = line
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("batch_no_services")
+	}
+	return entries, nil
+}
+
+// isOverride сообщает, является ли слово строки параметром: ключ до = должен
+// быть известным. Остальные слова с = относятся к имени сервиса, например
+// адрес с параметрами запроса.
+func isOverride(field string) bool {
+	key, _, found := strings.Cut(field, "=")
+	if !found {
+		return false
+	}
+	switch key {
+	case overrideLength, overrideCounter, overrideUsername:
+		return true
+	}
+	return false
+}
+
+func parseLine(text string) (Entry, error) {
+	var entry Entry
+
+	fields := strings.Fields(text)
+	serviceParts := []string{fields[0]}
+	overrides := false
+
+	for _, field := range fields[1:] {
+		if !isOverride(field) {
+			if overrides {
+				// После параметров имя сервиса продолжаться не может
+				return entry, fmt.Errorf("batch_unknown_override")
+			}
+			serviceParts = append(serviceParts, field)
+			continue
+		}
+		overrides = true
+		key, value, _ := strings.Cut(field, "=")
+
+		switch key {
+		case overrideLength:
+			length, err := strconv.Atoi(value)
+			if err != nil || generator.ValidateLength(length) != nil {
+				return entry, fmt.Errorf("batch_invalid_length")
+			}
+			entry.Length = length
+		case overrideCounter:
+			counter, err := strconv.Atoi(value)
+			if err != nil || generator.ValidateCounter(counter) != nil {
+				return entry, fmt.Errorf("batch_invalid_counter")
+			}
+			entry.Counter = uint32(counter)
+		case overrideUsername:
+			if value == "" {
+				return entry, fmt.Errorf("batch_empty_username")
+			}
+			entry.Username = value
+		}
+	}
+
+	entry.Service = strings.Join(serviceParts, " ")
+	return entry, nil
+}
+
+// ApplyDefaults заполняет незаданные параметры строк значениями по умолчанию
+func ApplyDefaults(entries []Entry, defaults Entry) {
+	for i := range entries {
+		if entries[i].Length == 0 {
+			entries[i].Length = defaults.Length
+		}
+		if entries[i].Counter == 0 {
+			entries[i].Counter = defaults.Counter
+		}
+		if entries[i].Username == "" {
+			entries[i].Username = defaults.Username
+		}
+	}
+}
+
+// Workers возвращает число параллельных вычислений, при котором суммарная
+// память Argon2 не превышает бюджет. Одно вычисление выполняется всегда.
+func Workers(argonMemoryKiB uint32, budgetMiB, maxWorkers int) int {
+	perWorkerMiB := int(argonMemoryKiB / 1024)
+	if perWorkerMiB < 1 {
+		perWorkerMiB = 1
+	}
+
+	workers := budgetMiB / perWorkerMiB
+	if workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// Run генерирует пароли пулом из workers горутин. Результаты возвращаются
+// в порядке строк независимо от порядка завершения.
+func Run(entries []Entry, workers int, generate func(Entry) (*security.SecureString, error)) []Result {
+	results := make([]Result, len(entries))
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				password, err := generate(entries[i])
+				results[i] = Result{Entry: entries[i], Password: password, Err: err}
+			}
+		}()
+	}
+
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// ParseFormat проверяет формат вывода
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case FormatTable, FormatJSON, FormatCSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("batch_unknown_format")
+	}
+}
+
+// Write выводит записи в выбранном формате
+func Write(w io.Writer, format string, records []Record, labels Labels) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, records)
+	case FormatCSV:
+		return writeCSV(w, records)
+	case FormatTable:
+		return writeTable(w, records, labels)
+	default:
+		return fmt.Errorf("batch_unknown_format")
+	}
+}
+
+func writeJSON(w io.Writer, records []Record) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	// Символы <, > и & встречаются в паролях и должны выводиться как есть
+	encoder.SetEscapeHTML(false)
+	if records == nil {
+		records = []Record{}
+	}
+	return encoder.Encode(records)
+}
+
+func writeCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"service", "username", "length", "counter", "password", "error"}); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := []string{
+			csvText(record.Service),
+			csvText(record.Username),
+			strconv.Itoa(record.Length),
+			strconv.FormatUint(uint64(record.Counter), 10),
+			record.Password,
+			csvText(record.Error),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText экранирует текстовое поле CSV апострофом, если табличный редактор
+// принял бы его за формулу. Пароль не экранируется: с апострофом он стал бы
+// другим паролем, а = + - @ есть в наборах символов.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func writeTable(w io.Writer, records []Record, labels Labels) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", labels.Service, labels.Username, labels.Counter, labels.Password)
+	for _, record := range records {
+		value := record.Password
+		if record.Error != "" {
+			value = labels.Error + " " + record.Error
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\n", record.Service, record.Username, record.Counter, value)
+	}
+	return writer.Flush()
+}
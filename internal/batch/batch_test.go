@@ -0,0 +1,267 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+func TestParse(t *testing.T) {
+	input := `# Рабочие сервисы
+github.com
+  gitlab.com length=24
+
+My Bank counter=3 user=alice
+mail length=20 counter=2
+intranet portal https://x/?a=b user=bob
+`
+	entries, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() ошибка: %v", err)
+	}
+
+	expected := []Entry{
+		{Line: 2, Service: "github.com"},
+		{Line: 3, Service: "gitlab.com", Length: 24},
+		{Line: 5, Service: "My Bank", Counter: 3, Username: "alice"},
+		{Line: 6, Service: "mail", Length: 20, Counter: 2},
+		// Слово с = и неизвестным ключом - часть имени сервиса
+		{Line: 7, Service: "intranet portal https://x/?a=b", Username: "bob"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Parse() вернул %d строк, ожидается %d", len(entries), len(expected))
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("Строка %d = %+v, ожидается %+v", i, entries[i], expected[i])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+		code  string
+	}{
+		{"Неизвестный параметр после параметров", "github.com\nmail length=20 lenght=20", 2, "batch_unknown_override"},
+		{"Имя после параметров", "mail length=20 extra", 1, "batch_unknown_override"},
+		{"Короткая длина", "mail length=3", 1, "batch_invalid_length"},
+		{"Нечисловая длина", "mail length=abc", 1, "batch_invalid_length"},
+		{"Нулевой счётчик", "mail counter=0", 1, "batch_invalid_counter"},
+		{"Пустой пользователь", "\n\nmail user=", 3, "batch_empty_username"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse() ошибка = %v, ожидается ParseError", err)
+			}
+			if parseErr.Line != tt.line || parseErr.Code != tt.code {
+				t.Errorf("Parse() ошибка = строка %d %s, ожидается строка %d %s", parseErr.Line, parseErr.Code, tt.line, tt.code)
+			}
+		})
+	}
+
+	t.Run("Пустой список", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("# только комментарий\n\n"))
+		if err == nil || err.Error() != "batch_no_services" {
+			t.Errorf("Parse() ошибка = %v, ожидается batch_no_services", err)
+		}
+	})
+}
+
+func TestApplyDefaults(t *testing.T) {
+	entries := []Entry{
+		{Service: "a"},
+		{Service: "b", Length: 24, Counter: 2, Username: "alice"},
+	}
+	ApplyDefaults(entries, Entry{Length: 16, Counter: 1, Username: "user"})
+
+	if entries[0] != (Entry{Service: "a", Length: 16, Counter: 1, Username: "user"}) {
+		t.Errorf("Значения по умолчанию не применены: %+v", entries[0])
+	}
+	if entries[1] != (Entry{Service: "b", Length: 24, Counter: 2, Username: "alice"}) {
+		t.Errorf("Заданные значения перезаписаны: %+v", entries[1])
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	tests := []struct {
+		name       string
+		memoryKiB  uint32
+		budgetMiB  int
+		maxWorkers int
+		expected   int
+	}{
+		{"Бюджет ограничивает", 256 * 1024, 1024, 8, 4},
+		{"Ядра ограничивают", 64 * 1024, 1024, 2, 2},
+		{"Бюджет меньше одного вычисления", 256 * 1024, 100, 8, 1},
+		{"Память меньше мегабайта", 512, 4, 8, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Workers(tt.memoryKiB, tt.budgetMiB, tt.maxWorkers); got != tt.expected {
+				t.Errorf("Workers() = %d, ожидается %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	entries := make([]Entry, 20)
+	for i := range entries {
+		entries[i] = Entry{Line: i + 1, Service: fmt.Sprintf("service%d", i)}
+	}
+
+	var running, peak int32
+	results := Run(entries, 3, func(entry Entry) (*security.SecureString, error) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		// Обратная задержка перемешивает порядок завершения
+		time.Sleep(time.Duration(len(entries)-entry.Line) * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if entry.Service == "service7" {
+			return nil, fmt.Errorf("failed")
+		}
+		return security.NewSecureString("pw-" + entry.Service), nil
+	})
+
+	if peak > 3 {
+		t.Errorf("Одновременно выполнялось %d вычислений, ожидается не более 3", peak)
+	}
+	if len(results) != len(entries) {
+		t.Fatalf("Run() вернул %d результатов, ожидается %d", len(results), len(entries))
+	}
+	for i, result := range results {
+		if result.Entry != entries[i] {
+			t.Errorf("Результат %d относится к %+v, порядок нарушен", i, result.Entry)
+			continue
+		}
+		if entries[i].Service == "service7" {
+			if result.Err == nil {
+				t.Error("Ошибка генерации потеряна")
+			}
+			continue
+		}
+		if result.Err != nil || result.Password.String() != "pw-"+entries[i].Service {
+			t.Errorf("Результат %d = %v, %v", i, result.Password, result.Err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	records := []Record{
+		{Service: "github.com", Username: "user", Length: 16, Counter: 1, Password: "a<b,def"},
+		{Service: "mail", Username: "alice", Length: 8, Counter: 2, Error: "too short"},
+	}
+	labels := Labels{Service: "SERVICE", Username: "USERNAME", Counter: "COUNTER", Password: "PASSWORD", Error: "error:"}
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatJSON, records, labels); err != nil {
+			t.Fatalf("Write() ошибка: %v", err)
+		}
+		var decoded []Record
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Некорректный JSON: %v", err)
+		}
+		if len(decoded) != 2 || decoded[0] != records[0] || decoded[1] != records[1] {
+			t.Errorf("JSON = %+v, ожидается %+v", decoded, records)
+		}
+		if strings.Contains(buf.String(), `"error": ""`) {
+			t.Error("Пустая ошибка не должна выводиться")
+		}
+		if !strings.Contains(buf.String(), "a<b,def") {
+			t.Error("Символы пароля экранированы")
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatCSV, records, labels); err != nil {
+			t.Fatalf("Write() ошибка: %v", err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("Некорректный CSV: %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("CSV содержит %d строк, ожидается 3", len(rows))
+		}
+		if rows[1][4] != "a<b,def" || rows[2][5] != "too short" || rows[2][3] != "2" {
+			t.Errorf("CSV строки = %v", rows)
+		}
+	})
+
+	t.Run("CSV формулы", func(t *testing.T) {
+		formulas := []Record{
+			{Service: "=cmd|' /C calc'!A0", Username: "@user", Length: 8, Counter: 1, Password: "-5+3"},
+			{Service: "+bank", Username: "\tuser", Length: 8, Counter: 1, Password: "a=b-c"},
+		}
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatCSV, formulas, labels); err != nil {
+			t.Fatalf("Write() ошибка: %v", err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("Некорректный CSV: %v", err)
+		}
+		want := [][]string{
+			{"'=cmd|' /C calc'!A0", "'@user", "-5+3"},
+			{"'+bank", "'\tuser", "a=b-c"},
+		}
+		// Пароль выводится как есть, даже похожий на формулу
+		for i, w := range want {
+			row := rows[i+1]
+			if row[0] != w[0] || row[1] != w[1] || row[4] != w[2] {
+				t.Errorf("CSV строка %d = %q, ожидается %q", i+1, row, w)
+			}
+		}
+	})
+
+	t.Run("Таблица", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatTable, records, labels); err != nil {
+			t.Fatalf("Write() ошибка: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("Таблица содержит %d строк, ожидается 3", len(lines))
+		}
+		if !strings.HasPrefix(lines[0], "SERVICE") || !strings.Contains(lines[2], "error: too short") {
+			t.Errorf("Таблица:\n%s", buf.String())
+		}
+		// Столбцы выровнены
+		if strings.Index(lines[1], "user") != strings.Index(lines[0], "USERNAME") {
+			t.Errorf("Столбцы не выровнены:\n%s", buf.String())
+		}
+	})
+
+	t.Run("Неизвестный формат", func(t *testing.T) {
+		if err := Write(&bytes.Buffer{}, "xml", records, labels); err == nil {
+			t.Error("Write() должен вернуть ошибку для неизвестного формата")
+		}
+		if _, err := ParseFormat("xml"); err == nil || err.Error() != "batch_unknown_format" {
+			t.Errorf("ParseFormat(xml) ошибка = %v, ожидается batch_unknown_format", err)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+// Package calibrate подбирает параметры Argon2id под текущую машину: замеряет
+// argon2.IDKey на сетке значений памяти и числа итераций и выбирает самые
+// дорогие параметры, укладывающиеся в заданное время.
+package calibrate
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Границы калибровки
+const (
+	MinMemoryKiB = 16 * 1024       // 16 МиБ - нижний уровень сетки памяти
+	MaxMemoryKiB = 4 * 1024 * 1024 // 4 ГиБ
+	MaxTime      = 10              // Наибольшее число итераций в сетке
+	MinTarget    = 100 * time.Millisecond
+	MaxTarget    = time.Minute
+)
+
+// Params параметры Argon2id одного замера
+type Params struct {
+	Time      uint32
+	MemoryKiB uint32
+	Threads   uint8
+}
+
+// Measurement результат замера
+type Measurement struct {
+	Params
+	Duration time.Duration
+}
+
+// Options настройки калибровки
+type Options struct {
+	Target       time.Duration
+	MaxMemoryKiB uint32
+	Threads      uint8
+}
+
+// Bench замеряет одно вычисление Argon2id с заданными параметрами
+type Bench func(p Params) time.Duration
+
+// Argon2Bench замеряет argon2.IDKey. Содержимое пароля и соли на время не влияет.
+func Argon2Bench(p Params) time.Duration {
+	password := make([]byte, 32)
+	salt := make([]byte, 16)
+	start := time.Now()
+	argon2.IDKey(password, salt, p.Time, p.MemoryKiB, p.Threads, 32)
+	return time.Since(start)
+}
+
+// Validate проверяет настройки калибровки
+func Validate(opts Options) error {
+	if opts.Target < MinTarget || opts.Target > MaxTarget {
+		return errors.New("target_invalid")
+	}
+	if opts.MaxMemoryKiB < MinMemoryKiB || opts.MaxMemoryKiB > MaxMemoryKiB {
+		return errors.New("memory_range")
+	}
+	if opts.Threads == 0 {
+		return errors.New("threads_invalid")
+	}
+	return nil
+}
+
+// MemoryLevels возвращает уровни памяти сетки: удвоение от MinMemoryKiB
+// до предела, сам предел входит последним уровнем
+func MemoryLevels(maxMemoryKiB uint32) []uint32 {
+	var levels []uint32
+	for memory := uint32(MinMemoryKiB); memory < maxMemoryKiB; memory *= 2 {
+		levels = append(levels, memory)
+	}
+	return append(levels, maxMemoryKiB)
+}
+
+// Run замеряет сетку параметров. Для каждого уровня памяти число итераций
+// растёт, пока замер не превысит цель; уровень, на котором уже одна итерация
+// дольше цели, завершает калибровку: больший объём памяти только медленнее.
+func Run(opts Options, bench Bench) []Measurement {
+	var results []Measurement
+	for _, memory := range MemoryLevels(opts.MaxMemoryKiB) {
+		for t := uint32(1); t <= MaxTime; t++ {
+			p := Params{Time: t, MemoryKiB: memory, Threads: opts.Threads}
+			m := Measurement{Params: p, Duration: bench(p)}
+			results = append(results, m)
+			if m.Duration > opts.Target {
+				if t == 1 {
+					return results
+				}
+				break
+			}
+		}
+	}
+	return results
+}
+
+// Recommend выбирает параметры из замеров: наибольший объём памяти, который
+// укладывается в цель, и для него наибольшее число итераций. Память важнее
+// итераций: она дороже всего обходится перебору на GPU и ASIC. Если в цель
+// не уложился ни один замер, возвращается самый быстрый и false.
+func Recommend(results []Measurement, target time.Duration) (Measurement, bool) {
+	var best, fastest Measurement
+	found := false
+	for i, m := range results {
+		if i == 0 || m.Duration < fastest.Duration {
+			fastest = m
+		}
+		if m.Duration > target {
+			continue
+		}
+		if !found || m.MemoryKiB > best.MemoryKiB || (m.MemoryKiB == best.MemoryKiB && m.Time > best.Time) {
+			best, found = m, true
+		}
+	}
+	if !found {
+		return fastest, false
+	}
+	return best, true
+}
+
+// ParseMemory разбирает объём памяти вида 512MiB, 1GiB или 65536KiB и
+// возвращает его в КиБ. Число без единицы - МиБ. Единицы двоичные:
+// MB и M означают то же, что MiB.
+func ParseMemory(value string) (uint32, error) {
+	s := strings.TrimSpace(strings.ToLower(value))
+	units := []struct {
+		suffix string
+		kib    uint64
+	}{
+		{"kib", 1}, {"kb", 1}, {"k", 1},
+		{"mib", 1024}, {"mb", 1024}, {"m", 1024},
+		{"gib", 1024 * 1024}, {"gb", 1024 * 1024}, {"g", 1024 * 1024},
+	}
+	multiplier := uint64(1024)
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.kib
+			break
+		}
+	}
+
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || n == 0 || n*multiplier > 1<<32-1 {
+		return 0, errors.New("memory_invalid")
+	}
+	return uint32(n * multiplier), nil
+}
@@ -0,0 +1,145 @@
+package calibrate
+
+import (
+	"testing"
+	"time"
+)
+
+// linearBench имитирует машину, на которой итерация над 64 МиБ длится 100 мс
+func linearBench(p Params) time.Duration {
+	return time.Duration(p.Time) * time.Duration(p.MemoryKiB) * 100 * time.Millisecond / (64 * 1024)
+}
+
+func TestMemoryLevels(t *testing.T) {
+	tests := []struct {
+		name     string
+		max      uint32
+		expected []uint32
+	}{
+		{"Степень двойки", 64 * 1024, []uint32{16 * 1024, 32 * 1024, 64 * 1024}},
+		{"Произвольный предел", 40 * 1024, []uint32{16 * 1024, 32 * 1024, 40 * 1024}},
+		{"Минимум", MinMemoryKiB, []uint32{MinMemoryKiB}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MemoryLevels(tt.max)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("MemoryLevels() = %v, ожидается %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("MemoryLevels()[%d] = %d, ожидается %d", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunAndRecommend(t *testing.T) {
+	opts := Options{Target: time.Second, MaxMemoryKiB: 1024 * 1024, Threads: 4}
+	results := Run(opts, linearBench)
+
+	// 1 ГиБ за одну итерацию - 1.6 с, дальше цели: уровень 1 ГиБ не продолжается
+	last := results[len(results)-1]
+	if last.MemoryKiB != 1024*1024 || last.Time != 1 {
+		t.Errorf("Последний замер = %+v, ожидается t=1 на 1 ГиБ", last)
+	}
+	for _, m := range results {
+		if m.Threads != 4 {
+			t.Errorf("Замер %+v с другим числом потоков", m)
+		}
+	}
+
+	best, ok := Recommend(results, opts.Target)
+	if !ok {
+		t.Fatal("Recommend() не нашёл параметры в пределах цели")
+	}
+	// 512 МиБ × 1 итерация = 800 мс, 512 МиБ × 2 = 1.6 с
+	if best.MemoryKiB != 512*1024 || best.Time != 1 {
+		t.Errorf("Recommend() = %+v, ожидается t=1, m=512 МиБ", best.Params)
+	}
+}
+
+func TestRunStopsOnSlowMachine(t *testing.T) {
+	slow := func(p Params) time.Duration { return 2 * time.Second }
+	results := Run(Options{Target: time.Second, MaxMemoryKiB: 512 * 1024, Threads: 1}, slow)
+	if len(results) != 1 {
+		t.Fatalf("Run() сделал %d замеров, ожидается остановка после первого", len(results))
+	}
+
+	best, ok := Recommend(results, time.Second)
+	if ok || best.MemoryKiB != MinMemoryKiB {
+		t.Errorf("Recommend() = %+v, %v, ожидаются самые быстрые параметры и false", best.Params, ok)
+	}
+}
+
+func TestRecommendPrefersMemory(t *testing.T) {
+	results := []Measurement{
+		{Params{Time: 8, MemoryKiB: 32 * 1024, Threads: 4}, 900 * time.Millisecond},
+		{Params{Time: 1, MemoryKiB: 128 * 1024, Threads: 4}, 300 * time.Millisecond},
+		{Params{Time: 2, MemoryKiB: 128 * 1024, Threads: 4}, 600 * time.Millisecond},
+		{Params{Time: 1, MemoryKiB: 256 * 1024, Threads: 4}, 1200 * time.Millisecond},
+	}
+	best, ok := Recommend(results, time.Second)
+	if !ok || best.MemoryKiB != 128*1024 || best.Time != 2 {
+		t.Errorf("Recommend() = %+v, ожидается t=2, m=128 МиБ", best.Params)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Options{Target: time.Second, MaxMemoryKiB: 512 * 1024, Threads: 4}
+	if err := Validate(valid); err != nil {
+		t.Errorf("Validate() ошибка: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"Короткая цель", Options{Target: time.Millisecond, MaxMemoryKiB: 512 * 1024, Threads: 4}, "target_invalid"},
+		{"Мало памяти", Options{Target: time.Second, MaxMemoryKiB: 1024, Threads: 4}, "memory_range"},
+		{"Без потоков", Options{Target: time.Second, MaxMemoryKiB: 512 * 1024}, "threads_invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.opts); err == nil || err.Error() != tt.expected {
+				t.Errorf("Validate() ошибка = %v, ожидается %s", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected uint32
+		valid    bool
+	}{
+		{"512MiB", 512 * 1024, true},
+		{"512", 512 * 1024, true},
+		{"1GiB", 1024 * 1024, true},
+		{"2 GB", 2 * 1024 * 1024, true},
+		{"65536KiB", 65536, true},
+		{"256m", 256 * 1024, true},
+		{"", 0, false},
+		{"0MiB", 0, false},
+		{"abc", 0, false},
+		{"8TiB", 0, false},
+		{"5000GiB", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMemory(tt.value)
+		if (err == nil) != tt.valid || got != tt.expected {
+			t.Errorf("ParseMemory(%q) = %d, %v", tt.value, got, err)
+		}
+	}
+}
+
+func TestArgon2Bench(t *testing.T) {
+	if d := Argon2Bench(Params{Time: 1, MemoryKiB: 1024, Threads: 1}); d <= 0 {
+		t.Errorf("Argon2Bench() = %v, ожидается положительное время", d)
+	}
+}
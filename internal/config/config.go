@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/verifier"
 )
 
 // ProfileStatistics статистика использования профиля
@@ -22,7 +23,7 @@ type ProfileStatistics struct {
 	// Время последнего использования
 	LastUsed *time.Time `json:"last_used,omitempty"`
 
-	// Название текущего профиля (для будущего расширения)
+	// Название профиля, которому принадлежит конфигурация
 	CurrentProfile string `json:"current_profile"`
 
 	// Статистика времени генерации
@@ -42,18 +43,36 @@ type Config struct {
 	DefaultLength   int    `json:"default_length"`
 	DefaultLanguage string `json:"default_language"`
 	CharacterSet    string `json:"character_set"`
+	RequiredClasses string `json:"required_classes"` // Обязательные классы символов, например "upper,digit,symbol"
+	PasswordType    string `json:"password_type"`    // Шаблон пароля ("pin", "basic", ...), пусто - генерация по набору символов
+	Algorithm       string `json:"algorithm"`        // Версия алгоритма генерации: "v1", "v2", "v3" или "v4"
+
+	// Приведение имени сервиса перед генерацией: "off", "host" или "domain"
+	ServiceCanonical string `json:"service_canonical"`
+	ServiceRecord    string `json:"service_record"` // Список использованных сервисов от опечаток: "plain", "blinded" или "off"
+
+	// Настройки парольных фраз
+	PassphraseWords      int    `json:"passphrase_words"`
+	PassphraseSeparator  string `json:"passphrase_separator"`
+	PassphraseWordlist   string `json:"passphrase_wordlist"`
+	PassphraseCapitalize bool   `json:"passphrase_capitalize"`
+	PassphraseDigit      bool   `json:"passphrase_digit"`
 
 	// Настройки буфера обмена
 	DefaultCopy         bool `json:"default_copy"`
 	DefaultClearTimeout int  `json:"default_clear_timeout"`
 
 	// Настройки отображения
-	ShowPasswordInfo bool `json:"show_password_info"`
-	ColorOutput      bool `json:"color_output"`
+	ShowPasswordInfo bool   `json:"show_password_info"`
+	ColorOutput      bool   `json:"color_output"`
+	Identicon        string `json:"identicon"` // Отпечаток мастер-пароля: "unicode", "ascii" или "off"
 
 	// Новые настройки для улучшенной генерации salt
 	Username string `json:"username"`
 
+	// Проверка мастер-пароля от опечаток, задаётся командой pgen master set-check
+	MasterCheck *verifier.Check `json:"master_check,omitempty"`
+
 	// Статистика использования
 	ProfileStats ProfileStatistics `json:"profile_stats"`
 
@@ -71,10 +90,17 @@ func DefaultConfig() *Config {
 		DefaultLength:       16,
 		DefaultLanguage:     "auto",
 		CharacterSet:        "alphanumeric_symbols",
+		Algorithm:           "v2",
+		ServiceCanonical:    "host",
+		ServiceRecord:       "plain",
+		PassphraseWords:     6,
+		PassphraseSeparator: "-",
+		PassphraseWordlist:  "en",
 		DefaultCopy:         false,
 		DefaultClearTimeout: 45,
 		ShowPasswordInfo:    false,
 		ColorOutput:         true,
+		Identicon:           "unicode",
 		Username:            "user",
 		ProfileStats: ProfileStatistics{
 			PasswordsGenerated: 0,
@@ -84,8 +110,21 @@ func DefaultConfig() *Config {
 	}
 }
 
-// GetConfigPath возвращает путь к файлу конфигурации
+// GetConfigPath возвращает путь к файлу конфигурации выбранного профиля
 func GetConfigPath() (string, error) {
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// GetConfigDir возвращает общий каталог pgen, в котором лежат конфигурация
+// профиля default и каталоги остальных профилей
+func GetConfigDir() (string, error) {
 	var configDir string
 
 	switch runtime.GOOS {
@@ -120,20 +159,26 @@ func GetConfigPath() (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(pgenConfigDir, "config.json"), nil
+	return pgenConfigDir, nil
 }
 
-// Load загружает конфигурацию из файла
+// Load загружает конфигурацию выбранного профиля
 func Load(messages *i18n.Messages) (*Config, error) {
-	configPath, err := GetConfigPath()
+	return LoadProfile(profile, messages)
+}
+
+// LoadProfile загружает конфигурацию профиля name
+func LoadProfile(name string, messages *i18n.Messages) (*Config, error) {
+	dir, err := ProfileDir(name)
 	if err != nil {
-		return DefaultConfig(), nil
+		return defaultProfileConfig(name), nil
 	}
+	configPath := filepath.Join(dir, "config.json")
 
 	data, err := os.ReadFile(configPath)
 	if os.IsNotExist(err) {
 		// Файл не существует, возвращаем конфигурацию по умолчанию
-		return DefaultConfig(), nil
+		return defaultProfileConfig(name), nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf(messages.ConfigErrorReading, err)
@@ -146,17 +191,22 @@ func Load(messages *i18n.Messages) (*Config, error) {
 
 	// Валидация и установка значений по умолчанию
 	config.validate()
+	config.ProfileStats.CurrentProfile = name
 
 	return &config, nil
 }
 
-// Save сохраняет конфигурацию в файл
+// Save сохраняет конфигурацию выбранного профиля
 func (c *Config) Save(messages *i18n.Messages) error {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return err
 	}
+	return c.saveTo(configPath, messages)
+}
 
+// saveTo записывает конфигурацию в файл path
+func (c *Config) saveTo(configPath string, messages *i18n.Messages) error {
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return fmt.Errorf(messages.ConfigErrorEncoding, err)
@@ -195,6 +245,27 @@ func (c *Config) validate() {
 	if c.CharacterSet == "" {
 		c.CharacterSet = "alphanumeric_symbols"
 	}
+	if c.Algorithm == "" {
+		// Конфигурации без версии созданы до появления v2 и должны давать прежние пароли
+		c.Algorithm = "v1"
+	}
+	if c.ServiceCanonical == "" {
+		// Конфигурации без настройки созданы до её появления: имена сервисов
+		// используются как введены, чтобы пароли не изменились
+		c.ServiceCanonical = "off"
+	}
+	if c.ServiceRecord == "" {
+		c.ServiceRecord = "plain"
+	}
+	if c.PassphraseWords == 0 {
+		c.PassphraseWords = 6
+	}
+	if c.PassphraseSeparator == "" {
+		c.PassphraseSeparator = "-"
+	}
+	if c.PassphraseWordlist == "" {
+		c.PassphraseWordlist = "en"
+	}
 	if c.DefaultClearTimeout < 0 {
 		c.DefaultClearTimeout = 45
 	}
@@ -32,6 +32,12 @@ func TestDefaultConfig(t *testing.T) {
 		{"DefaultLength", config.DefaultLength, 16},
 		{"DefaultLanguage", config.DefaultLanguage, "auto"},
 		{"CharacterSet", config.CharacterSet, "alphanumeric_symbols"},
+		{"Algorithm", config.Algorithm, "v2"},
+		{"ServiceCanonical", config.ServiceCanonical, "host"},
+		{"ServiceRecord", config.ServiceRecord, "plain"},
+		{"PassphraseWords", config.PassphraseWords, 6},
+		{"PassphraseSeparator", config.PassphraseSeparator, "-"},
+		{"PassphraseWordlist", config.PassphraseWordlist, "en"},
 		{"DefaultCopy", config.DefaultCopy, false},
 		{"DefaultClearTimeout", config.DefaultClearTimeout, 45},
 		{"ShowPasswordInfo", config.ShowPasswordInfo, false},
@@ -233,6 +239,67 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestConfigValidateAlgorithm(t *testing.T) {
+	// Конфигурация старой версии без поля algorithm сохраняет алгоритм v1
+	legacy := &Config{}
+	legacy.validate()
+	if legacy.Algorithm != "v1" {
+		t.Errorf("validate() Algorithm = %q, ожидается \"v1\"", legacy.Algorithm)
+	}
+
+	current := &Config{Algorithm: "v2"}
+	current.validate()
+	if current.Algorithm != "v2" {
+		t.Errorf("validate() Algorithm = %q, ожидается \"v2\"", current.Algorithm)
+	}
+}
+
+func TestConfigValidateServiceCanonical(t *testing.T) {
+	// Конфигурация без настройки использует имена сервисов как введены
+	legacy := &Config{}
+	legacy.validate()
+	if legacy.ServiceCanonical != "off" {
+		t.Errorf("validate() ServiceCanonical = %q, ожидается \"off\"", legacy.ServiceCanonical)
+	}
+
+	current := &Config{ServiceCanonical: "domain"}
+	current.validate()
+	if current.ServiceCanonical != "domain" {
+		t.Errorf("validate() ServiceCanonical = %q, ожидается \"domain\"", current.ServiceCanonical)
+	}
+}
+
+func TestConfigValidateServiceRecord(t *testing.T) {
+	// Конфигурация без настройки ведёт открытый список сервисов
+	legacy := &Config{}
+	legacy.validate()
+	if legacy.ServiceRecord != "plain" {
+		t.Errorf("validate() ServiceRecord = %q, ожидается \"plain\"", legacy.ServiceRecord)
+	}
+
+	current := &Config{ServiceRecord: "off"}
+	current.validate()
+	if current.ServiceRecord != "off" {
+		t.Errorf("validate() ServiceRecord = %q, ожидается \"off\"", current.ServiceRecord)
+	}
+}
+
+func TestConfigValidatePassphraseDefaults(t *testing.T) {
+	// Конфигурации старых версий не содержат настроек парольных фраз
+	config := &Config{}
+	config.validate()
+
+	if config.PassphraseWords != 6 {
+		t.Errorf("validate() PassphraseWords = %v, ожидается 6", config.PassphraseWords)
+	}
+	if config.PassphraseSeparator != "-" {
+		t.Errorf("validate() PassphraseSeparator = %q, ожидается \"-\"", config.PassphraseSeparator)
+	}
+	if config.PassphraseWordlist != "en" {
+		t.Errorf("validate() PassphraseWordlist = %q, ожидается \"en\"", config.PassphraseWordlist)
+	}
+}
+
 func TestLoadConfigNotExists(t *testing.T) {
 	// Тест загрузки конфигурации когда файл не существует
 	messages := &i18n.Messages{
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// derivationHistoryFile файл снимков прежних настроек в каталоге профиля
+const derivationHistoryFile = "derivation_history.json"
+
+// derivationHistoryVersion версия формата файла снимков
+const derivationHistoryVersion = 1
+
+// Derivation настройки, от которых зависят сгенерированные пароли. Смена
+// любой из них делает прежние пароли невоспроизводимыми.
+type Derivation struct {
+	ArgonTime        uint32 `json:"argon_time"`
+	ArgonMemory      uint32 `json:"argon_memory"`
+	ArgonThreads     uint8  `json:"argon_threads"`
+	ArgonKeyLen      uint32 `json:"argon_key_len"`
+	Username         string `json:"username"`
+	Algorithm        string `json:"algorithm"`
+	ServiceCanonical string `json:"service_canonical"`
+	CharacterSet     string `json:"character_set"`
+	RequiredClasses  string `json:"required_classes"`
+	PasswordType     string `json:"password_type"`
+	DefaultLength    int    `json:"default_length"`
+
+	PassphraseWords      int    `json:"passphrase_words"`
+	PassphraseSeparator  string `json:"passphrase_separator"`
+	PassphraseWordlist   string `json:"passphrase_wordlist"`
+	PassphraseCapitalize bool   `json:"passphrase_capitalize"`
+	PassphraseDigit      bool   `json:"passphrase_digit"`
+}
+
+// DerivationChange изменённая настройка с ключом конфигурации
+type DerivationChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// DerivationSnapshot снимок настроек, действовавших до изменения
+type DerivationSnapshot struct {
+	Derivation
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+// derivationHistory содержимое файла снимков
+type derivationHistory struct {
+	Version   int                  `json:"version"`
+	Snapshots []DerivationSnapshot `json:"snapshots"`
+}
+
+// Derivation возвращает настройки конфигурации, от которых зависят пароли
+func (c *Config) Derivation() Derivation {
+	return Derivation{
+		ArgonTime:        c.ArgonTime,
+		ArgonMemory:      c.ArgonMemory,
+		ArgonThreads:     c.ArgonThreads,
+		ArgonKeyLen:      c.ArgonKeyLen,
+		Username:         c.Username,
+		Algorithm:        c.Algorithm,
+		ServiceCanonical: c.ServiceCanonical,
+		CharacterSet:     c.CharacterSet,
+		RequiredClasses:  c.RequiredClasses,
+		PasswordType:     c.PasswordType,
+		DefaultLength:    c.DefaultLength,
+
+		PassphraseWords:      c.PassphraseWords,
+		PassphraseSeparator:  c.PassphraseSeparator,
+		PassphraseWordlist:   c.PassphraseWordlist,
+		PassphraseCapitalize: c.PassphraseCapitalize,
+		PassphraseDigit:      c.PassphraseDigit,
+	}
+}
+
+// ApplyDerivation заменяет настройки конфигурации, от которых зависят пароли
+func (c *Config) ApplyDerivation(d Derivation) {
+	c.ArgonTime = d.ArgonTime
+	c.ArgonMemory = d.ArgonMemory
+	c.ArgonThreads = d.ArgonThreads
+	c.ArgonKeyLen = d.ArgonKeyLen
+	c.Username = d.Username
+	c.Algorithm = d.Algorithm
+	c.ServiceCanonical = d.ServiceCanonical
+	c.CharacterSet = d.CharacterSet
+	c.RequiredClasses = d.RequiredClasses
+	c.PasswordType = d.PasswordType
+	c.DefaultLength = d.DefaultLength
+	c.PassphraseWords = d.PassphraseWords
+	c.PassphraseSeparator = d.PassphraseSeparator
+	c.PassphraseWordlist = d.PassphraseWordlist
+	c.PassphraseCapitalize = d.PassphraseCapitalize
+	c.PassphraseDigit = d.PassphraseDigit
+}
+
+// DerivationChanges возвращает настройки, которые отличаются в old и new,
+// в порядке ключей конфигурации
+func DerivationChanges(old, new Derivation) []DerivationChange {
+	fields := []struct {
+		key      string
+		old, new string
+	}{
+		{"argon_time", strconv.FormatUint(uint64(old.ArgonTime), 10), strconv.FormatUint(uint64(new.ArgonTime), 10)},
+		{"argon_memory", strconv.FormatUint(uint64(old.ArgonMemory), 10), strconv.FormatUint(uint64(new.ArgonMemory), 10)},
+		{"argon_threads", strconv.FormatUint(uint64(old.ArgonThreads), 10), strconv.FormatUint(uint64(new.ArgonThreads), 10)},
+		{"argon_key_len", strconv.FormatUint(uint64(old.ArgonKeyLen), 10), strconv.FormatUint(uint64(new.ArgonKeyLen), 10)},
+		{"username", old.Username, new.Username},
+		{"algorithm", old.Algorithm, new.Algorithm},
+		{"service_canonical", old.ServiceCanonical, new.ServiceCanonical},
+		{"character_set", old.CharacterSet, new.CharacterSet},
+		{"required_classes", old.RequiredClasses, new.RequiredClasses},
+		{"password_type", old.PasswordType, new.PasswordType},
+		{"default_length", strconv.Itoa(old.DefaultLength), strconv.Itoa(new.DefaultLength)},
+		{"passphrase_words", strconv.Itoa(old.PassphraseWords), strconv.Itoa(new.PassphraseWords)},
+		{"passphrase_separator", old.PassphraseSeparator, new.PassphraseSeparator},
+		{"passphrase_wordlist", old.PassphraseWordlist, new.PassphraseWordlist},
+		{"passphrase_capitalize", strconv.FormatBool(old.PassphraseCapitalize), strconv.FormatBool(new.PassphraseCapitalize)},
+		{"passphrase_digit", strconv.FormatBool(old.PassphraseDigit), strconv.FormatBool(new.PassphraseDigit)},
+	}
+
+	var changes []DerivationChange
+	for _, f := range fields {
+		if f.old != f.new {
+			changes = append(changes, DerivationChange{Key: f.key, Old: f.old, New: f.new})
+		}
+	}
+	return changes
+}
+
+// derivationHistoryPath возвращает путь к файлу снимков текущего профиля
+func derivationHistoryPath() (string, error) {
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, derivationHistoryFile), nil
+}
+
+// DerivationHistory возвращает снимки текущего профиля от старых к новым.
+// Номер снимка - его позиция, начиная с 1.
+func DerivationHistory() ([]DerivationSnapshot, error) {
+	path, err := derivationHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var history derivationHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, errors.New("history_invalid")
+	}
+	if history.Version > derivationHistoryVersion {
+		return nil, errors.New("history_version")
+	}
+	// Снимки без параметров фраз записаны до их появления в снимке: тогда
+	// пустые значения заменялись значениями по умолчанию, как в validate
+	for i := range history.Snapshots {
+		d := &history.Snapshots[i].Derivation
+		if d.PassphraseWords == 0 {
+			d.PassphraseWords = 6
+		}
+		if d.PassphraseSeparator == "" {
+			d.PassphraseSeparator = "-"
+		}
+		if d.PassphraseWordlist == "" {
+			d.PassphraseWordlist = "en"
+		}
+	}
+	return history.Snapshots, nil
+}
+
+// SaveDerivationSnapshot добавляет снимок прежних настроек и возвращает его
+// номер. Снимок, совпадающий с последним, повторно не добавляется.
+func SaveDerivationSnapshot(d Derivation) (int, error) {
+	snapshots, err := DerivationHistory()
+	if err != nil {
+		return 0, err
+	}
+	if n := len(snapshots); n > 0 && snapshots[n-1].Derivation == d {
+		return n, nil
+	}
+	snapshots = append(snapshots, DerivationSnapshot{Derivation: d, ReplacedAt: time.Now()})
+
+	data, err := json.MarshalIndent(derivationHistory{Version: derivationHistoryVersion, Snapshots: snapshots}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	path, err := derivationHistoryPath()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	// В снимке имя пользователя, поэтому файл доступен только владельцу
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// DerivationSnapshotAt возвращает снимок с номером n
+func DerivationSnapshotAt(n int) (DerivationSnapshot, error) {
+	snapshots, err := DerivationHistory()
+	if err != nil {
+		return DerivationSnapshot{}, err
+	}
+	if n < 1 || n > len(snapshots) {
+		return DerivationSnapshot{}, errors.New("snapshot_not_found")
+	}
+	return snapshots[n-1], nil
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+)
+
+func TestDerivationChanges(t *testing.T) {
+	old := DefaultConfig().Derivation()
+	if changes := DerivationChanges(old, old); len(changes) != 0 {
+		t.Errorf("DerivationChanges() без изменений = %v", changes)
+	}
+
+	updated := old
+	updated.ArgonMemory = 512 * 1024
+	updated.Username = "alice"
+	changes := DerivationChanges(old, updated)
+	if len(changes) != 2 {
+		t.Fatalf("DerivationChanges() = %v, ожидается два изменения", changes)
+	}
+	if changes[0] != (DerivationChange{Key: "argon_memory", Old: "262144", New: "524288"}) {
+		t.Errorf("Первое изменение = %+v", changes[0])
+	}
+	if changes[1] != (DerivationChange{Key: "username", Old: "user", New: "alice"}) {
+		t.Errorf("Второе изменение = %+v", changes[1])
+	}
+
+	// Параметры фраз тоже меняют пароли
+	phrase := old
+	phrase.PassphraseWords = 8
+	phrase.PassphraseCapitalize = true
+	changes = DerivationChanges(old, phrase)
+	if len(changes) != 2 ||
+		changes[0] != (DerivationChange{Key: "passphrase_words", Old: "6", New: "8"}) ||
+		changes[1] != (DerivationChange{Key: "passphrase_capitalize", Old: "false", New: "true"}) {
+		t.Errorf("DerivationChanges() параметров фразы = %v", changes)
+	}
+}
+
+func TestApplyDerivation(t *testing.T) {
+	c := DefaultConfig()
+	d := c.Derivation()
+	d.Algorithm = "v1"
+	d.DefaultLength = 20
+	d.PassphraseWords = 4
+	d.PassphraseSeparator = "_"
+	d.PassphraseWordlist = "ru"
+	d.PassphraseDigit = true
+
+	c.ApplyDerivation(d)
+	if c.Derivation() != d {
+		t.Errorf("Derivation() после ApplyDerivation() = %+v, ожидается %+v", c.Derivation(), d)
+	}
+	if !c.ColorOutput {
+		t.Error("ApplyDerivation() не должен менять остальные настройки")
+	}
+}
+
+func TestDerivationSnapshots(t *testing.T) {
+	useTempConfigDir(t)
+
+	snapshots, err := DerivationHistory()
+	if err != nil || len(snapshots) != 0 {
+		t.Fatalf("DerivationHistory() без файла = %v, %v", snapshots, err)
+	}
+
+	first := DefaultConfig().Derivation()
+	n, err := SaveDerivationSnapshot(first)
+	if err != nil || n != 1 {
+		t.Fatalf("SaveDerivationSnapshot() = %d, %v", n, err)
+	}
+	// Повтор того же снимка не добавляется
+	if n, _ := SaveDerivationSnapshot(first); n != 1 {
+		t.Errorf("Повторный SaveDerivationSnapshot() = %d, ожидается 1", n)
+	}
+
+	second := first
+	second.Username = "alice"
+	if n, _ := SaveDerivationSnapshot(second); n != 2 {
+		t.Errorf("SaveDerivationSnapshot() = %d, ожидается 2", n)
+	}
+
+	snapshot, err := DerivationSnapshotAt(2)
+	if err != nil || snapshot.Username != "alice" || snapshot.ReplacedAt.IsZero() {
+		t.Errorf("DerivationSnapshotAt(2) = %+v, %v", snapshot, err)
+	}
+	if _, err := DerivationSnapshotAt(3); err == nil || err.Error() != "snapshot_not_found" {
+		t.Errorf("DerivationSnapshotAt(3) ошибка = %v, ожидается snapshot_not_found", err)
+	}
+
+	dir, _ := ProfileDir(DefaultProfile)
+	info, err := os.Stat(filepath.Join(dir, derivationHistoryFile))
+	if err != nil {
+		t.Fatalf("Файл снимков не создан: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Права файла снимков = %v, ожидается 0600", info.Mode().Perm())
+	}
+}
+
+func TestDerivationHistoryPassphraseDefaults(t *testing.T) {
+	useTempConfigDir(t)
+	path, err := derivationHistoryPath()
+	if err != nil {
+		t.Fatalf("derivationHistoryPath() ошибка: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Снимок, записанный до появления параметров фраз
+	data := `{"version":1,"snapshots":[{"argon_time":3,"username":"bob","default_length":16}]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := DerivationSnapshotAt(1)
+	if err != nil {
+		t.Fatalf("DerivationSnapshotAt(1) ошибка: %v", err)
+	}
+	if snapshot.PassphraseWords != 6 || snapshot.PassphraseSeparator != "-" || snapshot.PassphraseWordlist != "en" {
+		t.Errorf("Параметры фразы старого снимка = %+v", snapshot.Derivation)
+	}
+}
+
+func TestDerivationHistoryPerProfile(t *testing.T) {
+	useTempConfigDir(t)
+	if _, err := SaveDerivationSnapshot(DefaultConfig().Derivation()); err != nil {
+		t.Fatalf("SaveDerivationSnapshot() ошибка: %v", err)
+	}
+
+	if err := CreateProfile("work", DefaultConfig(), &i18n.Messages{}); err != nil {
+		t.Fatalf("CreateProfile() ошибка: %v", err)
+	}
+	if err := UseProfile("work"); err != nil {
+		t.Fatalf("UseProfile() ошибка: %v", err)
+	}
+	snapshots, err := DerivationHistory()
+	if err != nil || len(snapshots) != 0 {
+		t.Errorf("DerivationHistory() нового профиля = %v, %v, ожидается пусто", snapshots, err)
+	}
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+)
+
+// DefaultProfile профиль, конфигурация которого лежит прямо в каталоге pgen.
+// Так установки, созданные до появления профилей, продолжают работать.
+const DefaultProfile = "default"
+
+const (
+	profilesDirName   = "profiles"       // каталог именованных профилей
+	activeProfileFile = "active_profile" // имя профиля, выбранного pgen profile use
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// profile профиль текущего запуска
+var profile = DefaultProfile
+
+// ValidateProfileName проверяет имя профиля: латинские буквы, цифры, '_' и '-',
+// не длиннее 32 символов
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return errors.New("profile_name_invalid")
+	}
+	return nil
+}
+
+// ProfileDir возвращает каталог профиля. У профиля default это общий каталог pgen.
+func ProfileDir(name string) (string, error) {
+	base, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultProfile {
+		return base, nil
+	}
+	return filepath.Join(base, profilesDirName, name), nil
+}
+
+// ProfileExists сообщает, создан ли профиль. Профиль default есть всегда.
+func ProfileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	if ValidateProfileName(name) != nil {
+		return false
+	}
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, "config.json"))
+	return err == nil
+}
+
+// UseProfile выбирает профиль для текущего запуска: с ним работают
+// GetConfigPath, Load и Save
+func UseProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !ProfileExists(name) {
+		return errors.New("profile_not_found")
+	}
+	profile = name
+	return nil
+}
+
+// CurrentProfile возвращает профиль текущего запуска
+func CurrentProfile() string {
+	return profile
+}
+
+// ActiveProfile возвращает профиль, выбранный командой pgen profile use
+func ActiveProfile() (string, error) {
+	base, err := GetConfigDir()
+	if err != nil {
+		return DefaultProfile, err
+	}
+	data, err := os.ReadFile(filepath.Join(base, activeProfileFile))
+	if os.IsNotExist(err) {
+		return DefaultProfile, nil
+	}
+	if err != nil {
+		return DefaultProfile, err
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return DefaultProfile, nil
+	}
+	return name, nil
+}
+
+// SetActiveProfile делает профиль активным для следующих запусков
+func SetActiveProfile(name string) error {
+	if !ProfileExists(name) {
+		return errors.New("profile_not_found")
+	}
+	base, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(base, activeProfileFile)
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, []byte(name+"\n"), 0644)
+}
+
+// Profiles возвращает имена профилей: default, затем остальные по алфавиту
+func Profiles() ([]string, error) {
+	base, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(base, profilesDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && ProfileExists(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// CreateProfile создаёт профиль с конфигурацией c. Статистика нового
+// профиля начинается с нуля.
+func CreateProfile(name string, c *Config, messages *i18n.Messages) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if ProfileExists(name) {
+		return errors.New("profile_exists")
+	}
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	created := *c
+	created.ProfileStats = ProfileStatistics{CurrentProfile: name}
+	return created.saveTo(filepath.Join(dir, "config.json"), messages)
+}
+
+// RemoveProfile удаляет профиль вместе с его реестром сервисов и хранилищем.
+// Профиль default и активный профиль не удаляются.
+func RemoveProfile(name string) error {
+	if name == DefaultProfile {
+		return errors.New("profile_default")
+	}
+	if !ProfileExists(name) {
+		return errors.New("profile_not_found")
+	}
+	if active, _ := ActiveProfile(); active == name {
+		return errors.New("profile_active")
+	}
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// RenameProfile переименовывает профиль. Активный профиль остаётся активным.
+func RenameProfile(oldName, newName string, messages *i18n.Messages) error {
+	if oldName == DefaultProfile || newName == DefaultProfile {
+		return errors.New("profile_default")
+	}
+	if err := ValidateProfileName(newName); err != nil {
+		return err
+	}
+	if !ProfileExists(oldName) {
+		return errors.New("profile_not_found")
+	}
+	if ProfileExists(newName) {
+		return errors.New("profile_exists")
+	}
+
+	oldDir, err := ProfileDir(oldName)
+	if err != nil {
+		return err
+	}
+	newDir, err := ProfileDir(newName)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(oldDir, newDir); err != nil {
+		return err
+	}
+
+	renamed, err := LoadProfile(newName, messages)
+	if err != nil {
+		return err
+	}
+	if err := renamed.saveTo(filepath.Join(newDir, "config.json"), messages); err != nil {
+		return err
+	}
+	if active, _ := ActiveProfile(); active == oldName {
+		return SetActiveProfile(newName)
+	}
+	return nil
+}
+
+// defaultProfileConfig возвращает конфигурацию по умолчанию для профиля name
+func defaultProfileConfig(name string) *Config {
+	c := DefaultConfig()
+	c.ProfileStats.CurrentProfile = name
+	return c
+}
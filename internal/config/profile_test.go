@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+)
+
+// useTempConfigDir переносит каталог конфигурации во временный каталог и
+// возвращает профиль default после теста
+func useTempConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Cleanup(func() { profile = DefaultProfile })
+
+	base, err := GetConfigDir()
+	if err != nil {
+		t.Fatalf("GetConfigDir() ошибка: %v", err)
+	}
+	return base
+}
+
+func TestValidateProfileName(t *testing.T) {
+	valid := []string{"work", "personal-2", "A_b", "x"}
+	for _, name := range valid {
+		if err := ValidateProfileName(name); err != nil {
+			t.Errorf("ValidateProfileName(%q) ошибка: %v", name, err)
+		}
+	}
+
+	invalid := []string{"", "-work", "../etc", "a b", "профиль", "toolongnametoolongnametoolongname1"}
+	for _, name := range invalid {
+		if err := ValidateProfileName(name); err == nil || err.Error() != "profile_name_invalid" {
+			t.Errorf("ValidateProfileName(%q) ошибка = %v, ожидается profile_name_invalid", name, err)
+		}
+	}
+}
+
+func TestProfileLifecycle(t *testing.T) {
+	base := useTempConfigDir(t)
+	messages := &i18n.Messages{}
+
+	work := DefaultConfig()
+	work.Username = "alice"
+	work.ArgonMemory = 64 * 1024
+	work.ProfileStats.PasswordsGenerated = 10
+	if err := CreateProfile("work", work, messages); err != nil {
+		t.Fatalf("CreateProfile() ошибка: %v", err)
+	}
+	if err := CreateProfile("work", work, messages); err == nil || err.Error() != "profile_exists" {
+		t.Errorf("Повторный CreateProfile() ошибка = %v, ожидается profile_exists", err)
+	}
+
+	names, err := Profiles()
+	if err != nil {
+		t.Fatalf("Profiles() ошибка: %v", err)
+	}
+	if len(names) != 2 || names[0] != DefaultProfile || names[1] != "work" {
+		t.Errorf("Profiles() = %v, ожидается [default work]", names)
+	}
+
+	if err := UseProfile("work"); err != nil {
+		t.Fatalf("UseProfile() ошибка: %v", err)
+	}
+	path, err := GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath() ошибка: %v", err)
+	}
+	if path != filepath.Join(base, "profiles", "work", "config.json") {
+		t.Errorf("GetConfigPath() = %q, ожидается файл в каталоге профиля", path)
+	}
+
+	loaded, err := Load(messages)
+	if err != nil {
+		t.Fatalf("Load() ошибка: %v", err)
+	}
+	if loaded.Username != "alice" || loaded.ArgonMemory != 64*1024 {
+		t.Errorf("Настройки профиля не сохранены: %q, %d", loaded.Username, loaded.ArgonMemory)
+	}
+	if loaded.ProfileStats.CurrentProfile != "work" || loaded.ProfileStats.PasswordsGenerated != 0 {
+		t.Errorf("Статистика нового профиля = %+v, ожидается пустая статистика work", loaded.ProfileStats)
+	}
+
+	if err := SetActiveProfile("work"); err != nil {
+		t.Fatalf("SetActiveProfile() ошибка: %v", err)
+	}
+	if active, _ := ActiveProfile(); active != "work" {
+		t.Errorf("ActiveProfile() = %q, ожидается work", active)
+	}
+	if err := RemoveProfile("work"); err == nil || err.Error() != "profile_active" {
+		t.Errorf("RemoveProfile() активного профиля ошибка = %v, ожидается profile_active", err)
+	}
+
+	if err := RenameProfile("work", "job", messages); err != nil {
+		t.Fatalf("RenameProfile() ошибка: %v", err)
+	}
+	if active, _ := ActiveProfile(); active != "job" {
+		t.Errorf("ActiveProfile() после переименования = %q, ожидается job", active)
+	}
+	renamed, err := LoadProfile("job", messages)
+	if err != nil || renamed.Username != "alice" {
+		t.Errorf("LoadProfile(job) = %+v, %v", renamed, err)
+	}
+
+	if err := SetActiveProfile(DefaultProfile); err != nil {
+		t.Fatalf("SetActiveProfile(default) ошибка: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, activeProfileFile)); !os.IsNotExist(err) {
+		t.Error("Для профиля default файл активного профиля должен удаляться")
+	}
+	if err := RemoveProfile("job"); err != nil {
+		t.Fatalf("RemoveProfile() ошибка: %v", err)
+	}
+	if ProfileExists("job") {
+		t.Error("Профиль job не удалён")
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	useTempConfigDir(t)
+	messages := &i18n.Messages{}
+
+	if err := UseProfile("missing"); err == nil || err.Error() != "profile_not_found" {
+		t.Errorf("UseProfile() ошибка = %v, ожидается profile_not_found", err)
+	}
+	if err := RemoveProfile(DefaultProfile); err == nil || err.Error() != "profile_default" {
+		t.Errorf("RemoveProfile(default) ошибка = %v, ожидается profile_default", err)
+	}
+	if err := RenameProfile(DefaultProfile, "other", messages); err == nil || err.Error() != "profile_default" {
+		t.Errorf("RenameProfile(default) ошибка = %v, ожидается profile_default", err)
+	}
+	if err := CreateProfile("../x", DefaultConfig(), messages); err == nil || err.Error() != "profile_name_invalid" {
+		t.Errorf("CreateProfile() ошибка = %v, ожидается profile_name_invalid", err)
+	}
+	if CurrentProfile() != DefaultProfile {
+		t.Errorf("CurrentProfile() = %q после ошибок, ожидается default", CurrentProfile())
+	}
+}
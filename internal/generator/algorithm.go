@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+const (
+	AlgorithmV1 = "v1" // исходный алгоритм, заморожен: от него зависят уже выданные пароли
+	AlgorithmV2 = "v2" // Argon2id → HKDF-SHA256
+	AlgorithmV3 = "v3" // Argon2id один раз на мастер-ключ, затем HMAC-SHA256 на сайт и HKDF-SHA256
+	AlgorithmV4 = "v4" // v2 с нормализацией Unicode мастер-пароля, имени пользователя и сервиса
+
+	// DefaultAlgorithmName алгоритм для новых установок
+	DefaultAlgorithmName = AlgorithmV2
+
+	// Минимальный размер ключа Argon2 для v2, используемого как PRK для HKDF
+	minAlgorithmV2KeyLen = sha256.Size
+)
+
+// Назначения ключевого материала. Они разделяют домены потоков:
+// в v1 это метки "PGenCLI|<назначение>|", в v2 - info строки HKDF.
+const (
+	purposePassword   = "password"
+	purposeRequire    = "require"
+	purposePassphrase = "passphrase"
+	purposeTemplate   = "template"
+)
+
+// Algorithm версия алгоритма, превращающего хеш Argon2 в пароль.
+// Реализации не меняются после выпуска: новая логика - новая версия.
+type Algorithm interface {
+	// Name возвращает имя версии для конфигурации и флага --algorithm
+	Name() string
+
+	// deriveKey получает ключ сайта из мастер-пароля
+	deriveKey(pg *PasswordGenerator, masterPassword *security.SecureString, serviceName, username string) ([]byte, error)
+	// password отображает хеш на символы набора генератора
+	password(pg *PasswordGenerator, hash []byte) ([]byte, error)
+	// stream возвращает поток чисел для назначения
+	stream(hash []byte, purpose string) *hashStream
+	// material возвращает size байт ключевого материала для назначения
+	material(hash []byte, purpose string, size int) ([]byte, error)
+}
+
+var algorithmRegistry = map[string]Algorithm{
+	AlgorithmV1: algorithmV1{},
+	AlgorithmV2: algorithmV2{},
+	AlgorithmV3: algorithmV3{},
+	AlgorithmV4: algorithmV4{},
+}
+
+// AlgorithmNames возвращает доступные версии алгоритма
+func AlgorithmNames() []string {
+	return []string{AlgorithmV1, AlgorithmV2, AlgorithmV3, AlgorithmV4}
+}
+
+// ResolveAlgorithm возвращает алгоритм по имени версии
+func ResolveAlgorithm(name string) (Algorithm, error) {
+	algorithm, ok := algorithmRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("algorithm_unknown")
+	}
+	return algorithm, nil
+}
+
+// algorithmV1 исходный алгоритм: хеш расходуется целиком через big.Int,
+// при нехватке битов продлевается цепочкой sha256(hash || password)
+type algorithmV1 struct{}
+
+func (algorithmV1) Name() string { return AlgorithmV1 }
+
+func (a algorithmV1) deriveKey(pg *PasswordGenerator, masterPassword *security.SecureString, serviceName, username string) ([]byte, error) {
+	salt := createSalt(serviceName, username, pg.counter)
+	return pg.argonHash(masterPassword, salt, a.keyLen(pg, pg.argonParams().KeyLen)), nil
+}
+
+func (algorithmV1) keyLen(pg *PasswordGenerator, configured uint32) uint32 {
+	keyLen := configured
+	// Длина фразы и шаблона не зависит от --length, поэтому и размер хеша для них не меняется
+	if pg.Mode() == ModePassword && pg.length > int(configured) {
+		keyLen = uint32(pg.length * 2) // Удваиваем для запаса
+	}
+	if pg.Mode() == ModeTemplate && keyLen < minTemplateHashSize {
+		keyLen = minTemplateHashSize
+	}
+	return keyLen
+}
+
+func (algorithmV1) password(pg *PasswordGenerator, hash []byte) ([]byte, error) {
+	return []byte(pg.generateFromHash(hash)), nil
+}
+
+func (algorithmV1) stream(hash []byte, purpose string) *hashStream {
+	return newHashStream("PGenCLI|"+purpose+"|", hash)
+}
+
+func (algorithmV1) material(hash []byte, purpose string, size int) ([]byte, error) {
+	// Шаблоны v1 читают байты хеша напрямую
+	if len(hash) < size {
+		return nil, fmt.Errorf("hash_too_short")
+	}
+	return hash[:size], nil
+}
+
+// algorithmV2 расширяет хеш Argon2 через HKDF-SHA256 с отдельной info строкой
+// для каждого назначения, поэтому длина пароля не влияет на параметры Argon2
+type algorithmV2 struct{}
+
+func (algorithmV2) Name() string { return AlgorithmV2 }
+
+func (a algorithmV2) deriveKey(pg *PasswordGenerator, masterPassword *security.SecureString, serviceName, username string) ([]byte, error) {
+	salt := createSalt(serviceName, username, pg.counter)
+	return pg.argonHash(masterPassword, salt, a.keyLen(pg, pg.argonParams().KeyLen)), nil
+}
+
+func (algorithmV2) keyLen(pg *PasswordGenerator, configured uint32) uint32 {
+	if configured < minAlgorithmV2KeyLen {
+		return minAlgorithmV2KeyLen
+	}
+	return configured
+}
+
+func (algorithmV2) password(pg *PasswordGenerator, hash []byte) ([]byte, error) {
+	return hkdfPassword(pg, hash, algorithmV2Info)
+}
+
+func (algorithmV2) stream(hash []byte, purpose string) *hashStream {
+	return hkdfStream(hash, algorithmV2Info+purpose)
+}
+
+func (algorithmV2) material(hash []byte, purpose string, size int) ([]byte, error) {
+	return hkdf.Expand(sha256.New, hash, algorithmV2Info+purpose, size)
+}
+
+// algorithmV3 двухэтапный алгоритм: Argon2id выполняется один раз над мастер-паролем
+// и именем пользователя, а ключ сайта - HMAC-SHA256(мастер-ключ, service|counter|type).
+// Ключ сайта расширяется через HKDF-SHA256, как в v2.
+type algorithmV3 struct{}
+
+func (algorithmV3) Name() string { return AlgorithmV3 }
+
+func (algorithmV3) deriveKey(pg *PasswordGenerator, masterPassword *security.SecureString, serviceName, username string) ([]byte, error) {
+	masterKey := pg.DeriveMasterKey(masterPassword, username)
+	defer masterKey.Clear()
+	return masterKey.siteKey(serviceName, pg.counter, pg.outputType()), nil
+}
+
+func (algorithmV3) password(pg *PasswordGenerator, hash []byte) ([]byte, error) {
+	return hkdfPassword(pg, hash, algorithmV3Info)
+}
+
+func (algorithmV3) stream(hash []byte, purpose string) *hashStream {
+	return hkdfStream(hash, algorithmV3Info+purpose)
+}
+
+func (algorithmV3) material(hash []byte, purpose string, size int) ([]byte, error) {
+	return hkdf.Expand(sha256.New, hash, algorithmV3Info+purpose, size)
+}
+
+// algorithmV4 приводит мастер-пароль и имя пользователя к NFC, а имя сервиса -
+// к канонической форме (CanonicalService), после чего работает как v2. Пароли
+// перестают зависеть от того, в какой форме Unicode ОС или раскладка передали
+// текст. Для ввода, уже находящегося в канонической форме, пароли совпадают с v2.
+type algorithmV4 struct {
+	algorithmV2
+}
+
+func (algorithmV4) Name() string { return AlgorithmV4 }
+
+func (a algorithmV4) deriveKey(pg *PasswordGenerator, masterPassword *security.SecureString, serviceName, username string) ([]byte, error) {
+	service, err := CanonicalService(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	normalized := normalizeSecret(masterPassword)
+	defer normalized.Clear()
+	return a.algorithmV2.deriveKey(pg, normalized, service, norm.NFC.String(username))
+}
+
+// Префиксы info строк HKDF, за ними следует назначение
+const (
+	algorithmV2Info = "PGenCLI|v2|"
+	algorithmV3Info = "PGenCLI|v3|"
+)
+
+// hkdfPassword отображает материал HKDF на символы набора генератора
+func hkdfPassword(pg *PasswordGenerator, hash []byte, infoPrefix string) ([]byte, error) {
+	// По два байта на символ: смещение распределения пренебрежимо мало
+	material, err := hkdf.Expand(sha256.New, hash, infoPrefix+purposePassword, pg.length*2)
+	if err != nil {
+		return nil, err
+	}
+	defer security.ZeroMemory(material)
+
+	alphabet := pg.charset.Alphabet
+	alphabetLen := big.NewInt(int64(len(alphabet)))
+	value := new(big.Int).SetBytes(material)
+	defer value.SetInt64(0)
+
+	password := make([]byte, pg.length)
+	remainder := new(big.Int)
+	for i := range password {
+		value.DivMod(value, alphabetLen, remainder)
+		password[i] = alphabet[remainder.Int64()]
+	}
+	return password, nil
+}
+
+// hkdfStream поток, каждый блок которого - отдельный вызов HKDF с номером блока в info
+func hkdfStream(hash []byte, info string) *hashStream {
+	block := 0
+	return newStream(func() [sha256.Size]byte {
+		var out [sha256.Size]byte
+		expanded, err := hkdf.Expand(sha256.New, hash, fmt.Sprintf("%s|%d", info, block), sha256.Size)
+		if err != nil {
+			// HKDF-Expand ошибается только при запросе больше 255 блоков за раз
+			panic(err)
+		}
+		copy(out[:], expanded)
+		security.ZeroMemory(expanded)
+		block++
+		return out
+	})
+}
+
+// SetAlgorithm задаёт версию алгоритма генерации
+func (pg *PasswordGenerator) SetAlgorithm(algorithm Algorithm) {
+	pg.algorithm = algorithm
+}
+
+// Algorithm возвращает версию алгоритма генерации, по умолчанию v1
+func (pg *PasswordGenerator) Algorithm() Algorithm {
+	if pg.algorithm == nil {
+		return algorithmV1{}
+	}
+	return pg.algorithm
+}
@@ -0,0 +1,221 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// algorithmCase набор параметров генератора для проверки известных ответов
+type algorithmCase struct {
+	name  string
+	setup func(t *testing.T) *PasswordGenerator
+}
+
+func algorithmCases() []algorithmCase {
+	fast := ArgonConfig{Time: 1, Memory: 64 * 1024, Threads: 1, KeyLen: 32}
+	short := fast
+	short.KeyLen = 16 // 128 бит хеша не хватает на 24 символа, v1 продлевает хеш
+
+	return []algorithmCase{
+		{"Пароль по умолчанию", func(t *testing.T) *PasswordGenerator {
+			return NewPasswordGeneratorWithConfig(16, fast, DefaultCharset())
+		}},
+		{"Продление хеша", func(t *testing.T) *PasswordGenerator {
+			return NewPasswordGeneratorWithConfig(24, short, DefaultCharset())
+		}},
+		{"Длинный пароль", func(t *testing.T) *PasswordGenerator {
+			return NewPasswordGeneratorWithConfig(64, fast, DefaultCharset())
+		}},
+		{"Пользовательский алфавит", func(t *testing.T) *PasswordGenerator {
+			digits, err := ResolveCharset("custom:0123456789")
+			if err != nil {
+				t.Fatalf("ResolveCharset() ошибка: %v", err)
+			}
+			return NewPasswordGeneratorWithConfig(12, fast, digits)
+		}},
+		{"Счётчик", func(t *testing.T) *PasswordGenerator {
+			pg := NewPasswordGeneratorWithConfig(16, fast, DefaultCharset())
+			pg.SetCounter(2)
+			return pg
+		}},
+		{"Парольная фраза", func(t *testing.T) *PasswordGenerator {
+			pg := NewPasswordGeneratorWithConfig(16, fast, DefaultCharset())
+			pg.SetMode(ModePassphrase)
+			return pg
+		}},
+		{"Русская парольная фраза", func(t *testing.T) *PasswordGenerator {
+			pg := NewPasswordGeneratorWithConfig(16, fast, DefaultCharset())
+			pg.SetMode(ModePassphrase)
+			opts := PassphraseOptions{Words: 4, Separator: " ", Capitalize: true, Digit: true, Wordlist: WordlistRussian}
+			if err := pg.SetPassphraseOptions(opts); err != nil {
+				t.Fatalf("SetPassphraseOptions() ошибка: %v", err)
+			}
+			return pg
+		}},
+		{"Шаблон long", func(t *testing.T) *PasswordGenerator {
+			pg := NewPasswordGeneratorWithConfig(16, fast, DefaultCharset())
+			pg.SetMode(ModeTemplate)
+			_ = pg.SetPasswordType(TypeLong)
+			return pg
+		}},
+		{"Шаблон pin", func(t *testing.T) *PasswordGenerator {
+			pg := NewPasswordGeneratorWithConfig(16, fast, DefaultCharset())
+			pg.SetMode(ModeTemplate)
+			_ = pg.SetPasswordType(TypePIN)
+			return pg
+		}},
+	}
+}
+
+func runAlgorithmKnownAnswers(t *testing.T, algorithm Algorithm, service string, expected map[string]string) {
+	t.Helper()
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	for _, tc := range algorithmCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			pg := tc.setup(t)
+			pg.SetAlgorithm(algorithm)
+
+			password, err := pg.GeneratePassword(masterPassword, service, "testuser")
+			if err != nil {
+				t.Fatalf("GeneratePassword() ошибка: %v", err)
+			}
+			defer password.Clear()
+
+			if password.String() != expected[tc.name] {
+				t.Errorf("GeneratePassword() = %q, ожидается %q", password.String(), expected[tc.name])
+			}
+		})
+	}
+}
+
+func TestAlgorithmV1KnownAnswers(t *testing.T) {
+	// Значения зафиксированы: любое изменение здесь меняет уже выданные пароли
+	runAlgorithmKnownAnswers(t, algorithmV1{}, "github.com", map[string]string{
+		"Пароль по умолчанию":      "Y|o3ku[p<_WSL+v1",
+		"Продление хеша":           "PtPO_oBO|;g*SLv%u2r2cR0o",
+		"Длинный пароль":           "l<UBMY2(l|)b}w9*}(0tZpm]?@u=0Lyk&Ovmc3$p*%q+T3)R{}Nx:],-^CnSv9{v",
+		"Пользовательский алфавит": "004949883779",
+		"Счётчик":                  "7mlxXH[A5eJ@hHEV",
+		"Парольная фраза":          "equinox-ashy-dollhouse-fountain-tracing-antarctic",
+		"Русская парольная фраза":  "Корова Река Бедро3 Облако",
+		"Шаблон long":              "JumvWigeCezb6@",
+		"Шаблон pin":               "9459",
+	})
+}
+
+func TestAlgorithmV2KnownAnswers(t *testing.T) {
+	runAlgorithmKnownAnswers(t, algorithmV2{}, "github.com", map[string]string{
+		"Пароль по умолчанию":      "xc-J%=P>JViVn}C|",
+		"Продление хеша":           "&O.AR{nD^5vk&+Cw#L=baBKt",
+		"Длинный пароль":           "JZY-yx=nGSfOmb|jHgZ%zCm(Yo*1dP)[RMa@<p*ccC<0;p9)#V++8sGFN5;,2-J]",
+		"Пользовательский алфавит": "532193816678",
+		"Счётчик":                  "ITU9%,*vO!s8y^L[",
+		"Парольная фраза":          "ditch-unskilled-sneak-pesky-capped-spherical",
+		"Русская парольная фраза":  "Бригада Вихрь3 Алфавит Бык",
+		"Шаблон long":              "YumaVegu2:Cuto",
+		"Шаблон pin":               "0940",
+	})
+}
+
+func TestAlgorithmV3KnownAnswers(t *testing.T) {
+	runAlgorithmKnownAnswers(t, algorithmV3{}, "github.com", map[string]string{
+		"Пароль по умолчанию":      "l}MfZsr^nRQ4XlaF",
+		"Продление хеша":           "8oZ02!!$7,s$9PX@a#8ND:L>",
+		"Длинный пароль":           "DSp*y|J8=?i8}dOE$dshG5U5!:NWV=G<X8{NO{u&?Yb(eY@Cs#Li*}{j4-u&RZL2",
+		"Пользовательский алфавит": "406055868323",
+		"Счётчик":                  "dB$}<JOxWgH)((y0",
+		"Парольная фраза":          "amply-stumbling-rhyme-refinance-antarctic-spotter",
+		"Русская парольная фраза":  "Сугроб Честь Плато Перо0",
+		"Шаблон long":              "ZisiXerzYuth4&",
+		"Шаблон pin":               "1083",
+	})
+}
+
+func TestAlgorithmV1RequiredClassesKnownAnswer(t *testing.T) {
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	pg := NewPasswordGeneratorWithConfig(6, ArgonConfig{Time: 1, Memory: 64 * 1024, Threads: 1, KeyLen: 32}, DefaultCharset())
+	if err := pg.SetRequiredClasses(allClasses); err != nil {
+		t.Fatalf("SetRequiredClasses() ошибка: %v", err)
+	}
+
+	// Без обязательных классов пароль "D?-BK!", подставляются строчная буква и цифра
+	password, err := pg.GeneratePassword(masterPassword, "c", "testuser")
+	if err != nil {
+		t.Fatalf("GeneratePassword() ошибка: %v", err)
+	}
+	defer password.Clear()
+
+	if password.String() != "Dk-BK7" {
+		t.Errorf("GeneratePassword() = %q, ожидается %q", password.String(), "Dk-BK7")
+	}
+}
+
+func TestResolveAlgorithm(t *testing.T) {
+	for _, name := range AlgorithmNames() {
+		algorithm, err := ResolveAlgorithm(name)
+		if err != nil {
+			t.Fatalf("ResolveAlgorithm(%q) ошибка: %v", name, err)
+		}
+		if algorithm.Name() != name {
+			t.Errorf("ResolveAlgorithm(%q).Name() = %q", name, algorithm.Name())
+		}
+	}
+
+	if _, err := ResolveAlgorithm("v0"); err == nil || err.Error() != "algorithm_unknown" {
+		t.Errorf("ResolveAlgorithm(\"v0\") ошибка = %v, ожидается algorithm_unknown", err)
+	}
+}
+
+func TestAlgorithmDefault(t *testing.T) {
+	// Генератор без явной версии сохраняет поведение v1
+	pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+	if pg.Algorithm().Name() != AlgorithmV1 {
+		t.Errorf("Algorithm() = %q, ожидается %q", pg.Algorithm().Name(), AlgorithmV1)
+	}
+}
+
+func TestAlgorithmV2KeyLen(t *testing.T) {
+	pg := NewPasswordGeneratorWithConfig(128, ArgonConfig{}, DefaultCharset())
+
+	// v2 не увеличивает хеш Argon2 под длину пароля, а расширяет его через HKDF
+	if got := (algorithmV2{}).keyLen(pg, 32); got != 32 {
+		t.Errorf("keyLen() = %d, ожидается 32", got)
+	}
+	if got := (algorithmV2{}).keyLen(pg, 16); got != minAlgorithmV2KeyLen {
+		t.Errorf("keyLen() = %d, ожидается %d", got, minAlgorithmV2KeyLen)
+	}
+	if got := (algorithmV1{}).keyLen(pg, 32); got != 256 {
+		t.Errorf("keyLen() v1 = %d, ожидается 256", got)
+	}
+}
+
+func TestAlgorithmV2DomainSeparation(t *testing.T) {
+	hash := make([]byte, 32)
+	for i := range hash {
+		hash[i] = byte(i)
+	}
+
+	password, err := (algorithmV2{}).material(hash, purposePassword, 32)
+	if err != nil {
+		t.Fatalf("material() ошибка: %v", err)
+	}
+	template, err := (algorithmV2{}).material(hash, purposeTemplate, 32)
+	if err != nil {
+		t.Fatalf("material() ошибка: %v", err)
+	}
+	if string(password) == string(template) {
+		t.Error("Ключевой материал разных назначений совпадает")
+	}
+
+	stream := (algorithmV2{}).stream(hash, purposePassword)
+	defer stream.wipe()
+	if stream.state == [32]byte(password) {
+		t.Error("Поток и материал пароля не разделены по доменам")
+	}
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// Именованные наборы символов (значения character_set в конфигурации)
+	CharsetNameAlphaNum    = "alphanumeric"
+	CharsetNameFull        = "alphanumeric_symbols"
+	CharsetNameSymbolsOnly = "symbols_only"
+	CharsetNameNoAmbiguous = "no_ambiguous"
+	DefaultCharsetName     = CharsetNameFull
+	customCharsetPrefix    = "custom:"
+	excludeCharsetPrefix   = "exclude:"
+	ambiguousChars         = "0O1lI"
+	minCharsetSize         = 2
+)
+
+// Charset набор символов, из которого строится пароль
+type Charset struct {
+	Name     string
+	Alphabet string
+}
+
+// Size возвращает размер алфавита
+func (c Charset) Size() int {
+	return len(c.Alphabet)
+}
+
+// реестр именованных наборов; порядок символов влияет на результат генерации
+var charsetRegistry = map[string]string{
+	CharsetNameAlphaNum:    charsetAlphaNum,
+	CharsetNameFull:        charsetFull,
+	CharsetNameSymbolsOnly: charsetSymbols,
+	CharsetNameNoAmbiguous: removeChars(charsetFull, ambiguousChars),
+}
+
+// DefaultCharset возвращает набор символов по умолчанию
+func DefaultCharset() Charset {
+	return Charset{Name: DefaultCharsetName, Alphabet: charsetFull}
+}
+
+// CharsetNames возвращает отсортированный список именованных наборов
+func CharsetNames() []string {
+	names := make([]string, 0, len(charsetRegistry))
+	for name := range charsetRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveCharset разбирает значение character_set:
+// имя из реестра, "custom:<символы>" или "exclude:<символы>"
+func ResolveCharset(spec string) (Charset, error) {
+	switch {
+	case strings.HasPrefix(spec, customCharsetPrefix):
+		alphabet, err := normalizeAlphabet(strings.TrimPrefix(spec, customCharsetPrefix))
+		if err != nil {
+			return Charset{}, err
+		}
+		return Charset{Name: spec, Alphabet: alphabet}, nil
+	case strings.HasPrefix(spec, excludeCharsetPrefix):
+		alphabet, err := normalizeAlphabet(removeChars(charsetFull, strings.TrimPrefix(spec, excludeCharsetPrefix)))
+		if err != nil {
+			return Charset{}, err
+		}
+		return Charset{Name: spec, Alphabet: alphabet}, nil
+	}
+
+	alphabet, ok := charsetRegistry[spec]
+	if !ok {
+		return Charset{}, fmt.Errorf("charset_unknown")
+	}
+	return Charset{Name: spec, Alphabet: alphabet}, nil
+}
+
+// normalizeAlphabet убирает повторы, сохраняя порядок, и проверяет допустимость символов
+func normalizeAlphabet(chars string) (string, error) {
+	seen := make(map[rune]bool, len(chars))
+	var b strings.Builder
+	for _, char := range chars {
+		// Только печатные ASCII без пробела: один символ пароля = один байт
+		if char < '!' || char > '~' {
+			return "", fmt.Errorf("charset_invalid_char")
+		}
+		if seen[char] {
+			continue
+		}
+		seen[char] = true
+		b.WriteRune(char)
+	}
+	if b.Len() < minCharsetSize {
+		return "", fmt.Errorf("charset_too_small")
+	}
+	return b.String(), nil
+}
+
+// removeChars удаляет из алфавита указанные символы
+func removeChars(alphabet, chars string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(chars, r) {
+			return -1
+		}
+		return r
+	}, alphabet)
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+func TestResolveCharset(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         string
+		expectedSize int
+		wantError    bool
+	}{
+		{"Буквы и цифры", "alphanumeric", 62, false},
+		{"Полный набор", "alphanumeric_symbols", 88, false},
+		{"Только спецсимволы", "symbols_only", 26, false},
+		{"Без неоднозначных символов", "no_ambiguous", 83, false},
+		{"Пользовательский алфавит", "custom:0123456789", 10, false},
+		{"Пользовательский алфавит с повторами", "custom:aabbcc", 3, false},
+		{"Исключение символов", "exclude:0O1lI", 83, false},
+		{"Неизвестное имя", "unknown", 0, true},
+		{"Пустое значение", "", 0, true},
+		{"Пустой пользовательский алфавит", "custom:", 0, true},
+		{"Один символ", "custom:aaa", 0, true},
+		{"Пробел в алфавите", "custom:ab c", 0, true},
+		{"Не ASCII символы", "custom:абв", 0, true},
+		{"Исключены все символы", "exclude:" + charsetFull, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charset, err := ResolveCharset(tt.spec)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("ResolveCharset(%q) ожидалась ошибка", tt.spec)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ResolveCharset(%q) неожиданная ошибка: %v", tt.spec, err)
+			}
+			if charset.Size() != tt.expectedSize {
+				t.Errorf("ResolveCharset(%q) размер = %d, ожидается %d", tt.spec, charset.Size(), tt.expectedSize)
+			}
+			if charset.Name != tt.spec {
+				t.Errorf("ResolveCharset(%q) имя = %q", tt.spec, charset.Name)
+			}
+		})
+	}
+}
+
+func TestResolveCharsetExcludesAmbiguous(t *testing.T) {
+	charset, err := ResolveCharset("no_ambiguous")
+	if err != nil {
+		t.Fatalf("ResolveCharset() ошибка: %v", err)
+	}
+
+	if strings.ContainsAny(charset.Alphabet, ambiguousChars) {
+		t.Errorf("Набор no_ambiguous содержит неоднозначные символы: %s", charset.Alphabet)
+	}
+}
+
+func TestDefaultCharsetUnchanged(t *testing.T) {
+	// Порядок символов по умолчанию определяет уже сгенерированные пароли
+	if DefaultCharset().Alphabet != charsetFull {
+		t.Error("Набор символов по умолчанию должен совпадать с charsetFull")
+	}
+
+	charset, err := ResolveCharset(DefaultCharsetName)
+	if err != nil {
+		t.Fatalf("ResolveCharset() ошибка: %v", err)
+	}
+	if charset != DefaultCharset() {
+		t.Error("ResolveCharset(DefaultCharsetName) должен совпадать с DefaultCharset()")
+	}
+}
+
+func TestCharsetNames(t *testing.T) {
+	names := CharsetNames()
+	if len(names) != len(charsetRegistry) {
+		t.Fatalf("CharsetNames() вернул %d имен, ожидается %d", len(names), len(charsetRegistry))
+	}
+
+	for _, name := range names {
+		if _, err := ResolveCharset(name); err != nil {
+			t.Errorf("ResolveCharset(%q) ошибка: %v", name, err)
+		}
+	}
+}
+
+func TestGeneratePasswordUsesCharset(t *testing.T) {
+	config := ArgonConfig{
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 1,
+		KeyLen:  32,
+	}
+
+	specs := []string{"alphanumeric", "symbols_only", "no_ambiguous", "custom:0123456789"}
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	for _, spec := range specs {
+		t.Run(spec, func(t *testing.T) {
+			charset, err := ResolveCharset(spec)
+			if err != nil {
+				t.Fatalf("ResolveCharset() ошибка: %v", err)
+			}
+
+			pg := NewPasswordGeneratorWithConfig(24, config, charset)
+			password, err := pg.GeneratePassword(masterPassword, "github.com", "testuser")
+			if err != nil {
+				t.Fatalf("GeneratePassword() ошибка: %v", err)
+			}
+			defer password.Clear()
+
+			if password.Len() != 24 {
+				t.Errorf("Длина пароля = %d, ожидается 24", password.Len())
+			}
+
+			for _, char := range password.String() {
+				if !strings.ContainsRune(charset.Alphabet, char) {
+					t.Errorf("Пароль содержит символ %q вне набора %s", char, spec)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPasswordGeneratorWithConfigEmptyCharset(t *testing.T) {
+	pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, Charset{})
+
+	if pg.Charset() != DefaultCharset() {
+		t.Error("Пустой набор символов должен заменяться набором по умолчанию")
+	}
+}
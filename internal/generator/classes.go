@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// CharClass класс символов пароля
+type CharClass string
+
+const (
+	ClassUpper  CharClass = "upper"
+	ClassLower  CharClass = "lower"
+	ClassDigit  CharClass = "digit"
+	ClassSymbol CharClass = "symbol"
+)
+
+// Каноничный порядок классов: от него зависит результат, а не от порядка во флаге
+var allClasses = []CharClass{ClassUpper, ClassLower, ClassDigit, ClassSymbol}
+
+// ParseRequiredClasses разбирает список классов через запятую ("upper,digit,symbol").
+// Пустая строка и "none" означают отсутствие требований.
+func ParseRequiredClasses(spec string) ([]CharClass, error) {
+	spec = strings.TrimSpace(strings.ToLower(spec))
+	if spec == "" || spec == "none" {
+		return nil, nil
+	}
+
+	requested := make(map[CharClass]bool)
+	for _, part := range strings.Split(spec, ",") {
+		class := CharClass(strings.TrimSpace(part))
+		if !isKnownClass(class) {
+			return nil, fmt.Errorf("required_class_unknown")
+		}
+		requested[class] = true
+	}
+
+	classes := make([]CharClass, 0, len(requested))
+	for _, class := range allClasses {
+		if requested[class] {
+			classes = append(classes, class)
+		}
+	}
+	return classes, nil
+}
+
+// FormatRequiredClasses возвращает каноничную строку для хранения в конфигурации
+func FormatRequiredClasses(classes []CharClass) string {
+	parts := make([]string, len(classes))
+	for i, class := range classes {
+		parts[i] = string(class)
+	}
+	return strings.Join(parts, ",")
+}
+
+// SetRequiredClasses задаёт классы, которые обязательно должны быть в каждом пароле
+func (pg *PasswordGenerator) SetRequiredClasses(classes []CharClass) error {
+	if len(classes) > pg.length {
+		return fmt.Errorf("required_classes_too_many")
+	}
+	for _, class := range classes {
+		if len(classChars(pg.charset.Alphabet, class)) == 0 {
+			return fmt.Errorf("required_class_unavailable")
+		}
+	}
+	pg.required = classes
+	return nil
+}
+
+// RequiredClasses возвращает обязательные классы генератора
+func (pg *PasswordGenerator) RequiredClasses() []CharClass {
+	return pg.required
+}
+
+// enforceRequiredClasses детерминированно дополняет пароль недостающими классами.
+// Позиции и символы берутся из потока, производного от хеша Argon2, поэтому
+// повторный запуск всегда даёт тот же результат. Пароль, уже содержащий все
+// классы, не изменяется.
+func (pg *PasswordGenerator) enforceRequiredClasses(password, hash []byte) {
+	if len(pg.required) == 0 {
+		return
+	}
+
+	counts := make(map[CharClass]int, len(allClasses))
+	for _, char := range password {
+		counts[classOf(char)]++
+	}
+
+	stream := pg.Algorithm().stream(hash, purposeRequire)
+	defer stream.wipe()
+
+	for _, class := range pg.required {
+		if counts[class] > 0 {
+			continue
+		}
+
+		// Заменяем только те позиции, потеря которых не лишит пароль другого обязательного класса
+		candidates := make([]int, 0, len(password))
+		for i, char := range password {
+			current := classOf(char)
+			if !pg.isRequired(current) || counts[current] > 1 {
+				candidates = append(candidates, i)
+			}
+		}
+
+		pool := classChars(pg.charset.Alphabet, class)
+		pos := candidates[stream.next(len(candidates))]
+
+		counts[classOf(password[pos])]--
+		password[pos] = pool[stream.next(len(pool))]
+		counts[class]++
+	}
+}
+
+func (pg *PasswordGenerator) isRequired(class CharClass) bool {
+	for _, required := range pg.required {
+		if required == class {
+			return true
+		}
+	}
+	return false
+}
+
+func isKnownClass(class CharClass) bool {
+	for _, known := range allClasses {
+		if known == class {
+			return true
+		}
+	}
+	return false
+}
+
+// classOf определяет класс ASCII символа
+func classOf(char byte) CharClass {
+	switch {
+	case char >= 'A' && char <= 'Z':
+		return ClassUpper
+	case char >= 'a' && char <= 'z':
+		return ClassLower
+	case char >= '0' && char <= '9':
+		return ClassDigit
+	default:
+		return ClassSymbol
+	}
+}
+
+// classChars возвращает символы алфавита, относящиеся к классу, в порядке алфавита
+func classChars(alphabet string, class CharClass) []byte {
+	chars := make([]byte, 0, len(alphabet))
+	for i := 0; i < len(alphabet); i++ {
+		if classOf(alphabet[i]) == class {
+			chars = append(chars, alphabet[i])
+		}
+	}
+	return chars
+}
+
+// hashStream детерминированный источник чисел, производный от хеша
+type hashStream struct {
+	state  [sha256.Size]byte
+	value  *big.Int
+	refill func() [sha256.Size]byte
+}
+
+// newStream создаёт поток, блоки которого выдаёт refill
+func newStream(refill func() [sha256.Size]byte) *hashStream {
+	s := &hashStream{refill: refill}
+	s.state = refill()
+	s.value = new(big.Int).SetBytes(s.state[:])
+	return s
+}
+
+// newHashStream поток v1: первый блок sha256(label || seed), далее цепочка SHA256
+func newHashStream(label string, seed []byte) *hashStream {
+	var s *hashStream
+	first := sha256.Sum256(append([]byte(label), seed...))
+	started := false
+	s = newStream(func() [sha256.Size]byte {
+		if !started {
+			started = true
+			block := first
+			security.ZeroMemory(first[:])
+			return block
+		}
+		return sha256.Sum256(s.state[:])
+	})
+	return s
+}
+
+// next возвращает число в диапазоне [0, n), расходуя биты потока
+func (s *hashStream) next(n int) int {
+	bound := big.NewInt(int64(n))
+	if s.value.Cmp(bound) < 0 {
+		// Биты исчерпаны, продлеваем поток следующим блоком
+		s.state = s.refill()
+		s.value.SetBytes(s.state[:])
+	}
+	remainder := new(big.Int)
+	s.value.DivMod(s.value, bound, remainder)
+	return int(remainder.Int64())
+}
+
+func (s *hashStream) wipe() {
+	security.ZeroMemory(s.state[:])
+	s.value.SetInt64(0)
+}
@@ -0,0 +1,212 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"reflect"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+func TestParseRequiredClasses(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		expected  []CharClass
+		wantError bool
+	}{
+		{"Пустая строка", "", nil, false},
+		{"Без требований", "none", nil, false},
+		{"Один класс", "digit", []CharClass{ClassDigit}, false},
+		{"Каноничный порядок", "symbol,digit,upper", []CharClass{ClassUpper, ClassDigit, ClassSymbol}, false},
+		{"Повторы и пробелы", " digit , DIGIT,lower ", []CharClass{ClassLower, ClassDigit}, false},
+		{"Все классы", "upper,lower,digit,symbol", allClasses, false},
+		{"Неизвестный класс", "upper,emoji", nil, true},
+		{"Пустой элемент", "upper,,digit", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classes, err := ParseRequiredClasses(tt.spec)
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("ParseRequiredClasses(%q) ожидалась ошибка", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRequiredClasses(%q) неожиданная ошибка: %v", tt.spec, err)
+			}
+			if len(classes) != len(tt.expected) || (len(classes) > 0 && !reflect.DeepEqual(classes, tt.expected)) {
+				t.Errorf("ParseRequiredClasses(%q) = %v, ожидается %v", tt.spec, classes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatRequiredClasses(t *testing.T) {
+	classes, _ := ParseRequiredClasses("symbol,upper,digit")
+	if got := FormatRequiredClasses(classes); got != "upper,digit,symbol" {
+		t.Errorf("FormatRequiredClasses() = %q, ожидается %q", got, "upper,digit,symbol")
+	}
+	if got := FormatRequiredClasses(nil); got != "" {
+		t.Errorf("FormatRequiredClasses(nil) = %q, ожидается пустая строка", got)
+	}
+}
+
+func TestSetRequiredClasses(t *testing.T) {
+	alphaNum, _ := ResolveCharset("alphanumeric")
+
+	tests := []struct {
+		name      string
+		length    int
+		charset   Charset
+		classes   []CharClass
+		wantError string
+	}{
+		{"Все классы в полном наборе", 16, DefaultCharset(), allClasses, ""},
+		{"Символы недоступны", 16, alphaNum, []CharClass{ClassSymbol}, "required_class_unavailable"},
+		{"Классов больше длины", 3, DefaultCharset(), allClasses, "required_classes_too_many"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pg := NewPasswordGeneratorWithConfig(tt.length, ArgonConfig{}, tt.charset)
+			err := pg.SetRequiredClasses(tt.classes)
+
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("SetRequiredClasses() неожиданная ошибка: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantError {
+				t.Errorf("SetRequiredClasses() ошибка = %v, ожидается %s", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestEnforceRequiredClasses(t *testing.T) {
+	pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+	if err := pg.SetRequiredClasses(allClasses); err != nil {
+		t.Fatalf("SetRequiredClasses() ошибка: %v", err)
+	}
+
+	hash := sha256.Sum256([]byte("hash"))
+
+	t.Run("Пароль без цифр и символов", func(t *testing.T) {
+		password := []byte("abcdefghABCDEFGH")
+		pg.enforceRequiredClasses(password, hash[:])
+		assertHasClasses(t, password, allClasses)
+
+		// Повторный запуск на тех же данных даёт тот же результат
+		again := []byte("abcdefghABCDEFGH")
+		pg.enforceRequiredClasses(again, hash[:])
+		if string(again) != string(password) {
+			t.Errorf("enforceRequiredClasses() не детерминирован: %s != %s", again, password)
+		}
+	})
+
+	t.Run("Пароль одного класса", func(t *testing.T) {
+		password := []byte("aaaaaaaaaaaaaaaa")
+		pg.enforceRequiredClasses(password, hash[:])
+		assertHasClasses(t, password, allClasses)
+	})
+
+	t.Run("Соответствующий пароль не меняется", func(t *testing.T) {
+		original := "aB3!aB3!aB3!aB3!"
+		password := []byte(original)
+		pg.enforceRequiredClasses(password, hash[:])
+		if string(password) != original {
+			t.Errorf("enforceRequiredClasses() изменил соответствующий пароль: %s", password)
+		}
+	})
+
+	t.Run("Минимальная длина", func(t *testing.T) {
+		short := NewPasswordGeneratorWithConfig(4, ArgonConfig{}, DefaultCharset())
+		if err := short.SetRequiredClasses(allClasses); err != nil {
+			t.Fatalf("SetRequiredClasses() ошибка: %v", err)
+		}
+		for i := 0; i < 200; i++ {
+			seed := sha256.Sum256([]byte{byte(i)})
+			password := []byte("zzzz")
+			short.enforceRequiredClasses(password, seed[:])
+			assertHasClasses(t, password, allClasses)
+		}
+	})
+}
+
+func TestGeneratePasswordWithRequiredClasses(t *testing.T) {
+	config := ArgonConfig{
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 1,
+		KeyLen:  32,
+	}
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	plain := NewPasswordGeneratorWithConfig(8, config, DefaultCharset())
+	required := NewPasswordGeneratorWithConfig(8, config, DefaultCharset())
+	if err := required.SetRequiredClasses(allClasses); err != nil {
+		t.Fatalf("SetRequiredClasses() ошибка: %v", err)
+	}
+
+	services := []string{"github.com", "google.com", "bank", "mail", "work", "shop"}
+	for _, service := range services {
+		t.Run(service, func(t *testing.T) {
+			first, err := required.GeneratePassword(masterPassword, service, "testuser")
+			if err != nil {
+				t.Fatalf("GeneratePassword() ошибка: %v", err)
+			}
+			defer first.Clear()
+
+			second, err := required.GeneratePassword(masterPassword, service, "testuser")
+			if err != nil {
+				t.Fatalf("GeneratePassword() ошибка: %v", err)
+			}
+			defer second.Clear()
+
+			if !first.SecureCompare(second) {
+				t.Errorf("Пароли не детерминированы: %s, %s", first.String(), second.String())
+			}
+			assertHasClasses(t, first.Bytes(), allClasses)
+
+			// Отличия от обычного пароля допустимы только в позициях недостающих классов
+			base, err := plain.GeneratePassword(masterPassword, service, "testuser")
+			if err != nil {
+				t.Fatalf("GeneratePassword() ошибка: %v", err)
+			}
+			defer base.Clear()
+
+			diff := 0
+			for i := range first.Bytes() {
+				if first.Bytes()[i] != base.Bytes()[i] {
+					diff++
+				}
+			}
+			if diff > len(allClasses) {
+				t.Errorf("Изменено %d позиций, ожидается не больше %d", diff, len(allClasses))
+			}
+		})
+	}
+}
+
+func assertHasClasses(t *testing.T, password []byte, classes []CharClass) {
+	t.Helper()
+	for _, class := range classes {
+		found := false
+		for _, char := range password {
+			if classOf(char) == class {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Пароль %s не содержит класс %s", password, class)
+		}
+	}
+}
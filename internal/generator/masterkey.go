@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+const (
+	masterKeyLength     = 32
+	masterKeySaltPrefix = "PGenCLI|v3|master|"
+)
+
+// MasterKey результат Argon2id над мастер-паролем и именем пользователя.
+// Один мастер-ключ обслуживает любое количество сайтов без повторного Argon2.
+type MasterKey struct {
+	key      *security.SecureString
+	username string
+}
+
+// DeriveMasterKey выполняет Argon2id с параметрами генератора. Ключ нужно
+// очистить через Clear после использования.
+func (pg *PasswordGenerator) DeriveMasterKey(masterPassword *security.SecureString, username string) *MasterKey {
+	salt := sha256.Sum256([]byte(masterKeySaltPrefix + username))
+	hash := pg.argonHash(masterPassword, salt[:saltLength], masterKeyLength)
+	defer security.ZeroMemory(hash)
+
+	return &MasterKey{
+		key:      security.NewSecureStringFromBytes(hash),
+		username: username,
+	}
+}
+
+// Username возвращает имя пользователя, для которого получен ключ
+func (mk *MasterKey) Username() string {
+	return mk.username
+}
+
+// LockMemory переносит ключ в память, закреплённую в оперативной памяти, чтобы
+// долгоживущий ключ не попал в swap
+func (mk *MasterKey) LockMemory() error {
+	if mk.key.IsLocked() {
+		return nil
+	}
+	key := mk.key.Bytes()
+	defer security.ZeroMemory(key)
+
+	locked, err := security.NewLockedSecureString(key)
+	if err != nil {
+		return err
+	}
+	mk.key.Clear()
+	mk.key = locked
+	return nil
+}
+
+// Clear очищает мастер-ключ из памяти
+func (mk *MasterKey) Clear() {
+	if mk == nil {
+		return
+	}
+	mk.key.Clear()
+}
+
+// siteKey возвращает HMAC-SHA256(мастер-ключ, service|counter|type)
+func (mk *MasterKey) siteKey(serviceName string, counter uint32, outputType string) []byte {
+	key := mk.key.Bytes()
+	defer security.ZeroMemory(key)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(serviceName + "|" + strconv.FormatUint(uint64(counter), 10) + "|" + outputType))
+	return mac.Sum(nil)
+}
+
+// GenerateWithMasterKey генерирует пароль сайта из готового мастер-ключа: только
+// HMAC и расширение ключа, без Argon2. Доступно для двухэтапного алгоритма v3.
+func (pg *PasswordGenerator) GenerateWithMasterKey(masterKey *MasterKey, serviceName string) (*security.SecureString, error) {
+	if _, ok := pg.Algorithm().(algorithmV3); !ok {
+		return nil, fmt.Errorf("algorithm_not_two_stage")
+	}
+	if masterKey == nil || masterKey.key.IsEmpty() {
+		return nil, fmt.Errorf("master_key_empty")
+	}
+	return pg.generateFromKey(masterKey.siteKey(serviceName, pg.counter, pg.outputType()))
+}
+
+// outputType описывает вид результата для ключа сайта, чтобы пароль, фраза
+// и шаблоны одного сайта не зависели друг от друга
+func (pg *PasswordGenerator) outputType() string {
+	switch pg.Mode() {
+	case ModeTemplate:
+		return string(ModeTemplate) + ":" + string(pg.template)
+	default:
+		return string(pg.Mode())
+	}
+}
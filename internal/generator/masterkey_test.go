@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+func newMasterKeyTestGenerator() *PasswordGenerator {
+	pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 1,
+		KeyLen:  32,
+	}, DefaultCharset())
+	pg.SetAlgorithm(algorithmV3{})
+	return pg
+}
+
+func TestGenerateWithMasterKey(t *testing.T) {
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	pg := newMasterKeyTestGenerator()
+	masterKey := pg.DeriveMasterKey(masterPassword, "testuser")
+	defer masterKey.Clear()
+
+	if masterKey.Username() != "testuser" {
+		t.Errorf("Username() = %q, ожидается %q", masterKey.Username(), "testuser")
+	}
+
+	services := []string{"github.com", "google.com", "bank"}
+	seen := make(map[string]string)
+	for _, service := range services {
+		t.Run(service, func(t *testing.T) {
+			// Результат совпадает с полным вызовом GeneratePassword для v3
+			fromKey, err := pg.GenerateWithMasterKey(masterKey, service)
+			if err != nil {
+				t.Fatalf("GenerateWithMasterKey() ошибка: %v", err)
+			}
+			defer fromKey.Clear()
+
+			full, err := pg.GeneratePassword(masterPassword, service, "testuser")
+			if err != nil {
+				t.Fatalf("GeneratePassword() ошибка: %v", err)
+			}
+			defer full.Clear()
+
+			if !fromKey.SecureCompare(full) {
+				t.Errorf("GenerateWithMasterKey() = %q, GeneratePassword() = %q", fromKey.String(), full.String())
+			}
+			if other, ok := seen[fromKey.String()]; ok {
+				t.Errorf("Пароли %s и %s совпадают", service, other)
+			}
+			seen[fromKey.String()] = service
+		})
+	}
+}
+
+func TestMasterKeySiteKeySeparation(t *testing.T) {
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	pg := newMasterKeyTestGenerator()
+	masterKey := pg.DeriveMasterKey(masterPassword, "testuser")
+	defer masterKey.Clear()
+
+	base := string(masterKey.siteKey("github.com", 1, "password"))
+
+	variants := map[string]string{
+		"Другой сервис":  string(masterKey.siteKey("gitlab.com", 1, "password")),
+		"Другой счётчик": string(masterKey.siteKey("github.com", 2, "password")),
+		"Другой тип":     string(masterKey.siteKey("github.com", 1, "template:pin")),
+	}
+	for name, key := range variants {
+		if key == base {
+			t.Errorf("%s: ключ сайта совпадает с базовым", name)
+		}
+	}
+
+	other := pg.DeriveMasterKey(masterPassword, "otheruser")
+	defer other.Clear()
+	if string(other.siteKey("github.com", 1, "password")) == base {
+		t.Error("Ключи сайта разных пользователей совпадают")
+	}
+}
+
+func TestGenerateWithMasterKeyErrors(t *testing.T) {
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	pg := newMasterKeyTestGenerator()
+	masterKey := pg.DeriveMasterKey(masterPassword, "testuser")
+
+	legacy := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+	if _, err := legacy.GenerateWithMasterKey(masterKey, "github.com"); err == nil || err.Error() != "algorithm_not_two_stage" {
+		t.Errorf("GenerateWithMasterKey() для v1 ошибка = %v, ожидается algorithm_not_two_stage", err)
+	}
+
+	masterKey.Clear()
+	if _, err := pg.GenerateWithMasterKey(masterKey, "github.com"); err == nil || err.Error() != "master_key_empty" {
+		t.Errorf("GenerateWithMasterKey() после Clear ошибка = %v, ожидается master_key_empty", err)
+	}
+	if _, err := pg.GenerateWithMasterKey(nil, "github.com"); err == nil {
+		t.Error("GenerateWithMasterKey(nil) должен вернуть ошибку")
+	}
+}
+
+func TestOutputType(t *testing.T) {
+	pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+	if got := pg.outputType(); got != "password" {
+		t.Errorf("outputType() = %q, ожидается %q", got, "password")
+	}
+
+	pg.SetMode(ModePassphrase)
+	if got := pg.outputType(); got != "passphrase" {
+		t.Errorf("outputType() = %q, ожидается %q", got, "passphrase")
+	}
+
+	pg.SetMode(ModeTemplate)
+	_ = pg.SetPasswordType(TypePIN)
+	if got := pg.outputType(); got != "template:pin" {
+		t.Errorf("outputType() = %q, ожидается %q", got, "template:pin")
+	}
+}
+
+// Бенчмарки сравнивают генерацию для нескольких сайтов: v2 выполняет Argon2
+// для каждого сайта, v3 - один Argon2 на мастер-ключ и HMAC на каждый сайт.
+const benchmarkSites = 10
+
+func BenchmarkGenerateSitesPerSiteArgon(b *testing.B) {
+	masterPassword := security.NewSecureString("benchmaster")
+	defer masterPassword.Clear()
+
+	pg := NewPasswordGenerator(16)
+	pg.SetAlgorithm(algorithmV2{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for site := 0; site < benchmarkSites; site++ {
+			password, err := pg.GeneratePassword(masterPassword, "site"+string(rune('a'+site)), "user")
+			if err != nil {
+				b.Fatal(err)
+			}
+			password.Clear()
+		}
+	}
+}
+
+func BenchmarkGenerateSitesWithMasterKey(b *testing.B) {
+	masterPassword := security.NewSecureString("benchmaster")
+	defer masterPassword.Clear()
+
+	pg := NewPasswordGenerator(16)
+	pg.SetAlgorithm(algorithmV3{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		masterKey := pg.DeriveMasterKey(masterPassword, "user")
+		for site := 0; site < benchmarkSites; site++ {
+			password, err := pg.GenerateWithMasterKey(masterKey, "site"+string(rune('a'+site)))
+			if err != nil {
+				b.Fatal(err)
+			}
+			password.Clear()
+		}
+		masterKey.Clear()
+	}
+}
+
+func BenchmarkDeriveMasterKey(b *testing.B) {
+	masterPassword := security.NewSecureString("benchmaster")
+	defer masterPassword.Clear()
+
+	pg := NewPasswordGenerator(16)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pg.DeriveMasterKey(masterPassword, "user").Clear()
+	}
+}
+
+func BenchmarkGenerateWithMasterKey(b *testing.B) {
+	masterPassword := security.NewSecureString("benchmaster")
+	defer masterPassword.Clear()
+
+	pg := NewPasswordGenerator(16)
+	pg.SetAlgorithm(algorithmV3{})
+	masterKey := pg.DeriveMasterKey(masterPassword, "user")
+	defer masterKey.Clear()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		password, err := pg.GenerateWithMasterKey(masterKey, "github.com")
+		if err != nil {
+			b.Fatal(err)
+		}
+		password.Clear()
+	}
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// CanonicalService приводит имя сервиса к канонической форме, чтобы один
+// сервис давал один пароль независимо от способа ввода:
+//   - NFC: "й" и "é" из разложенных форм NFD совпадают с составными
+//   - пробелы по краям и точка в конце доменного имени отбрасываются
+//   - доменные имена переводятся в ASCII по IDNA (UTS #46), интернациональные
+//     домены - в punycode: "Пример.РФ" → "xn--e1afmkfd.xn--p1ai"
+//   - остальные имена (с пробелами, "@" и т. п.) приводятся к единому регистру
+//     полной свёрткой регистра Unicode
+func CanonicalService(service string) (string, error) {
+	service = strings.TrimFunc(norm.NFC.String(service), unicode.IsSpace)
+	if service == "" {
+		return "", fmt.Errorf("service_empty")
+	}
+
+	if isDomainLike(service) {
+		domain, err := idna.Lookup.ToASCII(service)
+		domain = strings.TrimSuffix(domain, ".")
+		if err == nil && domain != "" {
+			return domain, nil
+		}
+	}
+	return cases.Fold().String(service), nil
+}
+
+// domainDots точки, которые IDNA считает разделителями меток
+const domainDots = ".。．｡"
+
+// isDomainLike сообщает, похоже ли имя сервиса на доменное: есть точка
+// и нет пробелов. Окончательно имя проверяет IDNA.
+func isDomainLike(service string) bool {
+	return strings.ContainsAny(service, domainDots) && !strings.ContainsFunc(service, unicode.IsSpace)
+}
+
+// normalizeSecret возвращает мастер-пароль в форме NFC. Результат нужно
+// очистить после использования.
+func normalizeSecret(secret *security.SecureString) *security.SecureString {
+	raw := secret.Bytes()
+	defer security.ZeroMemory(raw)
+
+	normalized := norm.NFC.Bytes(raw)
+	result := security.NewSecureStringFromBytes(normalized)
+	security.ZeroMemory(normalized)
+	return result
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+func TestCanonicalService(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		want    string
+	}{
+		// Пробелы по краям
+		{"Пробелы по краям", "  github.com\t\n", "github.com"},
+		{"Неразрывный пробел", " github.com　", "github.com"},
+
+		// Регистр
+		{"Регистр домена", "GitHub.COM", "github.com"},
+		{"Регистр имени без точки", "GitHub", "github"},
+		{"Регистр кириллицы", "ПОЧТА", "почта"},
+		{"Полная свёртка регистра", "Straße Bank", "strasse bank"},
+
+		// NFC
+		{"NFD й", "мои\u0306 банк", "мой банк"},
+		{"NFD é", "cafe\u0301", "café"},
+
+		// IDNA
+		{"Интернациональный домен", "пример.рф", "xn--e1afmkfd.xn--p1ai"},
+		{"Интернациональный домен в верхнем регистре", "Пример.РФ", "xn--e1afmkfd.xn--p1ai"},
+		{"Интернациональный домен в NFD", "bu\u0308cher.de", "xn--bcher-kva.de"},
+		{"Punycode не меняется", "xn--e1afmkfd.xn--p1ai", "xn--e1afmkfd.xn--p1ai"},
+		{"Точка в конце домена", "github.com.", "github.com"},
+		{"Полноширинная точка", "github。com", "github.com"},
+		{"Полноширинная точка в конце", "github.com。", "github.com"},
+
+		// Не доменные имена
+		{"Имя с пробелом", "My Bank", "my bank"},
+		{"Адрес почты", "User@Example.com", "user@example.com"},
+		{"Недопустимый домен", "-bad-.com", "-bad-.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalService(tt.service)
+			if err != nil {
+				t.Fatalf("CanonicalService(%q) ошибка: %v", tt.service, err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalService(%q) = %q, ожидается %q", tt.service, got, tt.want)
+			}
+			// Каноническая форма не меняется при повторной обработке
+			if again, _ := CanonicalService(got); again != got {
+				t.Errorf("CanonicalService(%q) = %q, форма не устойчива", got, again)
+			}
+		})
+	}
+}
+
+func TestCanonicalServiceEmpty(t *testing.T) {
+	for _, service := range []string{"", "   ", "\t\n"} {
+		if _, err := CanonicalService(service); err == nil || err.Error() != "service_empty" {
+			t.Errorf("CanonicalService(%q) ошибка = %v, ожидается service_empty", service, err)
+		}
+	}
+}
+
+func TestAlgorithmV4KnownAnswers(t *testing.T) {
+	// Для ввода в канонической форме v4 совпадает с v2
+	runAlgorithmKnownAnswers(t, algorithmV4{}, "github.com", map[string]string{
+		"Пароль по умолчанию":      "xc-J%=P>JViVn}C|",
+		"Продление хеша":           "&O.AR{nD^5vk&+Cw#L=baBKt",
+		"Длинный пароль":           "JZY-yx=nGSfOmb|jHgZ%zCm(Yo*1dP)[RMa@<p*ccC<0;p9)#V++8sGFN5;,2-J]",
+		"Пользовательский алфавит": "532193816678",
+		"Счётчик":                  "ITU9%,*vO!s8y^L[",
+		"Парольная фраза":          "ditch-unskilled-sneak-pesky-capped-spherical",
+		"Русская парольная фраза":  "Бригада Вихрь3 Алфавит Бык",
+		"Шаблон long":              "YumaVegu2:Cuto",
+		"Шаблон pin":               "0940",
+	})
+}
+
+func TestAlgorithmV4Normalization(t *testing.T) {
+	generate := func(algorithm Algorithm, master, service, username string) string {
+		t.Helper()
+		masterPassword := security.NewSecureString(master)
+		defer masterPassword.Clear()
+
+		pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{Time: 1, Memory: 64 * 1024, Threads: 1, KeyLen: 32}, DefaultCharset())
+		pg.SetAlgorithm(algorithm)
+		password, err := pg.GeneratePassword(masterPassword, service, username)
+		if err != nil {
+			t.Fatalf("GeneratePassword() ошибка: %v", err)
+		}
+		defer password.Clear()
+		return password.String()
+	}
+
+	// Один и тот же текст в NFC (Linux, Windows) и NFD (macOS)
+	const (
+		masterNFC = "мой-пароль-é"
+		masterNFD = "мои\u0306-пароль-e\u0301"
+		userNFC   = "José"
+		userNFD   = "Jose\u0301"
+	)
+
+	want := generate(algorithmV4{}, masterNFC, "пример.рф", userNFC)
+	variants := []struct {
+		name                      string
+		master, service, username string
+	}{
+		{"Мастер-пароль в NFD", masterNFD, "пример.рф", userNFC},
+		{"Имя пользователя в NFD", masterNFC, "пример.рф", userNFD},
+		{"Сервис в другом регистре и с пробелами", masterNFC, " Пример.РФ ", userNFC},
+		{"Сервис в punycode", masterNFC, "xn--e1afmkfd.xn--p1ai", userNFC},
+	}
+	for _, v := range variants {
+		t.Run(v.name, func(t *testing.T) {
+			if got := generate(algorithmV4{}, v.master, v.service, v.username); got != want {
+				t.Errorf("GeneratePassword() = %q, ожидается %q", got, want)
+			}
+		})
+	}
+
+	// v2 хеширует байты как есть, поэтому его пароли остаются прежними
+	if generate(algorithmV2{}, masterNFC, "github.com", userNFC) == generate(algorithmV2{}, masterNFD, "github.com", userNFC) {
+		t.Error("v2 не должен нормализовать мастер-пароль")
+	}
+}
@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mode режим генерации
+type Mode string
+
+const (
+	ModePassword   Mode = "password"
+	ModePassphrase Mode = "passphrase"
+	ModeTemplate   Mode = "template"
+)
+
+const (
+	WordlistEnglish = "en" // EFF large wordlist, 7776 слов
+	WordlistRussian = "ru" // курируемый список существительных, 1024 слова
+
+	DefaultPassphraseWords     = 6
+	DefaultPassphraseSeparator = "-"
+	DefaultWordlist            = WordlistEnglish
+	minPassphraseWords         = 3
+	maxPassphraseWords         = 20
+	maxSeparatorLength         = 4
+)
+
+//go:embed wordlists/*.txt
+var wordlistFiles embed.FS
+
+var wordlistSources = map[string]string{
+	WordlistEnglish: "wordlists/eff_large.txt",
+	WordlistRussian: "wordlists/ru_nouns.txt",
+}
+
+var (
+	wordlistCache   = make(map[string][]string)
+	wordlistCacheMu sync.Mutex
+)
+
+// PassphraseOptions параметры парольной фразы
+type PassphraseOptions struct {
+	Words      int
+	Separator  string
+	Capitalize bool
+	Digit      bool
+	Wordlist   string
+}
+
+// DefaultPassphraseOptions возвращает параметры парольной фразы по умолчанию
+func DefaultPassphraseOptions() PassphraseOptions {
+	return PassphraseOptions{
+		Words:     DefaultPassphraseWords,
+		Separator: DefaultPassphraseSeparator,
+		Wordlist:  DefaultWordlist,
+	}
+}
+
+// WordlistNames возвращает доступные словари
+func WordlistNames() []string {
+	return []string{WordlistEnglish, WordlistRussian}
+}
+
+// LoadWordlist возвращает слова встроенного словаря. Порядок слов фиксирован,
+// так как от него зависят уже сгенерированные фразы.
+func LoadWordlist(name string) ([]string, error) {
+	wordlistCacheMu.Lock()
+	defer wordlistCacheMu.Unlock()
+
+	if words, ok := wordlistCache[name]; ok {
+		return words, nil
+	}
+
+	path, ok := wordlistSources[name]
+	if !ok {
+		return nil, fmt.Errorf("wordlist_unknown")
+	}
+	data, err := wordlistFiles.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// Формат EFF: "11111<TAB>abacus"; в остальных словарях одно слово в строке
+		if idx := strings.IndexByte(line, '\t'); idx >= 0 {
+			line = line[idx+1:]
+		}
+		words = append(words, line)
+	}
+
+	wordlistCache[name] = words
+	return words, nil
+}
+
+// ValidatePassphraseWords проверяет количество слов
+func ValidatePassphraseWords(words int) error {
+	if words < minPassphraseWords {
+		return fmt.Errorf("words_too_few")
+	}
+	if words > maxPassphraseWords {
+		return fmt.Errorf("words_too_many")
+	}
+	return nil
+}
+
+// ValidateSeparator проверяет разделитель слов
+func ValidateSeparator(separator string) error {
+	if utf8.RuneCountInString(separator) > maxSeparatorLength {
+		return fmt.Errorf("separator_too_long")
+	}
+	for _, r := range separator {
+		if unicode.IsControl(r) || unicode.IsLetter(r) {
+			return fmt.Errorf("separator_invalid")
+		}
+	}
+	return nil
+}
+
+// ValidatePassphraseOptions проверяет параметры парольной фразы
+func ValidatePassphraseOptions(opts PassphraseOptions) error {
+	if err := ValidatePassphraseWords(opts.Words); err != nil {
+		return err
+	}
+	if err := ValidateSeparator(opts.Separator); err != nil {
+		return err
+	}
+	if _, ok := wordlistSources[opts.Wordlist]; !ok {
+		return fmt.Errorf("wordlist_unknown")
+	}
+	return nil
+}
+
+// SetMode задаёт режим генерации
+func (pg *PasswordGenerator) SetMode(mode Mode) {
+	pg.mode = mode
+}
+
+// Mode возвращает режим генерации
+func (pg *PasswordGenerator) Mode() Mode {
+	if pg.mode == "" {
+		return ModePassword
+	}
+	return pg.mode
+}
+
+// SetPassphraseOptions задаёт параметры парольной фразы
+func (pg *PasswordGenerator) SetPassphraseOptions(opts PassphraseOptions) error {
+	if err := ValidatePassphraseOptions(opts); err != nil {
+		return err
+	}
+	pg.passphrase = opts
+	return nil
+}
+
+// PassphraseOptions возвращает параметры парольной фразы
+func (pg *PasswordGenerator) PassphraseOptions() PassphraseOptions {
+	return pg.passphrase
+}
+
+// generatePassphrase отображает хеш Argon2 на слова словаря
+func (pg *PasswordGenerator) generatePassphrase(hash []byte) ([]byte, error) {
+	opts := pg.passphrase
+	words, err := LoadWordlist(opts.Wordlist)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := pg.Algorithm().stream(hash, purposePassphrase)
+	defer stream.wipe()
+
+	chosen := make([]string, opts.Words)
+	for i := range chosen {
+		word := words[stream.next(len(words))]
+		if opts.Capitalize {
+			first, size := utf8.DecodeRuneInString(word)
+			word = string(unicode.ToUpper(first)) + word[size:]
+		}
+		chosen[i] = word
+	}
+
+	if opts.Digit {
+		pos := stream.next(len(chosen))
+		digit := stream.next(10)
+		chosen[pos] += string(rune('0' + digit))
+	}
+
+	return []byte(strings.Join(chosen, opts.Separator)), nil
+}
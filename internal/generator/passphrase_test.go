@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+func TestLoadWordlist(t *testing.T) {
+	tests := []struct {
+		name         string
+		wordlist     string
+		expectedSize int
+		first        string
+		last         string
+	}{
+		{"Английский EFF", WordlistEnglish, 7776, "abacus", "zoom"},
+		{"Русский", WordlistRussian, 1024, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := LoadWordlist(tt.wordlist)
+			if err != nil {
+				t.Fatalf("LoadWordlist(%q) ошибка: %v", tt.wordlist, err)
+			}
+			if len(words) != tt.expectedSize {
+				t.Fatalf("LoadWordlist(%q) вернул %d слов, ожидается %d", tt.wordlist, len(words), tt.expectedSize)
+			}
+
+			// Порядок слов фиксирован, от него зависят уже сгенерированные фразы
+			if tt.first != "" && (words[0] != tt.first || words[len(words)-1] != tt.last) {
+				t.Errorf("Первое и последнее слово = %q, %q, ожидается %q, %q", words[0], words[len(words)-1], tt.first, tt.last)
+			}
+
+			seen := make(map[string]bool, len(words))
+			for _, word := range words {
+				if seen[word] {
+					t.Errorf("Слово %q повторяется в словаре %s", word, tt.wordlist)
+				}
+				seen[word] = true
+
+				for _, r := range word {
+					if !unicode.IsLetter(r) && r != '-' {
+						t.Errorf("Слово %q содержит недопустимый символ %q", word, r)
+						break
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := LoadWordlist("de"); err == nil || err.Error() != "wordlist_unknown" {
+		t.Errorf("LoadWordlist(\"de\") ошибка = %v, ожидается wordlist_unknown", err)
+	}
+}
+
+func TestValidatePassphraseOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(*PassphraseOptions)
+		wantError string
+	}{
+		{"По умолчанию", func(o *PassphraseOptions) {}, ""},
+		{"Минимум слов", func(o *PassphraseOptions) { o.Words = 3 }, ""},
+		{"Максимум слов", func(o *PassphraseOptions) { o.Words = 20 }, ""},
+		{"Мало слов", func(o *PassphraseOptions) { o.Words = 2 }, "words_too_few"},
+		{"Много слов", func(o *PassphraseOptions) { o.Words = 21 }, "words_too_many"},
+		{"Пустой разделитель", func(o *PassphraseOptions) { o.Separator = "" }, ""},
+		{"Пробел", func(o *PassphraseOptions) { o.Separator = " " }, ""},
+		{"Длинный разделитель", func(o *PassphraseOptions) { o.Separator = "-----" }, "separator_too_long"},
+		{"Буквы в разделителе", func(o *PassphraseOptions) { o.Separator = "x" }, "separator_invalid"},
+		{"Перевод строки", func(o *PassphraseOptions) { o.Separator = "\n" }, "separator_invalid"},
+		{"Неизвестный словарь", func(o *PassphraseOptions) { o.Wordlist = "de" }, "wordlist_unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultPassphraseOptions()
+			tt.modify(&opts)
+			err := ValidatePassphraseOptions(opts)
+
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("ValidatePassphraseOptions() неожиданная ошибка: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantError {
+				t.Errorf("ValidatePassphraseOptions() ошибка = %v, ожидается %s", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestModeDefaults(t *testing.T) {
+	pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+	if pg.Mode() != ModePassword {
+		t.Errorf("Mode() = %q, ожидается %q", pg.Mode(), ModePassword)
+	}
+	if pg.PassphraseOptions() != DefaultPassphraseOptions() {
+		t.Errorf("PassphraseOptions() = %+v, ожидается %+v", pg.PassphraseOptions(), DefaultPassphraseOptions())
+	}
+
+	var zero PasswordGenerator
+	if zero.Mode() != ModePassword {
+		t.Errorf("Mode() нулевого генератора = %q, ожидается %q", zero.Mode(), ModePassword)
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	config := ArgonConfig{
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 1,
+		KeyLen:  32,
+	}
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	generate := func(t *testing.T, length int, opts PassphraseOptions, service string) string {
+		t.Helper()
+		pg := NewPasswordGeneratorWithConfig(length, config, DefaultCharset())
+		pg.SetMode(ModePassphrase)
+		if err := pg.SetPassphraseOptions(opts); err != nil {
+			t.Fatalf("SetPassphraseOptions() ошибка: %v", err)
+		}
+		phrase, err := pg.GeneratePassword(masterPassword, service, "testuser")
+		if err != nil {
+			t.Fatalf("GeneratePassword() ошибка: %v", err)
+		}
+		defer phrase.Clear()
+		return phrase.String()
+	}
+
+	t.Run("Детерминированность и словарь", func(t *testing.T) {
+		for _, wordlist := range WordlistNames() {
+			opts := DefaultPassphraseOptions()
+			opts.Wordlist = wordlist
+
+			first := generate(t, 16, opts, "github.com")
+			second := generate(t, 16, opts, "github.com")
+			if first != second {
+				t.Errorf("Фразы не детерминированы: %q != %q", first, second)
+			}
+
+			words, _ := LoadWordlist(wordlist)
+			known := make(map[string]bool, len(words))
+			for _, word := range words {
+				known[word] = true
+			}
+
+			parts := strings.Split(first, opts.Separator)
+			if len(parts) != opts.Words {
+				t.Fatalf("Фраза %q содержит %d слов, ожидается %d", first, len(parts), opts.Words)
+			}
+			for _, part := range parts {
+				if !known[part] {
+					t.Errorf("Слово %q отсутствует в словаре %s", part, wordlist)
+				}
+			}
+		}
+	})
+
+	t.Run("Разные сервисы", func(t *testing.T) {
+		opts := DefaultPassphraseOptions()
+		if generate(t, 16, opts, "github.com") == generate(t, 16, opts, "google.com") {
+			t.Error("Фразы для разных сервисов совпадают")
+		}
+	})
+
+	t.Run("Не зависит от длины пароля", func(t *testing.T) {
+		opts := DefaultPassphraseOptions()
+		if generate(t, 16, opts, "github.com") != generate(t, 64, opts, "github.com") {
+			t.Error("Фраза зависит от --length")
+		}
+	})
+
+	t.Run("Количество слов и разделитель", func(t *testing.T) {
+		opts := DefaultPassphraseOptions()
+		opts.Words = 4
+		opts.Separator = " "
+		phrase := generate(t, 16, opts, "wifi")
+		if parts := strings.Split(phrase, " "); len(parts) != 4 {
+			t.Errorf("Фраза %q содержит %d слов, ожидается 4", phrase, len(parts))
+		}
+	})
+
+	t.Run("Заглавные буквы", func(t *testing.T) {
+		opts := DefaultPassphraseOptions()
+		opts.Wordlist = WordlistRussian
+		opts.Capitalize = true
+		plain := DefaultPassphraseOptions()
+		plain.Wordlist = WordlistRussian
+
+		phrase := generate(t, 16, opts, "disk")
+		for _, part := range strings.Split(phrase, opts.Separator) {
+			first, _ := utf8.DecodeRuneInString(part)
+			if !unicode.IsUpper(first) {
+				t.Errorf("Слово %q не начинается с заглавной буквы", part)
+			}
+		}
+		// Регистр меняется без изменения выбора слов
+		if strings.ToLower(phrase) != generate(t, 16, plain, "disk") {
+			t.Errorf("Заглавные буквы изменили выбор слов: %q", phrase)
+		}
+	})
+
+	t.Run("Цифра", func(t *testing.T) {
+		opts := DefaultPassphraseOptions()
+		opts.Digit = true
+		phrase := generate(t, 16, opts, "phone")
+
+		digits := 0
+		for _, r := range phrase {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits != 1 {
+			t.Errorf("Фраза %q содержит %d цифр, ожидается 1", phrase, digits)
+		}
+	})
+}
@@ -3,15 +3,19 @@ package generator
 import (
 	"crypto/sha256"
 	"fmt"
+	"math"
 	"math/big"
+	"strconv"
 
-	"github.com/MaksymLeiber/pgen/internal/i18n"
 	"github.com/MaksymLeiber/pgen/internal/security"
 	"golang.org/x/crypto/argon2"
 )
 
 const (
 	saltLength      = 16
+	saltPrefixV1    = "PGenCLI|v1|" // service|username, счётчик 1
+	saltPrefixV2    = "PGenCLI|v2|" // service|username|counter, счётчик > 1
+	defaultCounter  = 1
 	charsetAlphaNum = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
 	charsetSymbols  = "!@#$%^&*()_+-=[]{}|;:,.<>?"
 	charsetFull     = charsetAlphaNum + charsetSymbols
@@ -26,8 +30,15 @@ type ArgonConfig struct {
 }
 
 type PasswordGenerator struct {
-	length int
-	argon  *ArgonConfig
+	length     int
+	argon      *ArgonConfig
+	charset    Charset
+	required   []CharClass
+	counter    uint32
+	mode       Mode
+	passphrase PassphraseOptions
+	template   PasswordType
+	algorithm  Algorithm
 }
 
 func NewPasswordGenerator(length int) *PasswordGenerator {
@@ -35,67 +46,133 @@ func NewPasswordGenerator(length int) *PasswordGenerator {
 		length = 16
 	}
 	return &PasswordGenerator{
-		length: length,
-		argon:  nil,
+		length:     length,
+		argon:      nil,
+		charset:    DefaultCharset(),
+		counter:    defaultCounter,
+		mode:       ModePassword,
+		passphrase: DefaultPassphraseOptions(),
 	}
 }
 
-// NewPasswordGeneratorWithConfig создаёт генератор с пользовательской конфигурацией Argon2
-func NewPasswordGeneratorWithConfig(length int, argonConfig ArgonConfig) *PasswordGenerator {
+// NewPasswordGeneratorWithConfig создаёт генератор с пользовательской конфигурацией Argon2 и набором символов
+func NewPasswordGeneratorWithConfig(length int, argonConfig ArgonConfig, charset Charset) *PasswordGenerator {
 	if length <= 0 {
 		length = 16
 	}
+	if charset.Size() < minCharsetSize {
+		charset = DefaultCharset()
+	}
 	return &PasswordGenerator{
-		length: length,
-		argon:  &argonConfig,
+		length:     length,
+		argon:      &argonConfig,
+		charset:    charset,
+		counter:    defaultCounter,
+		mode:       ModePassword,
+		passphrase: DefaultPassphraseOptions(),
 	}
 }
 
-func (pg *PasswordGenerator) GeneratePassword(masterPassword *security.SecureString, serviceName, username string, messages *i18n.Messages) (*security.SecureString, error) {
-	salt := createSalt(serviceName, username)
+// SetCounter задаёт счётчик пароля сервиса: увеличение даёт новый пароль при смене пароля на сайте
+func (pg *PasswordGenerator) SetCounter(counter uint32) {
+	if counter < defaultCounter {
+		counter = defaultCounter
+	}
+	pg.counter = counter
+}
 
-	// Определяем параметрыы аrgon2
-	var argonTime uint32 = 3
-	var argonMemory uint32 = 256 * 1024
-	var argonThreads uint8 = 4
-	var argonKeyLen uint32 = 32
+// Counter возвращает счётчик пароля
+func (pg *PasswordGenerator) Counter() uint32 {
+	return pg.counter
+}
 
-	if pg.argon != nil {
-		argonTime = pg.argon.Time
-		argonMemory = pg.argon.Memory
-		argonThreads = pg.argon.Threads
-		argonKeyLen = pg.argon.KeyLen
+// Charset возвращает набор символов генератора
+func (pg *PasswordGenerator) Charset() Charset {
+	return pg.charset
+}
+
+func (pg *PasswordGenerator) GeneratePassword(masterPassword *security.SecureString, serviceName, username string) (*security.SecureString, error) {
+	hash, err := pg.Algorithm().deriveKey(pg, masterPassword, serviceName, username)
+	if err != nil {
+		return nil, err
 	}
+	return pg.generateFromKey(hash)
+}
 
-	var keyLen uint32 = argonKeyLen
-	if pg.length > int(argonKeyLen) {
-		keyLen = uint32(pg.length * 2) // Удваиваем для запаса
+// argonParams возвращает параметры Argon2 генератора
+func (pg *PasswordGenerator) argonParams() ArgonConfig {
+	if pg.argon != nil {
+		return *pg.argon
+	}
+	return ArgonConfig{
+		Time:    3,
+		Memory:  256 * 1024,
+		Threads: 4,
+		KeyLen:  32,
 	}
+}
+
+// argonHash вычисляет Argon2id мастер-пароля с параметрами генератора
+func (pg *PasswordGenerator) argonHash(masterPassword *security.SecureString, salt []byte, keyLen uint32) []byte {
+	params := pg.argonParams()
 
 	// Используем безопасные байты мастер-пароля
 	masterPasswordBytes := masterPassword.Bytes()
+	defer security.ZeroMemory(masterPasswordBytes)
 
-	hash := argon2.IDKey(
+	return argon2.IDKey(
 		masterPasswordBytes,
 		salt,
-		argonTime,
-		argonMemory,
-		argonThreads,
+		params.Time,
+		params.Memory,
+		params.Threads,
 		keyLen,
 	)
+}
 
-	// Используем все биты хеша
-	password := pg.generateFromHash(hash)
+// generateFromKey отображает ключ сайта на пароль, фразу или шаблон и очищает ключ
+func (pg *PasswordGenerator) generateFromKey(hash []byte) (*security.SecureString, error) {
+	if pg.Mode() != ModePassword {
+		var output []byte
+		var err error
+		switch pg.Mode() {
+		case ModePassphrase:
+			output, err = pg.generatePassphrase(hash)
+		case ModeTemplate:
+			output, err = pg.generateFromTemplate(hash)
+		default:
+			err = fmt.Errorf("mode_unknown")
+		}
+		security.ZeroMemory(hash)
+		if err != nil {
+			return nil, err
+		}
+		secureOutput := security.NewSecureStringFromBytes(output)
+		security.SecureWipe(output)
+		return secureOutput, nil
+	}
+
+	// Отображаем хеш на символы выбранной версией алгоритма
+	password, err := pg.Algorithm().password(pg, hash)
+	if err != nil {
+		security.ZeroMemory(hash)
+		return nil, err
+	}
 
 	if len(password) < pg.length {
-		return nil, fmt.Errorf("%s", messages.Errors.HashTooShort)
+		security.ZeroMemory(hash)
+		return nil, fmt.Errorf("hash_too_short")
 	}
 
+	// Гарантируем обязательные классы символов, используя биты того же хеша
+	passwordBytes := password[:pg.length]
+	pg.enforceRequiredClasses(passwordBytes, hash)
+
 	// Создаем SecureString из сгенерированного пароля
-	securePassword := security.NewSecureString(password[:pg.length])
+	securePassword := security.NewSecureStringFromBytes(passwordBytes)
 
 	// Очищаем временные данные
-	security.SecureWipe([]byte(password))
+	security.SecureWipe(password)
 	security.ZeroMemory(hash)
 
 	return securePassword, nil
@@ -103,7 +180,7 @@ func (pg *PasswordGenerator) GeneratePassword(masterPassword *security.SecureStr
 
 // generateFromHash генерирует пароль из хеша без потери энтропии
 func (pg *PasswordGenerator) generateFromHash(hash []byte) string {
-	charset := charsetFull
+	charset := pg.charset.Alphabet
 	charsetLen := big.NewInt(int64(len(charset)))
 
 	hashInt := new(big.Int).SetBytes(hash)
@@ -134,9 +211,13 @@ func (pg *PasswordGenerator) generateFromHash(hash []byte) string {
 	return string(password)
 }
 
-func createSalt(serviceName, username string) []byte {
-	// Улучшенная генерация salt с версионированием и персонализацией
-	baseText := "PGenCLI|v1|" + serviceName + "|" + username
+func createSalt(serviceName, username string, counter uint32) []byte {
+	// Улучшенная генерация salt с версионированием и персонализацией.
+	// Счётчик 1 использует формат v1, чтобы существующие пароли не изменились.
+	baseText := saltPrefixV1 + serviceName + "|" + username
+	if counter > defaultCounter {
+		baseText = saltPrefixV2 + serviceName + "|" + username + "|" + strconv.FormatUint(uint64(counter), 10)
+	}
 	hash := sha256.Sum256([]byte(baseText))
 	return hash[:saltLength]
 }
@@ -150,3 +231,13 @@ func ValidateLength(length int) error {
 	}
 	return nil
 }
+
+func ValidateCounter(counter int) error {
+	if counter < defaultCounter {
+		return fmt.Errorf("counter_too_small")
+	}
+	if uint64(counter) > math.MaxUint32 {
+		return fmt.Errorf("counter_too_large")
+	}
+	return nil
+}
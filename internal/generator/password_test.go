@@ -1,9 +1,11 @@
 package generator
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"math"
 	"testing"
 
-	"github.com/MaksymLeiber/pgen/internal/i18n"
 	"github.com/MaksymLeiber/pgen/internal/security"
 )
 
@@ -40,7 +42,7 @@ func TestNewPasswordGeneratorWithConfig(t *testing.T) {
 		KeyLen:  32,
 	}
 
-	pg := NewPasswordGeneratorWithConfig(20, config)
+	pg := NewPasswordGeneratorWithConfig(20, config, DefaultCharset())
 
 	if pg.length != 20 {
 		t.Errorf("NewPasswordGeneratorWithConfig() длина = %v, ожидается %v", pg.length, 20)
@@ -56,7 +58,6 @@ func TestNewPasswordGeneratorWithConfig(t *testing.T) {
 }
 
 func TestGeneratePassword(t *testing.T) {
-	messages := i18n.GetMessages(i18n.English, "test")
 	pg := NewPasswordGenerator(16)
 
 	tests := []struct {
@@ -77,7 +78,7 @@ func TestGeneratePassword(t *testing.T) {
 			masterPassword := security.NewSecureString(tt.masterPassword)
 			defer masterPassword.Clear()
 			
-			password, err := pg.GeneratePassword(masterPassword, tt.serviceName, "testuser", messages)
+			password, err := pg.GeneratePassword(masterPassword, tt.serviceName, "testuser")
 
 			if tt.wantError && err == nil {
 				t.Error("GeneratePassword() ожидалась ошибка, получен nil")
@@ -103,7 +104,6 @@ func TestGeneratePassword(t *testing.T) {
 }
 
 func TestGeneratePasswordDeterministic(t *testing.T) {
-	messages := i18n.GetMessages(i18n.English, "test")
 	pg := NewPasswordGenerator(16)
 
 	masterPassword := security.NewSecureString("testmaster123")
@@ -112,19 +112,19 @@ func TestGeneratePasswordDeterministic(t *testing.T) {
 	username := "testuser"
 
 	// Генерируем пароль несколько раз
-	password1, err := pg.GeneratePassword(masterPassword, serviceName, username, messages)
+	password1, err := pg.GeneratePassword(masterPassword, serviceName, username)
 	if err != nil {
 		t.Fatalf("GeneratePassword() ошибка: %v", err)
 	}
 	defer password1.Clear()
 
-	password2, err := pg.GeneratePassword(masterPassword, serviceName, username, messages)
+	password2, err := pg.GeneratePassword(masterPassword, serviceName, username)
 	if err != nil {
 		t.Fatalf("GeneratePassword() ошибка: %v", err)
 	}
 	defer password2.Clear()
 
-	password3, err := pg.GeneratePassword(masterPassword, serviceName, username, messages)
+	password3, err := pg.GeneratePassword(masterPassword, serviceName, username)
 	if err != nil {
 		t.Fatalf("GeneratePassword() ошибка: %v", err)
 	}
@@ -137,7 +137,6 @@ func TestGeneratePasswordDeterministic(t *testing.T) {
 }
 
 func TestGeneratePasswordDifferentInputs(t *testing.T) {
-	messages := i18n.GetMessages(i18n.English, "test")
 	pg := NewPasswordGenerator(16)
 
 	master1 := security.NewSecureString("master1")
@@ -145,11 +144,11 @@ func TestGeneratePasswordDifferentInputs(t *testing.T) {
 	master2 := security.NewSecureString("master2")
 	defer master2.Clear()
 
-	password1, _ := pg.GeneratePassword(master1, "service1", "user1", messages)
+	password1, _ := pg.GeneratePassword(master1, "service1", "user1")
 	defer password1.Clear()
-	password2, _ := pg.GeneratePassword(master2, "service1", "user1", messages)
+	password2, _ := pg.GeneratePassword(master2, "service1", "user1")
 	defer password2.Clear()
-	password3, _ := pg.GeneratePassword(master1, "service2", "user1", messages)
+	password3, _ := pg.GeneratePassword(master1, "service2", "user1")
 	defer password3.Clear()
 
 	if password1.SecureCompare(password2) {
@@ -162,8 +161,6 @@ func TestGeneratePasswordDifferentInputs(t *testing.T) {
 }
 
 func TestGeneratePasswordWithCustomConfig(t *testing.T) {
-	messages := i18n.GetMessages(i18n.English, "test")
-
 	// Быстрые параметры для тестов
 	config := ArgonConfig{
 		Time:    1,
@@ -172,11 +169,11 @@ func TestGeneratePasswordWithCustomConfig(t *testing.T) {
 		KeyLen:  32,
 	}
 
-	pg := NewPasswordGeneratorWithConfig(20, config)
+	pg := NewPasswordGeneratorWithConfig(20, config, DefaultCharset())
 	masterPassword := security.NewSecureString("testmaster")
 	defer masterPassword.Clear()
 	
-	password, err := pg.GeneratePassword(masterPassword, "testservice", "testuser", messages)
+	password, err := pg.GeneratePassword(masterPassword, "testservice", "testuser")
 
 	if err != nil {
 		t.Fatalf("GeneratePassword() ошибка: %v", err)
@@ -200,8 +197,8 @@ func TestCreateSalt(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.serviceName, func(t *testing.T) {
-			salt1 := createSalt(tt.serviceName, "testuser")
-			salt2 := createSalt(tt.serviceName, "testuser")
+			salt1 := createSalt(tt.serviceName, "testuser", 1)
+			salt2 := createSalt(tt.serviceName, "testuser", 1)
 
 			if len(salt1) != saltLength {
 				t.Errorf("createSalt() длина = %v, ожидается %v", len(salt1), saltLength)
@@ -215,14 +212,115 @@ func TestCreateSalt(t *testing.T) {
 	}
 
 	// Проверяем, что разные сервисы дают разные соли
-	salt1 := createSalt("service1", "user1")
-	salt2 := createSalt("service2", "user1")
+	salt1 := createSalt("service1", "user1", 1)
+	salt2 := createSalt("service2", "user1", 1)
 
 	if string(salt1) == string(salt2) {
 		t.Error("Разные сервисы должны генерировать разные соли")
 	}
 }
 
+func TestCreateSaltCounter(t *testing.T) {
+	// Счётчик 1 обязан давать ту же соль, что и формат до появления счётчика
+	legacy := sha256.Sum256([]byte("PGenCLI|v1|github.com|testuser"))
+	if !bytes.Equal(createSalt("github.com", "testuser", 1), legacy[:saltLength]) {
+		t.Error("createSalt() со счётчиком 1 должен совпадать с форматом v1")
+	}
+
+	// Счётчик 0 трактуется как 1
+	if !bytes.Equal(createSalt("github.com", "testuser", 0), legacy[:saltLength]) {
+		t.Error("createSalt() со счётчиком 0 должен совпадать с форматом v1")
+	}
+
+	v2 := sha256.Sum256([]byte("PGenCLI|v2|github.com|testuser|2"))
+	if !bytes.Equal(createSalt("github.com", "testuser", 2), v2[:saltLength]) {
+		t.Error("createSalt() со счётчиком 2 должен использовать формат v2")
+	}
+
+	seen := make(map[string]uint32)
+	for counter := uint32(1); counter <= 10; counter++ {
+		salt := string(createSalt("github.com", "testuser", counter))
+		if previous, ok := seen[salt]; ok {
+			t.Errorf("Счётчики %d и %d дают одинаковую соль", previous, counter)
+		}
+		seen[salt] = counter
+	}
+}
+
+func TestGeneratePasswordCounter(t *testing.T) {
+	config := ArgonConfig{
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 1,
+		KeyLen:  32,
+	}
+
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	pg := NewPasswordGeneratorWithConfig(16, config, DefaultCharset())
+	if pg.Counter() != 1 {
+		t.Errorf("Счётчик по умолчанию = %d, ожидается 1", pg.Counter())
+	}
+
+	first, err := pg.GeneratePassword(masterPassword, "github.com", "testuser")
+	if err != nil {
+		t.Fatalf("GeneratePassword() ошибка: %v", err)
+	}
+	defer first.Clear()
+
+	pg.SetCounter(2)
+	second, err := pg.GeneratePassword(masterPassword, "github.com", "testuser")
+	if err != nil {
+		t.Fatalf("GeneratePassword() ошибка: %v", err)
+	}
+	defer second.Clear()
+
+	if first.SecureCompare(second) {
+		t.Error("Разные счётчики должны генерировать разные пароли")
+	}
+
+	pg.SetCounter(1)
+	again, err := pg.GeneratePassword(masterPassword, "github.com", "testuser")
+	if err != nil {
+		t.Fatalf("GeneratePassword() ошибка: %v", err)
+	}
+	defer again.Clear()
+
+	if !first.SecureCompare(again) {
+		t.Error("Возврат к счётчику 1 должен восстановить исходный пароль")
+	}
+}
+
+func TestValidateCounter(t *testing.T) {
+	tests := []struct {
+		name    string
+		counter int
+		wantErr string
+	}{
+		{"Минимальный", 1, ""},
+		{"Обычный", 42, ""},
+		{"Ноль", 0, "counter_too_small"},
+		{"Отрицательный", -1, "counter_too_small"},
+		{"Больше uint32", math.MaxUint32 + 1, "counter_too_large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCounter(tt.counter)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateCounter(%d) неожиданная ошибка: %v", tt.counter, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidateCounter(%d) ошибка = %v, ожидается %s", tt.counter, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateLength(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -284,14 +382,13 @@ func TestGenerateFromHash(t *testing.T) {
 
 // Бенчмарки для измерения производительности
 func BenchmarkGeneratePassword(b *testing.B) {
-	messages := i18n.GetMessages(i18n.English, "test")
 	pg := NewPasswordGenerator(16)
 	masterPassword := security.NewSecureString("benchmark")
 	defer masterPassword.Clear()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		password, _ := pg.GeneratePassword(masterPassword, "test", "benchuser", messages)
+		password, _ := pg.GeneratePassword(masterPassword, "test", "benchuser")
 		if password != nil {
 			password.Clear()
 		}
@@ -299,8 +396,6 @@ func BenchmarkGeneratePassword(b *testing.B) {
 }
 
 func BenchmarkGeneratePasswordFast(b *testing.B) {
-	messages := i18n.GetMessages(i18n.English, "test")
-
 	// Быстрые параметры для тестирования
 	config := ArgonConfig{
 		Time:    1,
@@ -309,13 +404,13 @@ func BenchmarkGeneratePasswordFast(b *testing.B) {
 		KeyLen:  32,
 	}
 
-	pg := NewPasswordGeneratorWithConfig(16, config)
+	pg := NewPasswordGeneratorWithConfig(16, config, DefaultCharset())
 	masterPassword := security.NewSecureString("benchmark")
 	defer masterPassword.Clear()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		password, _ := pg.GeneratePassword(masterPassword, "test", "benchuser", messages)
+		password, _ := pg.GeneratePassword(masterPassword, "test", "benchuser")
 		if password != nil {
 			password.Clear()
 		}
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ServiceCanonical режим приведения имени сервиса перед генерацией
+type ServiceCanonical string
+
+const (
+	ServiceCanonicalOff    ServiceCanonical = "off"    // имя используется как введено
+	ServiceCanonicalHost   ServiceCanonical = "host"   // хост из URL без схемы, пути, порта и "www."
+	ServiceCanonicalDomain ServiceCanonical = "domain" // регистрируемый домен по списку публичных суффиксов
+)
+
+// ServiceCanonicalNames возвращает доступные режимы приведения имени сервиса
+func ServiceCanonicalNames() []string {
+	return []string{string(ServiceCanonicalOff), string(ServiceCanonicalHost), string(ServiceCanonicalDomain)}
+}
+
+// ParseServiceCanonical разбирает режим приведения имени сервиса. Пустая
+// строка означает "off": так работали конфигурации до появления настройки.
+func ParseServiceCanonical(name string) (ServiceCanonical, error) {
+	switch mode := ServiceCanonical(name); mode {
+	case "":
+		return ServiceCanonicalOff, nil
+	case ServiceCanonicalOff, ServiceCanonicalHost, ServiceCanonicalDomain:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("service_canonical_unknown")
+	}
+}
+
+// CanonicalizeService приводит имя сервиса к одному виду, чтобы
+// "https://www.GitHub.com:443/login" и "github.com" давали один пароль:
+//   - из URL берётся хост, схема, учётные данные, порт, путь и запрос
+//     отбрасываются; адрес без схемы ("github.com/login") разбирается так же
+//   - префикс "www." отбрасывается
+//   - имя приводится к канонической форме CanonicalService: NFC, нижний
+//     регистр, интернациональные домены в punycode
+//   - в режиме domain хост сокращается до регистрируемого домена по
+//     встроенному списку публичных суффиксов: "accounts.google.com" →
+//     "google.com", но "user.github.io" остаётся как есть
+//
+// Имена, не похожие на адрес ("My Bank"), только приводятся к канонической форме.
+// Имя без точки ("GitHub") остаётся именем: зона домена неизвестна, поэтому
+// "GitHub" даёт "github", а не "github.com", и пароль другой. Такие имена
+// вне задачи приведения; совпадение "github" с известным "github.com"
+// находит проверка опечаток в имени сервиса.
+func CanonicalizeService(service string, mode ServiceCanonical) (string, error) {
+	mode, err := ParseServiceCanonical(string(mode))
+	if err != nil {
+		return "", err
+	}
+	if mode == ServiceCanonicalOff {
+		return service, nil
+	}
+
+	canonical, err := CanonicalService(serviceHost(service))
+	if err != nil {
+		return "", err
+	}
+	if trimmed, ok := strings.CutPrefix(canonical, "www."); ok && strings.Contains(trimmed, ".") {
+		canonical = trimmed
+	}
+	if mode == ServiceCanonicalDomain && net.ParseIP(canonical) == nil {
+		if domain, err := publicsuffix.EffectiveTLDPlusOne(canonical); err == nil {
+			canonical = domain
+		}
+	}
+	return canonical, nil
+}
+
+// serviceHost возвращает хост, если имя сервиса - URL или адрес сайта без
+// схемы, иначе имя без изменений
+func serviceHost(service string) string {
+	service = strings.TrimSpace(service)
+	address := service
+	if !strings.Contains(service, "://") {
+		// Адрес без схемы: первая часть до пути должна быть похожа на домен
+		head, _, _ := strings.Cut(service, "/")
+		head, _, _ = strings.Cut(head, "?")
+		head, _, _ = strings.Cut(head, "#")
+		if !isDomainLike(head) || strings.Contains(head, "@") {
+			return service
+		}
+		address = "//" + service
+	}
+
+	parsed, err := url.Parse(address)
+	if err != nil || parsed.Hostname() == "" {
+		return service
+	}
+	return parsed.Hostname()
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// PasswordType тип шаблонного пароля в стиле Master Password/Spectre
+type PasswordType string
+
+const (
+	TypeMaximum PasswordType = "maximum" // 20 символов, все классы
+	TypeLong    PasswordType = "long"    // 14 символов, произносимый, с цифрой и символом
+	TypeMedium  PasswordType = "medium"  // 8 символов, произносимый, с цифрой и символом
+	TypeShort   PasswordType = "short"   // 4 символа
+	TypeBasic   PasswordType = "basic"   // 8 букв и цифр, без символов
+	TypePIN     PasswordType = "pin"     // 4 цифры
+	TypeName    PasswordType = "name"    // 9 строчных букв, подходит для логина
+	TypePhrase  PasswordType = "phrase"  // 20 символов, произносимые слова через пробел
+
+	// TypeNone отключает шаблоны и возвращает генерацию по набору символов
+	TypeNone = "none"
+
+	// Шаблоны расходуют по байту хеша на выбор шаблона и на каждый символ
+	minTemplateHashSize = 32
+)
+
+// Классы символов шаблона
+var templateClasses = map[byte]string{
+	'V': "AEIOU",
+	'C': "BCDFGHJKLMNPQRSTVWXYZ",
+	'v': "aeiou",
+	'c': "bcdfghjklmnpqrstvwxyz",
+	'A': "AEIOUBCDFGHJKLMNPQRSTVWXYZ",
+	'a': "AEIOUaeiouBCDFGHJKLMNPQRSTVWXYZbcdfghjklmnpqrstvwxyz",
+	'n': "0123456789",
+	'o': "@&%?,=[]_:-+*$#!'^~;()/.",
+	'x': "AEIOUaeiouBCDFGHJKLMNPQRSTVWXYZbcdfghjklmnpqrstvwxyz0123456789!@#$%^&*()",
+	' ': " ",
+}
+
+// Шаблоны каждого типа. Порядок фиксирован: первый байт хеша выбирает шаблон.
+var passwordTemplates = map[PasswordType][]string{
+	TypeMaximum: {"anoxxxxxxxxxxxxxxxxx", "axxxxxxxxxxxxxxxxxno"},
+	TypeLong: {
+		"CvcvnoCvcvCvcv", "CvcvCvcvnoCvcv", "CvcvCvcvCvcvno",
+		"CvccnoCvcvCvcv", "CvccCvcvnoCvcv", "CvccCvcvCvcvno",
+		"CvcvnoCvccCvcv", "CvcvCvccnoCvcv", "CvcvCvccCvcvno",
+		"CvcvnoCvcvCvcc", "CvcvCvcvnoCvcc", "CvcvCvcvCvccno",
+		"CvccnoCvccCvcv", "CvccCvccnoCvcv", "CvccCvccCvcvno",
+		"CvcvnoCvccCvcc", "CvcvCvccnoCvcc", "CvcvCvccCvccno",
+		"CvccnoCvcvCvcc", "CvccCvcvnoCvcc", "CvccCvcvCvccno",
+	},
+	TypeMedium: {"CvcnoCvc", "CvcCvcno"},
+	TypeShort:  {"Cvcn"},
+	TypeBasic:  {"aaanaaan", "aannaaan", "aaannaaa"},
+	TypePIN:    {"nnnn"},
+	TypeName:   {"cvccvcvcv"},
+	TypePhrase: {"cvcc cvc cvccvcv cvc", "cvc cvccvcvcv cvcv", "cv cvccv cvc cvcvccv"},
+}
+
+// PasswordTypeNames возвращает доступные типы шаблонов
+func PasswordTypeNames() []string {
+	return []string{
+		string(TypeMaximum), string(TypeLong), string(TypeMedium), string(TypeShort),
+		string(TypeBasic), string(TypePIN), string(TypeName), string(TypePhrase),
+	}
+}
+
+// ParsePasswordType разбирает имя типа. Пустая строка и "none" означают
+// генерацию по набору символов без шаблона.
+func ParsePasswordType(name string) (PasswordType, error) {
+	name = strings.TrimSpace(strings.ToLower(name))
+	if name == "" || name == TypeNone {
+		return "", nil
+	}
+	if _, ok := passwordTemplates[PasswordType(name)]; !ok {
+		return "", fmt.Errorf("password_type_unknown")
+	}
+	return PasswordType(name), nil
+}
+
+// TemplateEntropy возвращает энтропию типа в битах. Берётся самый слабый
+// шаблон типа плюс биты выбора шаблона.
+func TemplateEntropy(passwordType PasswordType) float64 {
+	templates, ok := passwordTemplates[passwordType]
+	if !ok {
+		return 0
+	}
+
+	weakest := math.Inf(1)
+	for _, template := range templates {
+		bits := 0.0
+		for i := 0; i < len(template); i++ {
+			bits += math.Log2(float64(len(templateClasses[template[i]])))
+		}
+		weakest = math.Min(weakest, bits)
+	}
+	return weakest + math.Log2(float64(len(templates)))
+}
+
+// SetPasswordType задаёт тип шаблона для режима ModeTemplate
+func (pg *PasswordGenerator) SetPasswordType(passwordType PasswordType) error {
+	if _, ok := passwordTemplates[passwordType]; !ok {
+		return fmt.Errorf("password_type_unknown")
+	}
+	pg.template = passwordType
+	return nil
+}
+
+// PasswordType возвращает тип шаблона генератора
+func (pg *PasswordGenerator) PasswordType() PasswordType {
+	return pg.template
+}
+
+// generateFromTemplate отображает хеш на шаблон: первый байт выбирает шаблон,
+// каждый следующий байт выбирает символ из класса очередной позиции
+func (pg *PasswordGenerator) generateFromTemplate(hash []byte) ([]byte, error) {
+	templates, ok := passwordTemplates[pg.template]
+	if !ok {
+		return nil, fmt.Errorf("password_type_unknown")
+	}
+
+	// Байт выбора шаблона и по байту на каждый символ самого длинного шаблона
+	size := 1
+	for _, template := range templates {
+		size = max(size, len(template)+1)
+	}
+	material, err := pg.Algorithm().material(hash, purposeTemplate, size)
+	if err != nil {
+		return nil, err
+	}
+
+	template := templates[int(material[0])%len(templates)]
+	password := make([]byte, len(template))
+	for i := 0; i < len(template); i++ {
+		chars := templateClasses[template[i]]
+		password[i] = chars[int(material[i+1])%len(chars)]
+	}
+	return password, nil
+}
@@ -0,0 +1,209 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+func TestParsePasswordType(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		expected  PasswordType
+		wantError bool
+	}{
+		{"Пустая строка", "", "", false},
+		{"Без шаблона", "none", "", false},
+		{"PIN", "pin", TypePIN, false},
+		{"Регистр и пробелы", " Basic ", TypeBasic, false},
+		{"Неизвестный тип", "medium_rare", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passwordType, err := ParsePasswordType(tt.spec)
+
+			if tt.wantError {
+				if err == nil || err.Error() != "password_type_unknown" {
+					t.Errorf("ParsePasswordType(%q) ошибка = %v, ожидается password_type_unknown", tt.spec, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePasswordType(%q) неожиданная ошибка: %v", tt.spec, err)
+			}
+			if passwordType != tt.expected {
+				t.Errorf("ParsePasswordType(%q) = %q, ожидается %q", tt.spec, passwordType, tt.expected)
+			}
+		})
+	}
+
+	for _, name := range PasswordTypeNames() {
+		if _, err := ParsePasswordType(name); err != nil {
+			t.Errorf("ParsePasswordType(%q) ошибка: %v", name, err)
+		}
+	}
+}
+
+func TestTemplateEntropy(t *testing.T) {
+	tests := []struct {
+		passwordType PasswordType
+		expected     float64
+	}{
+		{TypePIN, 4 * math.Log2(10)},
+		{TypeShort, math.Log2(21) + math.Log2(5) + math.Log2(21) + math.Log2(10)},
+		{TypeBasic, 5*math.Log2(52) + 3*math.Log2(10) + math.Log2(3)}, // самый слабый шаблон "aannaaan"
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.passwordType), func(t *testing.T) {
+			if got := TemplateEntropy(tt.passwordType); math.Abs(got-tt.expected) > 0.001 {
+				t.Errorf("TemplateEntropy(%q) = %v, ожидается %v", tt.passwordType, got, tt.expected)
+			}
+		})
+	}
+
+	if got := TemplateEntropy("unknown"); got != 0 {
+		t.Errorf("TemplateEntropy(unknown) = %v, ожидается 0", got)
+	}
+}
+
+func TestGenerateFromTemplate(t *testing.T) {
+	// Нулевой хеш выбирает первый шаблон и первый символ каждого класса
+	zero := make([]byte, minTemplateHashSize)
+
+	tests := []struct {
+		passwordType PasswordType
+		expected     string
+	}{
+		{TypePIN, "0000"},
+		{TypeBasic, "AAA0AAA0"},
+		{TypeShort, "Bab0"},
+		{TypeName, "babbababa"},
+		{TypeMaximum, "A0@AAAAAAAAAAAAAAAAA"},
+		{TypePhrase, "babb bab babbaba bab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.passwordType), func(t *testing.T) {
+			pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+			if err := pg.SetPasswordType(tt.passwordType); err != nil {
+				t.Fatalf("SetPasswordType() ошибка: %v", err)
+			}
+			password, err := pg.generateFromTemplate(zero)
+			if err != nil {
+				t.Fatalf("generateFromTemplate() ошибка: %v", err)
+			}
+			if string(password) != tt.expected {
+				t.Errorf("generateFromTemplate() = %q, ожидается %q", password, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Выбор шаблона первым байтом", func(t *testing.T) {
+		pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+		_ = pg.SetPasswordType(TypeBasic)
+
+		hash := make([]byte, minTemplateHashSize)
+		hash[0] = 2 // третий шаблон "aaannaaa"
+		password, _ := pg.generateFromTemplate(hash)
+		if string(password) != "AAA00AAA" {
+			t.Errorf("generateFromTemplate() = %q, ожидается %q", password, "AAA00AAA")
+		}
+	})
+
+	t.Run("Короткий хеш", func(t *testing.T) {
+		pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+		_ = pg.SetPasswordType(TypeMaximum)
+		if _, err := pg.generateFromTemplate(make([]byte, 8)); err == nil || err.Error() != "hash_too_short" {
+			t.Errorf("generateFromTemplate() ошибка = %v, ожидается hash_too_short", err)
+		}
+	})
+}
+
+func TestSetPasswordTypeUnknown(t *testing.T) {
+	pg := NewPasswordGeneratorWithConfig(16, ArgonConfig{}, DefaultCharset())
+	if err := pg.SetPasswordType("unknown"); err == nil {
+		t.Error("SetPasswordType(unknown) должен вернуть ошибку")
+	}
+	if pg.PasswordType() != "" {
+		t.Errorf("PasswordType() = %q, ожидается пустая строка", pg.PasswordType())
+	}
+}
+
+func TestGeneratePasswordWithTemplate(t *testing.T) {
+	masterPassword := security.NewSecureString("testmaster")
+	defer masterPassword.Clear()
+
+	generate := func(t *testing.T, length int, config ArgonConfig, passwordType PasswordType) string {
+		t.Helper()
+		pg := NewPasswordGeneratorWithConfig(length, config, DefaultCharset())
+		pg.SetMode(ModeTemplate)
+		if err := pg.SetPasswordType(passwordType); err != nil {
+			t.Fatalf("SetPasswordType() ошибка: %v", err)
+		}
+		password, err := pg.GeneratePassword(masterPassword, "bank", "testuser")
+		if err != nil {
+			t.Fatalf("GeneratePassword() ошибка: %v", err)
+		}
+		defer password.Clear()
+		return password.String()
+	}
+
+	config := ArgonConfig{
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 1,
+		KeyLen:  32,
+	}
+
+	for _, name := range PasswordTypeNames() {
+		passwordType := PasswordType(name)
+		t.Run(name, func(t *testing.T) {
+			password := generate(t, 16, config, passwordType)
+			if password != generate(t, 16, config, passwordType) {
+				t.Errorf("Пароль типа %s не детерминирован", name)
+			}
+			if !matchesAnyTemplate(password, passwordTemplates[passwordType]) {
+				t.Errorf("Пароль %q не соответствует ни одному шаблону типа %s", password, name)
+			}
+		})
+	}
+
+	t.Run("Не зависит от длины пароля", func(t *testing.T) {
+		if generate(t, 16, config, TypeLong) != generate(t, 64, config, TypeLong) {
+			t.Error("Шаблонный пароль зависит от --length")
+		}
+	})
+
+	t.Run("Маленький размер ключа Argon2", func(t *testing.T) {
+		small := config
+		small.KeyLen = 8
+		password := generate(t, 16, small, TypeMaximum)
+		if len(password) != 20 {
+			t.Errorf("Длина пароля = %d, ожидается 20", len(password))
+		}
+	})
+}
+
+func matchesAnyTemplate(password string, templates []string) bool {
+	for _, template := range templates {
+		if len(template) != len(password) {
+			continue
+		}
+		matches := true
+		for i := 0; i < len(template); i++ {
+			if !strings.ContainsRune(templateClasses[template[i]], rune(password[i])) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
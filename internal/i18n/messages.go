@@ -178,6 +178,338 @@ type Messages struct {
 	ConfigCharsetValues        string
 	ConfigTimeoutRange         string
 
+	// Обязательные классы символов
+	ConfigInvalidRequiredClasses string
+	ConfigRequiredClassesValues  string
+	RequiredClassUnavailable     string
+	RequiredClassesTooMany       string
+	RequireFlagDesc              string
+
+	// Счётчик пароля сервиса
+	CounterLabel    string
+	CounterTooSmall string
+	CounterTooLarge string
+	CounterFlagDesc string
+
+	// Парольные фразы
+	PassphraseFlagDesc                string
+	WordsFlagDesc                     string
+	SeparatorFlagDesc                 string
+	WordlistFlagDesc                  string
+	CapitalizeFlagDesc                string
+	DigitFlagDesc                     string
+	WordsLabel                        string
+	WordlistLabel                     string
+	WordsTooFew                       string
+	WordsTooMany                      string
+	SeparatorTooLong                  string
+	SeparatorInvalid                  string
+	WordlistUnknown                   string
+	ConfigInvalidPassphraseWords      string
+	ConfigInvalidPassphraseCapitalize string
+	ConfigInvalidPassphraseDigit      string
+
+	// Шаблоны паролей
+	TypeFlagDesc              string
+	TypeLabel                 string
+	ConfigInvalidPasswordType string
+	ConfigPasswordTypeValues  string
+
+	// Версии алгоритма генерации
+	AlgorithmFlagDesc            string
+	AlgorithmInfo                string
+	ConfigInvalidAlgorithm       string
+	ConfigAlgorithmValues        string
+	ConfigIdenticonValues        string
+	ConfigServiceCanonicalValues string
+	ConfigServiceRecordValues    string
+
+	// Пакетная генерация
+	BatchShort                string
+	BatchLong                 string
+	BatchFormatFlagDesc       string
+	BatchOutFlagDesc          string
+	BatchWorkersFlagDesc      string
+	BatchMemoryBudgetFlagDesc string
+	BatchLengthFlagDesc       string
+	BatchReadError            string
+	BatchWriteError           string
+	BatchNoServices           string
+	BatchUnknownFormat        string
+	BatchInvalidWorkers       string
+	BatchLineError            string
+	BatchUnknownOverride      string
+	BatchInvalidLength        string
+	BatchInvalidCounter       string
+	BatchEmptyUsername        string
+	BatchWorkersInfo          string
+	BatchGenerated            string
+	BatchWrittenTo            string
+	BatchColumnService        string
+	BatchColumnUsername       string
+	BatchColumnCounter        string
+	BatchColumnPassword       string
+	BatchErrorPrefix          string
+
+	// Команда get
+	GetShort               string
+	GetLong                string
+	GetMasterStdinFlagDesc string
+	GetMasterFdFlagDesc    string
+	GetMasterFileFlagDesc  string
+	GetRawFlagDesc         string
+	GetMasterMissing       string
+	GetMasterFileInsecure  string
+	GetMasterTooLong       string
+	GetMasterFdInvalid     string
+	GetMasterReadError     string
+	GetSummary             string
+
+	// Формат вывода --output
+	OutputFlagDesc   string
+	OutputUnknown    string
+	OutputValues     string
+	OutputWriteError string
+
+	// Агент мастер-пароля
+	AgentShort               string
+	AgentLong                string
+	AgentStartShort          string
+	AgentStatusShort         string
+	AgentLockShort           string
+	AgentUnlockShort         string
+	AgentStopShort           string
+	AgentIdleTimeoutFlagDesc string
+	AgentMaxLifetimeFlagDesc string
+	AgentForegroundFlagDesc  string
+	AgentStarted             string
+	AgentListening           string
+	AgentStatusRunning       string
+	AgentStatusLocked        string
+	AgentStatusUnlocked      string
+	AgentStatusIdle          string
+	AgentStatusExpires       string
+	AgentStatusKeys          string
+	AgentLocked              string
+	AgentUnlocked            string
+	AgentStopped             string
+	AgentMasterMissing       string
+	AgentNotRunning          string
+	AgentAlreadyRunning      string
+	AgentLockedError         string
+	AgentSocketInsecure      string
+	AgentPeerUnsupported     string
+	AgentPeerDenied          string
+	AgentPeerForeign         string
+	AgentMemoryLockFailed    string
+	AgentProtocolMismatch    string
+	AgentStartFailed         string
+	AgentStartTimeout        string
+	AgentError               string
+
+	// Интерактивная сессия shell
+	ShellShort               string
+	ShellLong                string
+	ShellIdleTimeoutFlagDesc string
+	ShellNoHistoryFlagDesc   string
+	ShellWelcome             string
+	ShellPrompt              string
+	ShellHelp                string
+	ShellDerivingKey         string
+	ShellLengthSet           string
+	ShellCounterSet          string
+	ShellNoPassword          string
+	ShellHistoryEmpty        string
+	ShellUnknownCommand      string
+	ShellArgumentMissing     string
+	ShellArgumentInvalid     string
+	ShellHistoryMissing      string
+	ShellTimeout             string
+	ShellBye                 string
+	ShellHistorySaveError    string
+
+	// Реестр сервисов
+	SiteShort           string
+	SiteLong            string
+	SiteAddShort        string
+	SiteEditShort       string
+	SiteRmShort         string
+	SiteLsShort         string
+	SiteShowShort       string
+	SiteUserFlagDesc    string
+	SiteCharsetFlagDesc string
+	SiteNoteFlagDesc    string
+	SiteUnsetFlagDesc   string
+	SiteAdded           string
+	SiteUpdated         string
+	SiteRemoved         string
+	SiteEmpty           string
+	SiteDefaultsInfo    string
+	SiteApplied         string
+	SiteNotFound        string
+	SiteExists          string
+	SiteFieldUnknown    string
+	SiteInvalid         string
+	SiteVersion         string
+	SiteLoadError       string
+	SiteSaveError       string
+	SiteColumnService   string
+	SiteColumnUser      string
+	SiteColumnLength    string
+	SiteColumnCounter   string
+	SiteColumnMode      string
+	SiteColumnNote      string
+
+	// Зашифрованное хранилище
+	VaultShort            string
+	VaultLong             string
+	VaultInitShort        string
+	VaultStatusShort      string
+	VaultUserShort        string
+	VaultDisableShort     string
+	VaultConfirmMaster    string
+	VaultConfirmMismatch  string
+	VaultCreated          string
+	VaultDisabled         string
+	VaultUserSet          string
+	VaultStatusEnabled    string
+	VaultStatusDisabled   string
+	VaultStatusSites      string
+	VaultStatusKDF        string
+	VaultHiddenUser       string
+	VaultExists           string
+	VaultNotEnabled       string
+	VaultMasterMismatch   string
+	VaultInvalid          string
+	VaultVersion          string
+	VaultLoadError        string
+	VaultSaveError        string
+	VaultAgentUnsupported string
+	VaultUsernameHint     string
+
+	// Профили
+	ProfileShort             string
+	ProfileLong              string
+	ProfileCreateShort       string
+	ProfileUseShort          string
+	ProfileListShort         string
+	ProfileRmShort           string
+	ProfileRenameShort       string
+	ProfileFlagDesc          string
+	ProfileUserFlagDesc      string
+	ProfileCopyFlagDesc      string
+	ProfileYesFlagDesc       string
+	ProfileCreated           string
+	ProfileSwitched          string
+	ProfileRemoved           string
+	ProfileRenamed           string
+	ProfileRmConfirm         string
+	ProfileRmCancelled       string
+	ProfileRmConfirmRequired string
+	ProfileNotFound          string
+	ProfileExists            string
+	ProfileNameInvalid       string
+	ProfileDefaultError      string
+	ProfileActiveError       string
+	ProfileError             string
+	ProfileColumnName        string
+	ProfileColumnUser        string
+	ProfileColumnAlgorithm   string
+	ProfileColumnPasswords   string
+
+	// Проверка мастер-пароля
+	MasterShort            string
+	MasterLong             string
+	MasterSetCheckShort    string
+	MasterCheckShort       string
+	MasterClearCheckShort  string
+	MasterBitsFlagDesc     string
+	MasterRefuseFlagDesc   string
+	MasterCheckSaved       string
+	MasterCheckCleared     string
+	MasterCheckMatch       string
+	MasterCheckMismatch    string
+	MasterCheckWarning     string
+	MasterCheckNotSet      string
+	MasterCheckBitsInvalid string
+	MasterCheckInvalid     string
+	MasterCheckError       string
+
+	// Отпечаток мастер-пароля
+	IdenticonLabel string
+
+	// Калибровка Argon2
+	CalibrateShort             string
+	CalibrateLong              string
+	CalibrateTargetFlagDesc    string
+	CalibrateMaxMemoryFlagDesc string
+	CalibrateThreadsFlagDesc   string
+	CalibrateApplyFlagDesc     string
+	CalibrateRunning           string
+	CalibrateColumnTime        string
+	CalibrateColumnMemory      string
+	CalibrateColumnThreads     string
+	CalibrateColumnDuration    string
+	CalibrateRecommended       string
+	CalibrateNoneFits          string
+	CalibrateCurrent           string
+	CalibrateUnchanged         string
+	CalibrateWarning           string
+	CalibrateApplyHint         string
+	CalibrateApplied           string
+	CalibrateTargetInvalid     string
+	CalibrateMemoryInvalid     string
+	CalibrateMemoryRange       string
+	CalibrateThreadsInvalid    string
+	CalibrateError             string
+
+	// Защита от смены паролей при изменении настроек
+	DriftForceFlagDesc   string
+	DriftWarning         string
+	DriftSites           string
+	DriftOtherServices   string
+	DriftVaultSites      string
+	DriftConfirm         string
+	DriftConfirmRequired string
+	DriftCancelled       string
+	DriftSnapshotSaved   string
+	DriftSnapshotError   string
+
+	// История настроек генерации и снимки --snapshot
+	HistoryShort           string
+	HistoryEmpty           string
+	HistoryColumnReplaced  string
+	HistoryColumnUser      string
+	HistoryColumnAlgorithm string
+	HistoryColumnCharset   string
+	HistoryColumnLength    string
+	HistoryLoadError       string
+	HistoryInvalid         string
+	HistoryVersion         string
+	SnapshotFlagDesc       string
+	SnapshotNotFound       string
+	SnapshotApplied        string
+
+	// Приведение имени сервиса
+	RawServiceFlagDesc   string
+	ServiceCanonicalized string
+
+	// Проверка имени сервиса на опечатки
+	NewServiceFlagDesc          string
+	ServiceTypo                 string
+	ServiceTypoList             string
+	ServiceTypoChoose           string
+	ServiceTypoNewHint          string
+	ServiceTypoShellHint        string
+	ServiceRecordError          string
+	ServiceRecordInvalid        string
+	ServiceRecordVersion        string
+	ServiceRecordMasterMismatch string
+
+	// Имя пользователя для одного запуска
+	UserFlagDesc    string
+	UsernameApplied string
+
 	// Метрики и статистика
 	MetricsTitle       string
 	ProfileStatistics  string
@@ -436,9 +768,341 @@ func GetMessages(lang Language, version string) *Messages {
 			ProfileLabel:               "профиль:",
 			ConfigLengthRange:          "default_length должен быть от 4 до 128",
 			ConfigLanguageValues:       "default_language должен быть 'ru', 'en' или 'auto'",
-			ConfigCharsetValues:        "character_set должен быть 'alphanumeric', 'alphanumeric_symbols' или 'symbols_only'",
+			ConfigCharsetValues:        "character_set должен быть 'alphanumeric', 'alphanumeric_symbols', 'symbols_only', 'no_ambiguous', 'custom:<символы>' или 'exclude:<символы>' (печатные ASCII, минимум 2 различных)",
 			ConfigTimeoutRange:         "default_clear_timeout должен быть >= 0",
 
+			// Обязательные классы символов
+			ConfigInvalidRequiredClasses: "Неверное значение required_classes:",
+			ConfigRequiredClassesValues:  "required_classes должен быть списком через запятую из 'upper', 'lower', 'digit', 'symbol' или 'none'",
+			RequiredClassUnavailable:     "Обязательный класс символов отсутствует в выбранном наборе символов",
+			RequiredClassesTooMany:       "Обязательных классов символов больше, чем длина пароля",
+			RequireFlagDesc:              "Обязательные классы символов (upper,lower,digit,symbol)",
+
+			// Счётчик пароля сервиса
+			CounterLabel:    "Счётчик:",
+			CounterTooSmall: "Минимальное значение счётчика: 1",
+			CounterTooLarge: "Максимальное значение счётчика: 4294967295",
+			CounterFlagDesc: "Счётчик пароля сервиса (увеличьте для смены пароля)",
+
+			// Парольные фразы
+			PassphraseFlagDesc:                "Сгенерировать парольную фразу из слов вместо пароля",
+			WordsFlagDesc:                     "Количество слов в парольной фразе (3-20)",
+			SeparatorFlagDesc:                 "Разделитель слов парольной фразы",
+			WordlistFlagDesc:                  "Словарь парольной фразы: en (EFF, 7776 слов) или ru (1024 слова)",
+			CapitalizeFlagDesc:                "Начинать каждое слово парольной фразы с заглавной буквы",
+			DigitFlagDesc:                     "Добавить цифру к одному из слов парольной фразы",
+			WordsLabel:                        "Слов:",
+			WordlistLabel:                     "словарь",
+			WordsTooFew:                       "Минимальное количество слов: 3",
+			WordsTooMany:                      "Максимальное количество слов: 20",
+			SeparatorTooLong:                  "Разделитель не может быть длиннее 4 символов",
+			SeparatorInvalid:                  "Разделитель не может содержать буквы и управляющие символы",
+			WordlistUnknown:                   "Неизвестный словарь, доступны: en, ru",
+			ConfigInvalidPassphraseWords:      "Неверное значение passphrase_words:",
+			ConfigInvalidPassphraseCapitalize: "Неверное значение passphrase_capitalize:",
+			ConfigInvalidPassphraseDigit:      "Неверное значение passphrase_digit:",
+
+			// Шаблоны паролей
+			TypeFlagDesc:              "Шаблон пароля: maximum, long, medium, short, basic, pin, name, phrase или none",
+			TypeLabel:                 "Шаблон:",
+			ConfigInvalidPasswordType: "Неверное значение password_type:",
+			ConfigPasswordTypeValues:  "password_type должен быть 'maximum', 'long', 'medium', 'short', 'basic', 'pin', 'name', 'phrase' или 'none'",
+
+			// Версии алгоритма генерации
+			AlgorithmFlagDesc:            "Версия алгоритма генерации: v1 (прежние пароли), v2 (Argon2id → HKDF-SHA256), v3 (мастер-ключ + HMAC на сайт) или v4 (v2 с нормализацией Unicode и имени сервиса)",
+			AlgorithmInfo:                "Алгоритм генерации: %s",
+			ConfigInvalidAlgorithm:       "Неверное значение algorithm:",
+			ConfigAlgorithmValues:        "algorithm должен быть 'v1', 'v2', 'v3' или 'v4'",
+			ConfigIdenticonValues:        "identicon должен быть 'unicode', 'ascii' или 'off'",
+			ConfigServiceCanonicalValues: "service_canonical должен быть 'off', 'host' или 'domain'",
+			ConfigServiceRecordValues:    "service_record должен быть 'plain', 'blinded' или 'off'",
+
+			// Пакетная генерация
+			BatchShort:                "Сгенерировать пароли для списка сервисов",
+			BatchLong:                 "Читает список сервисов из файла или stdin и генерирует пароли с одним вводом мастер-пароля.\nФормат строки: сервис [length=N] [counter=N] [user=ИМЯ]. Слова с = и другим ключом относятся к имени сервиса. Пустые строки и строки с # пропускаются.\nЕсли список читается из stdin без терминала, первой строкой идёт мастер-пароль.",
+			BatchFormatFlagDesc:       "Формат вывода: table, json или csv (в CSV имена, похожие на формулы, начинаются с ', пароль выводится как есть)",
+			BatchOutFlagDesc:          "Записать результат в файл (права 0600) вместо stdout",
+			BatchWorkersFlagDesc:      "Максимальное число параллельных вычислений",
+			BatchMemoryBudgetFlagDesc: "Бюджет памяти Argon2 в МиБ на все параллельные вычисления",
+			BatchLengthFlagDesc:       "Длина пароля для строк без length=",
+			BatchReadError:            "Ошибка чтения списка сервисов:",
+			BatchWriteError:           "Ошибка записи результата:",
+			BatchNoServices:           "Список сервисов пуст",
+			BatchUnknownFormat:        "Неизвестный формат вывода, доступны: table, json, csv",
+			BatchInvalidWorkers:       "Число параллельных вычислений и бюджет памяти должны быть положительными",
+			BatchLineError:            "Строка %d: %s",
+			BatchUnknownOverride:      "после параметров имя сервиса продолжаться не может, доступны length=, counter=, user=",
+			BatchInvalidLength:        "length должен быть от 4 до 128",
+			BatchInvalidCounter:       "counter должен быть от 1 до 4294967295",
+			BatchEmptyUsername:        "user не может быть пустым",
+			BatchWorkersInfo:          "Параллельных вычислений: %d (бюджет памяти %d МиБ)",
+			BatchGenerated:            "Сгенерировано паролей: %d из %d",
+			BatchWrittenTo:            "Результат записан в",
+			BatchColumnService:        "СЕРВИС",
+			BatchColumnUsername:       "ПОЛЬЗОВАТЕЛЬ",
+			BatchColumnCounter:        "СЧЁТЧИК",
+			BatchColumnPassword:       "ПАРОЛЬ",
+			BatchErrorPrefix:          "ошибка:",
+
+			// Команда get
+			GetShort:               "Сгенерировать пароль без интерактивного ввода",
+			GetLong:                "Генерирует пароль сервиса для скриптов и CI: без баннера, подсказок и вопросов.\nМастер-пароль берётся из --master-fd, --master-file, --master-stdin или файла из PGEN_MASTER_FILE.\nБез источника пароль запрашивается у запущенного агента (pgen agent start).\nФайл мастер-пароля должен быть доступен только владельцу (0600).\n\nКоды завершения:\n  0  пароль сгенерирован\n  1  прочая ошибка\n  2  неверные аргументы или флаги, имя похоже на известный сервис без --new\n  3  мастер-пароль не получен\n  4  ошибка конфигурации или параметров генерации\n  5  ошибка генерации",
+			GetMasterStdinFlagDesc: "Прочитать мастер-пароль из первой строки stdin",
+			GetMasterFdFlagDesc:    "Прочитать мастер-пароль из открытого файлового дескриптора",
+			GetMasterFileFlagDesc:  "Прочитать мастер-пароль из файла с правами 0600",
+			GetRawFlagDesc:         "Вывести только пароль, без перевода строки и сводки",
+			GetMasterMissing:       "Мастер-пароль не задан: укажите --master-stdin, --master-fd, --master-file, PGEN_MASTER_FILE или запустите pgen agent start",
+			GetMasterFileInsecure:  "Файл мастер-пароля доступен группе или другим пользователям, выполните chmod 600",
+			GetMasterTooLong:       "Мастер-пароль слишком длинный",
+			GetMasterFdInvalid:     "Недопустимый файловый дескриптор мастер-пароля",
+			GetMasterReadError:     "Ошибка чтения мастер-пароля:",
+			GetSummary:             "Пароль для %s, длина %d",
+
+			// Формат вывода --output
+			OutputFlagDesc:   "Формат вывода: text или json",
+			OutputUnknown:    "Неизвестный формат вывода.",
+			OutputValues:     "Допустимые значения: text, json",
+			OutputWriteError: "Ошибка вывода:",
+
+			// Агент мастер-пароля
+			AgentShort:               "Агент, хранящий мастер-пароль в памяти",
+			AgentLong:                "Фоновый агент в стиле ssh-agent: мастер-пароль вводится один раз, хранится в закреплённой памяти,\nа pgen get без источника мастер-пароля получает пароли от агента через UNIX сокет.\nСокет доступен только владельцу (0600), агент сверяет UID собеседника.\nПо умолчанию сокет: $XDG_RUNTIME_DIR/pgen/agent.sock, переопределяется PGEN_AGENT_SOCK.\nАгент стирает секреты после простоя (--idle-timeout) и завершается по истечении --max-lifetime.",
+			AgentStartShort:          "Запустить агент",
+			AgentStatusShort:         "Показать состояние агента",
+			AgentLockShort:           "Стереть секреты агента, не останавливая его",
+			AgentUnlockShort:         "Передать мастер-пароль заблокированному агенту",
+			AgentStopShort:           "Остановить агент",
+			AgentIdleTimeoutFlagDesc: "Стереть секреты после простоя (0 - не стирать)",
+			AgentMaxLifetimeFlagDesc: "Завершить агент через заданное время (0 - без ограничения)",
+			AgentForegroundFlagDesc:  "Не уходить в фон",
+			AgentStarted:             "Агент запущен (PID %d), сокет: %s",
+			AgentListening:           "Агент слушает сокет %s",
+			AgentStatusRunning:       "Агент работает (PID %d), сокет: %s",
+			AgentStatusLocked:        "Состояние: заблокирован",
+			AgentStatusUnlocked:      "Состояние: разблокирован",
+			AgentStatusIdle:          "Блокировка после простоя: %s",
+			AgentStatusExpires:       "Остановка через: %s",
+			AgentStatusKeys:          "Мастер-ключей v3 в памяти: %d",
+			AgentLocked:              "Агент заблокирован, секреты стёрты",
+			AgentUnlocked:            "Агент разблокирован",
+			AgentStopped:             "Агент остановлен",
+			AgentMasterMissing:       "Мастер-пароль не задан: введите его в терминале или укажите --master-stdin, --master-fd, --master-file",
+			AgentNotRunning:          "Агент не запущен, запустите его: pgen agent start",
+			AgentAlreadyRunning:      "Агент уже запущен",
+			AgentLockedError:         "Агент заблокирован, разблокируйте его: pgen agent unlock",
+			AgentSocketInsecure:      "Каталог сокета агента доступен другим пользователям или принадлежит не вам",
+			AgentPeerUnsupported:     "Агент недоступен на этой платформе: нельзя проверить UID собеседника",
+			AgentPeerDenied:          "Агент отклонил запрос другого пользователя",
+			AgentPeerForeign:         "Сокет агента открыт процессом другого пользователя, запрос не отправлен",
+			AgentMemoryLockFailed:    "Не удалось закрепить память для секретов (mlock), увеличьте ulimit -l",
+			AgentProtocolMismatch:    "Версия агента не совпадает с pgen, перезапустите агент",
+			AgentStartFailed:         "Не удалось запустить агент:",
+			AgentStartTimeout:        "Агент не ответил за %s",
+			AgentError:               "Ошибка агента:",
+
+			// Интерактивная сессия shell
+			ShellShort:               "Интерактивный сеанс: мастер-пароль вводится один раз для многих сервисов",
+			ShellLong:                "Интерактивный сеанс генерации: мастер-пароль вводится один раз, затем вводятся имена сервисов.\nКоманды сеанса:\n  :len N      длина следующих паролей\n  :counter N  счётчик следующих паролей\n  :copy       скопировать последний пароль в буфер обмена\n  :info       анализ последнего пароля\n  :history    история имён сервисов, !N повторяет запись N\n  :help       список команд\n  :quit       завершить сеанс (также Ctrl+D)\nВ историю попадают только имена сервисов, пароли не сохраняются.\nНовое имя, похожее на известный сервис, нужно ввести дважды.\nПосле простоя --idle-timeout мастер-пароль стирается и сеанс завершается.",
+			ShellIdleTimeoutFlagDesc: "Завершить сеанс после простоя (0 - не завершать)",
+			ShellNoHistoryFlagDesc:   "Не читать и не сохранять историю имён сервисов",
+			ShellWelcome:             "Введите имя сервиса или :help. Выход: :quit или Ctrl+D",
+			ShellPrompt:              "pgen> ",
+			ShellHelp:                "Команды: :len N, :counter N, :copy, :info, :history, !N, :help, :quit",
+			ShellDerivingKey:         "Вычисление мастер-ключа...",
+			ShellLengthSet:           "Длина следующих паролей: %d",
+			ShellCounterSet:          "Счётчик следующих паролей: %d",
+			ShellNoPassword:          "Пароль ещё не сгенерирован",
+			ShellHistoryEmpty:        "История пуста",
+			ShellUnknownCommand:      "Неизвестная команда %s, список команд: :help",
+			ShellArgumentMissing:     "Команде %s нужен числовой аргумент",
+			ShellArgumentInvalid:     "Недопустимый аргумент в %s",
+			ShellHistoryMissing:      "В истории нет записи %s",
+			ShellTimeout:             "Сеанс завершён после %s простоя, мастер-пароль стёрт",
+			ShellBye:                 "Сеанс завершён, мастер-пароль стёрт",
+			ShellHistorySaveError:    "Не удалось сохранить историю:",
+
+			// Реестр сервисов
+			SiteShort:           "Реестр сервисов: настройки генерации для каждого сервиса",
+			SiteLong:            "Реестр хранит настройки генерации каждого сервиса (длина, счётчик, пользователь, набор символов, тип),\nчтобы не повторять флаги. Пароли в реестре не хранятся.\nПри генерации для сервиса из реестра его настройки применяются автоматически, флаги имеют приоритет.\nРеестр хранится в файле sites.json рядом с config.json с правами 0600.",
+			SiteAddShort:        "Добавить сервис в реестр",
+			SiteEditShort:       "Изменить настройки сервиса",
+			SiteRmShort:         "Удалить сервис из реестра",
+			SiteLsShort:         "Список сервисов реестра",
+			SiteShowShort:       "Показать настройки сервиса",
+			SiteUserFlagDesc:    "Имя пользователя сервиса (входит в соль)",
+			SiteCharsetFlagDesc: "Набор символов сервиса",
+			SiteNoteFlagDesc:    "Заметка о сервисе",
+			SiteUnsetFlagDesc:   "Сбросить настройки к значениям конфигурации: %s",
+			SiteAdded:           "Сервис %s добавлен в реестр",
+			SiteUpdated:         "Настройки сервиса %s обновлены",
+			SiteRemoved:         "Сервис %s удалён из реестра",
+			SiteEmpty:           "Реестр пуст, добавьте сервис: pgen site add <сервис>",
+			SiteDefaultsInfo:    "Не заданные настройки берутся из конфигурации",
+			SiteApplied:         "Применены настройки сервиса из реестра",
+			SiteNotFound:        "Сервиса %s нет в реестре",
+			SiteExists:          "Сервис %s уже есть в реестре, измените его: pgen site edit",
+			SiteFieldUnknown:    "Неизвестная настройка %s, допустимые: %s",
+			SiteInvalid:         "Файл реестра сервисов повреждён",
+			SiteVersion:         "Файл реестра сервисов создан более новой версией pgen",
+			SiteLoadError:       "Не удалось прочитать реестр сервисов:",
+			SiteSaveError:       "Не удалось сохранить реестр сервисов:",
+			SiteColumnService:   "СЕРВИС",
+			SiteColumnUser:      "ПОЛЬЗОВАТЕЛЬ",
+			SiteColumnLength:    "ДЛИНА",
+			SiteColumnCounter:   "СЧЁТЧИК",
+			SiteColumnMode:      "ТИП",
+			SiteColumnNote:      "ЗАМЕТКА",
+
+			// Зашифрованное хранилище
+			VaultShort:            "Зашифрованное хранилище имени пользователя и реестра сервисов",
+			VaultLong:             "Хранилище шифрует имя пользователя и реестр сервисов ключом из мастер-пароля\n(Argon2id, HKDF, XChaCha20-Poly1305). Имена сервисов заменены на HMAC, поэтому файл\nбез мастер-пароля не показывает, на каких сервисах есть учётные записи.\nПосле pgen vault init имя пользователя удаляется из config.json, а sites.json и shell_history - с диска;\nистория pgen shell больше не сохраняется.\nКомандам, читающим хранилище, нужен мастер-пароль; агент с хранилищем не используется.",
+			VaultInitShort:        "Зашифровать имя пользователя и реестр сервисов",
+			VaultStatusShort:      "Показать состояние хранилища",
+			VaultUserShort:        "Изменить имя пользователя в хранилище",
+			VaultDisableShort:     "Расшифровать хранилище обратно в config.json и sites.json",
+			VaultConfirmMaster:    "Повторите мастер-пароль:",
+			VaultConfirmMismatch:  "Мастер-пароли не совпадают",
+			VaultCreated:          "Хранилище создано: сервисов %d, имя пользователя удалено из config.json",
+			VaultDisabled:         "Хранилище расшифровано в config.json и sites.json и удалено",
+			VaultUserSet:          "Имя пользователя в хранилище изменено",
+			VaultStatusEnabled:    "Хранилище включено:",
+			VaultStatusDisabled:   "Хранилище не создано, включите его: pgen vault init",
+			VaultStatusSites:      "Сервисов: %d",
+			VaultStatusKDF:        "Argon2id: время %d, память %d КиБ, потоки %d",
+			VaultHiddenUser:       "скрыт",
+			VaultExists:           "Хранилище уже создано",
+			VaultNotEnabled:       "Хранилище не создано, включите его: pgen vault init",
+			VaultMasterMismatch:   "Неверный мастер-пароль: хранилище не расшифровано",
+			VaultInvalid:          "Файл хранилища повреждён",
+			VaultVersion:          "Файл хранилища создан более новой версией pgen",
+			VaultLoadError:        "Не удалось прочитать хранилище:",
+			VaultSaveError:        "Не удалось сохранить хранилище:",
+			VaultAgentUnsupported: "Имя пользователя и реестр зашифрованы: укажите мастер-пароль через --master-file, --master-fd или --master-stdin",
+			VaultUsernameHint:     "Имя пользователя хранится в зашифрованном хранилище, измените его: pgen vault user <имя>",
+
+			// Профили
+			ProfileShort:             "Именованные профили: своё имя пользователя, параметры Argon2 и статистика",
+			ProfileLong:              "Профиль - отдельная конфигурация со своим именем пользователя, параметрами Argon2,\nнастройками генерации, статистикой, реестром сервисов и хранилищем.\nПрофиль default - это config.json, остальные лежат в каталоге profiles рядом с ним.\npgen profile use делает профиль активным, флаг --profile выбирает профиль на один запуск.",
+			ProfileCreateShort:       "Создать профиль",
+			ProfileUseShort:          "Сделать профиль активным",
+			ProfileListShort:         "Список профилей",
+			ProfileRmShort:           "Удалить профиль вместе с его реестром сервисов и хранилищем",
+			ProfileRenameShort:       "Переименовать профиль",
+			ProfileFlagDesc:          "Профиль для этого запуска",
+			ProfileUserFlagDesc:      "Имя пользователя нового профиля (входит в соль)",
+			ProfileCopyFlagDesc:      "Скопировать настройки текущего профиля вместо настроек по умолчанию",
+			ProfileYesFlagDesc:       "Удалить без подтверждения",
+			ProfileCreated:           "Профиль %s создан, переключитесь на него: pgen profile use %s",
+			ProfileSwitched:          "Активный профиль: %s",
+			ProfileRemoved:           "Профиль %s удалён",
+			ProfileRenamed:           "Профиль %s переименован в %s",
+			ProfileRmConfirm:         "Удалить профиль %s вместе с его реестром сервисов и хранилищем? (y/N):",
+			ProfileRmCancelled:       "Удаление профиля отменено",
+			ProfileRmConfirmRequired: "Подтвердите удаление профиля флагом --yes",
+			ProfileNotFound:          "Профиль %s не найден, список профилей: pgen profile list",
+			ProfileExists:            "Профиль %s уже существует",
+			ProfileNameInvalid:       "Недопустимое имя профиля %s: латинские буквы, цифры, '_' и '-', до 32 символов",
+			ProfileDefaultError:      "Профиль default нельзя удалить или переименовать",
+			ProfileActiveError:       "Профиль %s активен, сначала переключитесь на другой: pgen profile use default",
+			ProfileError:             "Ошибка профиля:",
+			ProfileColumnName:        "ПРОФИЛЬ",
+			ProfileColumnUser:        "ПОЛЬЗОВАТЕЛЬ",
+			ProfileColumnAlgorithm:   "АЛГОРИТМ",
+			ProfileColumnPasswords:   "ПАРОЛЕЙ",
+
+			// Проверка мастер-пароля
+			MasterShort:            "Проверка мастер-пароля от опечаток",
+			MasterLong:             "Проверка хранит несколько бит, вычисленных из мастер-пароля через Argon2id,\nи предупреждает об опечатке до генерации. Бит намеренно мало: один неверный\nмастер-пароль из 2^bits проходит проверку, поэтому она не помогает подобрать\nмастер-пароль перебором. Проверка своя у каждого профиля.",
+			MasterSetCheckShort:    "Сохранить проверку мастер-пароля",
+			MasterCheckShort:       "Проверить мастер-пароль",
+			MasterClearCheckShort:  "Удалить проверку мастер-пароля",
+			MasterBitsFlagDesc:     "Число бит проверки, от %d до %d: больше бит - меньше ложных совпадений, но больше пользы для перебора",
+			MasterRefuseFlagDesc:   "Отказывать в генерации при несовпадении вместо предупреждения",
+			MasterCheckSaved:       "Проверка мастер-пароля сохранена: %d бит, неверный мастер-пароль проходит её с вероятностью 1 из %d",
+			MasterCheckCleared:     "Проверка мастер-пароля удалена",
+			MasterCheckMatch:       "Мастер-пароль прошёл проверку",
+			MasterCheckMismatch:    "Мастер-пароль не совпадает с сохранённой проверкой, вероятно, опечатка",
+			MasterCheckWarning:     "Мастер-пароль не прошёл проверку, вероятно, опечатка: пароли будут другими",
+			MasterCheckNotSet:      "Проверка мастер-пароля не задана, сохраните её: pgen master set-check",
+			MasterCheckBitsInvalid: "Число бит проверки должно быть от %d до %d",
+			MasterCheckInvalid:     "Сохранённая проверка мастер-пароля повреждена, задайте её заново: pgen master set-check",
+			MasterCheckError:       "Ошибка проверки мастер-пароля:",
+
+			// Отпечаток мастер-пароля
+			IdenticonLabel: "Отпечаток мастер-пароля:",
+
+			// Калибровка Argon2
+			CalibrateShort:             "Подобрать параметры Argon2 под эту машину",
+			CalibrateLong:              "Замеряет Argon2id на сетке значений памяти и числа итераций и рекомендует самые\nдорогие параметры, которые укладываются в заданное время. Память важнее итераций.\nС --apply параметры записываются в текущий профиль.\n\nВНИМАНИЕ: параметры Argon2 входят в вычисление каждого пароля. После их смены\nвсе пароли станут другими. Сначала смените пароли на сайтах или создайте\nдля новых параметров отдельный профиль.",
+			CalibrateTargetFlagDesc:    "Желаемое время одного вычисления, например 500ms или 1s",
+			CalibrateMaxMemoryFlagDesc: "Предел памяти, например 512MiB или 1GiB",
+			CalibrateThreadsFlagDesc:   "Число потоков Argon2 (0 - из профиля)",
+			CalibrateApplyFlagDesc:     "Записать рекомендуемые параметры в текущий профиль",
+			CalibrateRunning:           "Замер Argon2id: цель %s, до %d МиБ, потоков: %d...",
+			CalibrateColumnTime:        "ИТЕРАЦИИ",
+			CalibrateColumnMemory:      "ПАМЯТЬ",
+			CalibrateColumnThreads:     "ПОТОКИ",
+			CalibrateColumnDuration:    "ВРЕМЯ",
+			CalibrateRecommended:       "Рекомендуемые параметры: t=%d, m=%d МиБ, p=%d (%s)",
+			CalibrateNoneFits:          "Даже самые быстрые параметры дольше цели %s: рекомендуются они",
+			CalibrateCurrent:           "Текущие параметры профиля %s: t=%d, m=%d МиБ, p=%d",
+			CalibrateUnchanged:         "Рекомендуемые параметры совпадают с текущими",
+			CalibrateWarning:           "ВНИМАНИЕ: смена параметров Argon2 меняет КАЖДЫЙ сгенерированный пароль. Сначала смените пароли на сайтах или создайте отдельный профиль: pgen profile create",
+			CalibrateApplyHint:         "Записать параметры в текущий профиль: pgen calibrate --apply",
+			CalibrateApplied:           "Параметры Argon2 профиля %s: t=%d, m=%d МиБ, p=%d",
+			CalibrateTargetInvalid:     "Цель калибровки должна быть от %s до %s",
+			CalibrateMemoryInvalid:     "Неверный объём памяти %s: укажите, например, 512MiB или 1GiB",
+			CalibrateMemoryRange:       "Предел памяти должен быть от %d МиБ до %d МиБ",
+			CalibrateThreadsInvalid:    "Число потоков Argon2 должно быть больше нуля",
+			CalibrateError:             "Ошибка калибровки:",
+
+			// Защита от смены паролей при изменении настроек
+			DriftForceFlagDesc:   "Применить изменение без подтверждения, даже если пароли изменятся",
+			DriftWarning:         "ВНИМАНИЕ: меняются настройки, от которых зависят пароли:",
+			DriftSites:           "Изменятся пароли сервисов реестра (%d): %s",
+			DriftOtherServices:   "Изменятся и пароли всех сервисов вне реестра",
+			DriftVaultSites:      "Реестр сервисов зашифрован: изменятся пароли всех сервисов без собственных настроек",
+			DriftConfirm:         "Применить изменение? Прежние пароли останутся доступны через pgen get --snapshot (y/N):",
+			DriftConfirmRequired: "Изменение меняет пароли: подтвердите его флагом --force",
+			DriftCancelled:       "Конфигурация не изменена",
+			DriftSnapshotSaved:   "Прежние настройки сохранены как снимок %d, прежний пароль: pgen get --snapshot %d <сервис>",
+			DriftSnapshotError:   "Ошибка сохранения снимка настроек:",
+
+			// История настроек генерации и снимки --snapshot
+			HistoryShort:           "Снимки прежних настроек, от которых зависят пароли",
+			HistoryEmpty:           "Снимков нет: настройки, от которых зависят пароли, не менялись",
+			HistoryColumnReplaced:  "ЗАМЕНЁН",
+			HistoryColumnUser:      "ПОЛЬЗОВАТЕЛЬ",
+			HistoryColumnAlgorithm: "АЛГОРИТМ",
+			HistoryColumnCharset:   "СИМВОЛЫ",
+			HistoryColumnLength:    "ДЛИНА",
+			HistoryLoadError:       "Ошибка чтения снимков настроек:",
+			HistoryInvalid:         "Файл снимков настроек повреждён",
+			HistoryVersion:         "Файл снимков настроек создан более новой версией pgen",
+			SnapshotFlagDesc:       "Сгенерировать пароль с прежними настройками из снимка N (pgen config history)",
+			SnapshotNotFound:       "Снимок %d не найден, список снимков: pgen config history",
+			SnapshotApplied:        "Настройки снимка %d от %s",
+
+			// Приведение имени сервиса
+			RawServiceFlagDesc:   "Использовать имя сервиса как введено, без приведения URL к хосту или домену",
+			ServiceCanonicalized: "Сервис: %s",
+
+			// Проверка имени сервиса на опечатки
+			NewServiceFlagDesc:          "Подтвердить новый сервис без проверки имени на опечатку",
+			ServiceTypo:                 "%s — возможно, вы имели в виду %s?",
+			ServiceTypoList:             "%s — возможно, вы имели в виду:",
+			ServiceTypoChoose:           "Номер сервиса, n — новый сервис, Enter — отмена:",
+			ServiceTypoNewHint:          "Если это новый сервис, повторите команду с флагом --new",
+			ServiceTypoShellHint:        "Если это новый сервис, введите имя ещё раз",
+			ServiceRecordError:          "Не удалось обновить список сервисов:",
+			ServiceRecordInvalid:        "Файл списка сервисов повреждён",
+			ServiceRecordVersion:        "Файл списка сервисов создан более новой версией pgen",
+			ServiceRecordMasterMismatch: "Список сервисов скрыт другим мастер-паролем и не проверяется",
+
+			// Имя пользователя для одного запуска
+			UserFlagDesc:    "Имя пользователя для этого запуска: перекрывает имя из реестра сервисов и конфигурации",
+			UsernameApplied: "Имя пользователя: %s",
+
 			// Метрики и статистика
 			MetricsTitle:       "📊 Подробные метрики PGen",
 			ProfileStatistics:  "=== Статистика профиля [%s] ===",
@@ -565,6 +1229,29 @@ func GetMessages(lang Language, version string) *Messages {
   pgen --copy                  # Скопировать пароль в буфер
   pgen -c -t 30                # Скопировать с очисткой через 30 сек
   pgen --length 20             # Установить длину пароля
+  pgen --require digit,symbol  # Обязательно включить цифру и спецсимвол
+  pgen --counter 2             # Новый пароль после смены на сайте
+  pgen --passphrase --words 5  # Парольная фраза из пяти слов
+  pgen --type pin              # PIN-код из четырёх цифр
+  pgen batch services.txt      # Пароли для списка сервисов
+  pgen get github.com --master-file ~/.pgen-master --raw  # Пароль для скрипта
+  pgen --info --output json     # Результат и анализ в JSON
+  pgen agent start              # Ввести мастер-пароль один раз для pgen get
+  pgen shell                    # Сеанс: много сервисов за один ввод мастер-пароля
+  pgen site add github.com --length 24 --counter 2  # Запомнить настройки сервиса
+  pgen vault init               # Зашифровать имя пользователя и реестр сервисов
+  pgen profile create work --user alice  # Отдельный профиль для работы
+  pgen --profile work get github.com     # Пароль из профиля work
+  pgen master set-check                  # Сохранить проверку мастер-пароля от опечаток
+  pgen config set identicon ascii        # Отпечаток мастер-пароля без Unicode
+  pgen calibrate --target 1s             # Подобрать параметры Argon2 под машину
+  pgen get --snapshot 1 github.com       # Пароль с настройками до их смены
+  pgen get https://www.github.com/login  # Тот же пароль, что для github.com
+  pgen config set service_canonical domain # accounts.google.com → google.com
+  pgen get --raw-service "GitHub"        # Имя сервиса как введено
+  pgen get --new githib.com              # Новый сервис, похожий на известный
+  pgen config set service_record blinded # Скрыть список использованных сервисов
+  pgen get --user work github.com        # Пароль рабочего аккаунта
   pgen --lang en               # Использовать английский язык
   pgen --install               # Установить PGen в системные пути`,
 			Flags: struct {
@@ -807,9 +1494,341 @@ func GetMessages(lang Language, version string) *Messages {
 			ProfileLabel:               "profile:",
 			ConfigLengthRange:          "default_length must be between 4 and 128",
 			ConfigLanguageValues:       "default_language must be 'ru', 'en' or 'auto'",
-			ConfigCharsetValues:        "character_set must be 'alphanumeric', 'alphanumeric_symbols', or 'symbols_only'",
+			ConfigCharsetValues:        "character_set must be 'alphanumeric', 'alphanumeric_symbols', 'symbols_only', 'no_ambiguous', 'custom:<chars>' or 'exclude:<chars>' (printable ASCII, at least 2 distinct)",
 			ConfigTimeoutRange:         "default_clear_timeout must be >= 0",
 
+			// Обязательные классы символов
+			ConfigInvalidRequiredClasses: "Invalid required_classes value:",
+			ConfigRequiredClassesValues:  "required_classes must be a comma-separated list of 'upper', 'lower', 'digit', 'symbol' or 'none'",
+			RequiredClassUnavailable:     "Required character class is not available in the selected character set",
+			RequiredClassesTooMany:       "More required character classes than password length",
+			RequireFlagDesc:              "Required character classes (upper,lower,digit,symbol)",
+
+			// Счётчик пароля сервиса
+			CounterLabel:    "Counter:",
+			CounterTooSmall: "Minimum counter value: 1",
+			CounterTooLarge: "Maximum counter value: 4294967295",
+			CounterFlagDesc: "Service password counter (increase to rotate the password)",
+
+			// Парольные фразы
+			PassphraseFlagDesc:                "Generate a passphrase of words instead of a password",
+			WordsFlagDesc:                     "Number of words in the passphrase (3-20)",
+			SeparatorFlagDesc:                 "Passphrase word separator",
+			WordlistFlagDesc:                  "Passphrase wordlist: en (EFF, 7776 words) or ru (1024 words)",
+			CapitalizeFlagDesc:                "Capitalize each passphrase word",
+			DigitFlagDesc:                     "Append a digit to one of the passphrase words",
+			WordsLabel:                        "Words:",
+			WordlistLabel:                     "wordlist",
+			WordsTooFew:                       "Minimum number of words: 3",
+			WordsTooMany:                      "Maximum number of words: 20",
+			SeparatorTooLong:                  "Separator cannot be longer than 4 characters",
+			SeparatorInvalid:                  "Separator cannot contain letters or control characters",
+			WordlistUnknown:                   "Unknown wordlist, available: en, ru",
+			ConfigInvalidPassphraseWords:      "Invalid passphrase_words value:",
+			ConfigInvalidPassphraseCapitalize: "Invalid passphrase_capitalize value:",
+			ConfigInvalidPassphraseDigit:      "Invalid passphrase_digit value:",
+
+			// Шаблоны паролей
+			TypeFlagDesc:              "Password template: maximum, long, medium, short, basic, pin, name, phrase or none",
+			TypeLabel:                 "Template:",
+			ConfigInvalidPasswordType: "Invalid password_type value:",
+			ConfigPasswordTypeValues:  "password_type must be 'maximum', 'long', 'medium', 'short', 'basic', 'pin', 'name', 'phrase' or 'none'",
+
+			// Версии алгоритма генерации
+			AlgorithmFlagDesc:            "Generation algorithm version: v1 (existing passwords), v2 (Argon2id → HKDF-SHA256), v3 (master key + per-site HMAC) or v4 (v2 with Unicode and service name normalization)",
+			AlgorithmInfo:                "Generation algorithm: %s",
+			ConfigInvalidAlgorithm:       "Invalid algorithm value:",
+			ConfigAlgorithmValues:        "algorithm must be 'v1', 'v2', 'v3' or 'v4'",
+			ConfigIdenticonValues:        "identicon must be 'unicode', 'ascii' or 'off'",
+			ConfigServiceCanonicalValues: "service_canonical must be 'off', 'host' or 'domain'",
+			ConfigServiceRecordValues:    "service_record must be 'plain', 'blinded' or 'off'",
+
+			// Пакетная генерация
+			BatchShort:                "Generate passwords for a list of services",
+			BatchLong:                 "Reads a list of services from a file or stdin and generates passwords with a single master password prompt.\nLine format: service [length=N] [counter=N] [user=NAME]. Words with = and any other key belong to the service name. Blank lines and lines starting with # are skipped.\nWhen the list is piped through stdin, the first line is the master password.",
+			BatchFormatFlagDesc:       "Output format: table, json or csv (in CSV, names that look like formulas are prefixed with ', the password is written as is)",
+			BatchOutFlagDesc:          "Write the result to a file (mode 0600) instead of stdout",
+			BatchWorkersFlagDesc:      "Maximum number of parallel derivations",
+			BatchMemoryBudgetFlagDesc: "Argon2 memory budget in MiB shared by all parallel derivations",
+			BatchLengthFlagDesc:       "Password length for lines without length=",
+			BatchReadError:            "Error reading the service list:",
+			BatchWriteError:           "Error writing the result:",
+			BatchNoServices:           "The service list is empty",
+			BatchUnknownFormat:        "Unknown output format, available: table, json, csv",
+			BatchInvalidWorkers:       "Worker count and memory budget must be positive",
+			BatchLineError:            "Line %d: %s",
+			BatchUnknownOverride:      "the service name cannot continue after parameters, available: length=, counter=, user=",
+			BatchInvalidLength:        "length must be between 4 and 128",
+			BatchInvalidCounter:       "counter must be between 1 and 4294967295",
+			BatchEmptyUsername:        "user cannot be empty",
+			BatchWorkersInfo:          "Parallel derivations: %d (memory budget %d MiB)",
+			BatchGenerated:            "Passwords generated: %d of %d",
+			BatchWrittenTo:            "Result written to",
+			BatchColumnService:        "SERVICE",
+			BatchColumnUsername:       "USERNAME",
+			BatchColumnCounter:        "COUNTER",
+			BatchColumnPassword:       "PASSWORD",
+			BatchErrorPrefix:          "error:",
+
+			// Команда get
+			GetShort:               "Generate a password without interactive prompts",
+			GetLong:                "Generates a service password for scripts and CI: no banner, tips or prompts.\nThe master password comes from --master-fd, --master-file, --master-stdin or the file named by PGEN_MASTER_FILE.\nWithout a source the password is requested from a running agent (pgen agent start).\nThe master password file must be accessible by its owner only (0600).\n\nExit codes:\n  0  password generated\n  1  other error\n  2  invalid arguments or flags, or a name resembling a known service without --new\n  3  master password not obtained\n  4  configuration or generation options error\n  5  generation error",
+			GetMasterStdinFlagDesc: "Read the master password from the first line of stdin",
+			GetMasterFdFlagDesc:    "Read the master password from an open file descriptor",
+			GetMasterFileFlagDesc:  "Read the master password from a file with 0600 permissions",
+			GetRawFlagDesc:         "Print only the password, without a trailing newline or summary",
+			GetMasterMissing:       "No master password source: use --master-stdin, --master-fd, --master-file, PGEN_MASTER_FILE or run pgen agent start",
+			GetMasterFileInsecure:  "The master password file is accessible by group or others, run chmod 600",
+			GetMasterTooLong:       "The master password is too long",
+			GetMasterFdInvalid:     "Invalid master password file descriptor",
+			GetMasterReadError:     "Failed to read the master password:",
+			GetSummary:             "Password for %s, length %d",
+
+			// Формат вывода --output
+			OutputFlagDesc:   "Output format: text or json",
+			OutputUnknown:    "Unknown output format.",
+			OutputValues:     "Valid values: text, json",
+			OutputWriteError: "Output error:",
+
+			// Агент мастер-пароля
+			AgentShort:               "Agent that keeps the master password in memory",
+			AgentLong:                "Background ssh-agent style agent: the master password is entered once and kept in locked memory,\nand pgen get without a master password source obtains passwords from the agent over a UNIX socket.\nThe socket is accessible by its owner only (0600) and the agent checks the peer UID.\nDefault socket: $XDG_RUNTIME_DIR/pgen/agent.sock, overridden by PGEN_AGENT_SOCK.\nThe agent wipes its secrets after --idle-timeout of inactivity and exits after --max-lifetime.",
+			AgentStartShort:          "Start the agent",
+			AgentStatusShort:         "Show agent status",
+			AgentLockShort:           "Wipe the agent secrets without stopping it",
+			AgentUnlockShort:         "Give the master password to a locked agent",
+			AgentStopShort:           "Stop the agent",
+			AgentIdleTimeoutFlagDesc: "Wipe secrets after inactivity (0 disables)",
+			AgentMaxLifetimeFlagDesc: "Stop the agent after this time (0 disables)",
+			AgentForegroundFlagDesc:  "Do not detach into the background",
+			AgentStarted:             "Agent started (PID %d), socket: %s",
+			AgentListening:           "Agent is listening on %s",
+			AgentStatusRunning:       "Agent is running (PID %d), socket: %s",
+			AgentStatusLocked:        "State: locked",
+			AgentStatusUnlocked:      "State: unlocked",
+			AgentStatusIdle:          "Locks after inactivity: %s",
+			AgentStatusExpires:       "Stops in: %s",
+			AgentStatusKeys:          "v3 master keys in memory: %d",
+			AgentLocked:              "Agent locked, secrets wiped",
+			AgentUnlocked:            "Agent unlocked",
+			AgentStopped:             "Agent stopped",
+			AgentMasterMissing:       "No master password: type it in a terminal or use --master-stdin, --master-fd, --master-file",
+			AgentNotRunning:          "The agent is not running, start it with: pgen agent start",
+			AgentAlreadyRunning:      "The agent is already running",
+			AgentLockedError:         "The agent is locked, unlock it with: pgen agent unlock",
+			AgentSocketInsecure:      "The agent socket directory is accessible by other users or not owned by you",
+			AgentPeerUnsupported:     "The agent is unavailable on this platform: the peer UID cannot be checked",
+			AgentPeerDenied:          "The agent rejected a request from another user",
+			AgentPeerForeign:         "The agent socket is served by another user's process, the request was not sent",
+			AgentMemoryLockFailed:    "Failed to lock memory for secrets (mlock), raise ulimit -l",
+			AgentProtocolMismatch:    "The agent version does not match pgen, restart the agent",
+			AgentStartFailed:         "Failed to start the agent:",
+			AgentStartTimeout:        "The agent did not respond within %s",
+			AgentError:               "Agent error:",
+
+			// Интерактивная сессия shell
+			ShellShort:               "Interactive session: enter the master password once for many services",
+			ShellLong:                "Interactive generation session: enter the master password once, then type service names.\nSession commands:\n  :len N      length of the next passwords\n  :counter N  counter of the next passwords\n  :copy       copy the last password to the clipboard\n  :info       analysis of the last password\n  :history    service name history, !N repeats entry N\n  :help       list commands\n  :quit       end the session (also Ctrl+D)\nOnly service names are kept in the history, passwords are never stored.\nA new name resembling a known service must be entered twice.\nAfter --idle-timeout of inactivity the master password is wiped and the session ends.",
+			ShellIdleTimeoutFlagDesc: "End the session after inactivity (0 disables)",
+			ShellNoHistoryFlagDesc:   "Do not read or save the service name history",
+			ShellWelcome:             "Type a service name or :help. Exit with :quit or Ctrl+D",
+			ShellPrompt:              "pgen> ",
+			ShellHelp:                "Commands: :len N, :counter N, :copy, :info, :history, !N, :help, :quit",
+			ShellDerivingKey:         "Deriving the master key...",
+			ShellLengthSet:           "Length of the next passwords: %d",
+			ShellCounterSet:          "Counter of the next passwords: %d",
+			ShellNoPassword:          "No password has been generated yet",
+			ShellHistoryEmpty:        "The history is empty",
+			ShellUnknownCommand:      "Unknown command %s, list commands with :help",
+			ShellArgumentMissing:     "Command %s needs a numeric argument",
+			ShellArgumentInvalid:     "Invalid argument in %s",
+			ShellHistoryMissing:      "The history has no entry %s",
+			ShellTimeout:             "Session ended after %s of inactivity, master password wiped",
+			ShellBye:                 "Session ended, master password wiped",
+			ShellHistorySaveError:    "Failed to save the history:",
+
+			// Реестр сервисов
+			SiteShort:           "Site registry: generation settings per service",
+			SiteLong:            "The registry keeps generation settings per service (length, counter, user, charset, type)\nso flags don't have to be retyped. Passwords are never stored.\nWhen generating for a registered service its settings apply automatically, flags take precedence.\nThe registry is stored in sites.json next to config.json with 0600 permissions.",
+			SiteAddShort:        "Add a service to the registry",
+			SiteEditShort:       "Change service settings",
+			SiteRmShort:         "Remove a service from the registry",
+			SiteLsShort:         "List registered services",
+			SiteShowShort:       "Show service settings",
+			SiteUserFlagDesc:    "Service username (part of the salt)",
+			SiteCharsetFlagDesc: "Service character set",
+			SiteNoteFlagDesc:    "Note about the service",
+			SiteUnsetFlagDesc:   "Reset settings to configuration values: %s",
+			SiteAdded:           "Service %s added to the registry",
+			SiteUpdated:         "Settings of %s updated",
+			SiteRemoved:         "Service %s removed from the registry",
+			SiteEmpty:           "The registry is empty, add a service: pgen site add <service>",
+			SiteDefaultsInfo:    "Unset settings come from the configuration",
+			SiteApplied:         "Registered service settings applied",
+			SiteNotFound:        "Service %s is not in the registry",
+			SiteExists:          "Service %s is already registered, change it with: pgen site edit",
+			SiteFieldUnknown:    "Unknown setting %s, valid settings: %s",
+			SiteInvalid:         "The site registry file is corrupted",
+			SiteVersion:         "The site registry file was created by a newer pgen version",
+			SiteLoadError:       "Failed to read the site registry:",
+			SiteSaveError:       "Failed to save the site registry:",
+			SiteColumnService:   "SERVICE",
+			SiteColumnUser:      "USER",
+			SiteColumnLength:    "LENGTH",
+			SiteColumnCounter:   "COUNTER",
+			SiteColumnMode:      "TYPE",
+			SiteColumnNote:      "NOTE",
+
+			// Зашифрованное хранилище
+			VaultShort:            "Encrypted store for the username and the site registry",
+			VaultLong:             "The store encrypts the username and the site registry with a key derived from the master password\n(Argon2id, HKDF, XChaCha20-Poly1305). Service names are replaced with HMACs, so without the master\npassword the file does not reveal which services you have accounts on.\nAfter pgen vault init the username is removed from config.json, sites.json and shell_history from disk;\npgen shell no longer keeps a history.\nCommands that read the store need the master password; the agent is not used with the store.",
+			VaultInitShort:        "Encrypt the username and the site registry",
+			VaultStatusShort:      "Show the store status",
+			VaultUserShort:        "Change the username in the store",
+			VaultDisableShort:     "Decrypt the store back into config.json and sites.json",
+			VaultConfirmMaster:    "Repeat the master password:",
+			VaultConfirmMismatch:  "Master passwords do not match",
+			VaultCreated:          "Store created: %d services, username removed from config.json",
+			VaultDisabled:         "Store decrypted into config.json and sites.json and removed",
+			VaultUserSet:          "Username in the store changed",
+			VaultStatusEnabled:    "Store enabled:",
+			VaultStatusDisabled:   "No store, enable it with: pgen vault init",
+			VaultStatusSites:      "Services: %d",
+			VaultStatusKDF:        "Argon2id: time %d, memory %d KiB, threads %d",
+			VaultHiddenUser:       "hidden",
+			VaultExists:           "The store already exists",
+			VaultNotEnabled:       "No store, enable it with: pgen vault init",
+			VaultMasterMismatch:   "Wrong master password: the store could not be decrypted",
+			VaultInvalid:          "The store file is corrupted",
+			VaultVersion:          "The store file was created by a newer pgen version",
+			VaultLoadError:        "Failed to read the store:",
+			VaultSaveError:        "Failed to save the store:",
+			VaultAgentUnsupported: "The username and registry are encrypted: pass the master password with --master-file, --master-fd or --master-stdin",
+			VaultUsernameHint:     "The username is kept in the encrypted store, change it with: pgen vault user <name>",
+
+			// Профили
+			ProfileShort:             "Named profiles: separate username, Argon2 parameters and statistics",
+			ProfileLong:              "A profile is a separate configuration with its own username, Argon2 parameters,\ngeneration settings, statistics, site registry and vault.\nThe default profile is config.json, the others live in the profiles directory next to it.\npgen profile use makes a profile active, the --profile flag selects a profile for one run.",
+			ProfileCreateShort:       "Create a profile",
+			ProfileUseShort:          "Make a profile active",
+			ProfileListShort:         "List profiles",
+			ProfileRmShort:           "Remove a profile with its site registry and vault",
+			ProfileRenameShort:       "Rename a profile",
+			ProfileFlagDesc:          "Profile for this run",
+			ProfileUserFlagDesc:      "Username of the new profile (part of the salt)",
+			ProfileCopyFlagDesc:      "Copy the current profile settings instead of the defaults",
+			ProfileYesFlagDesc:       "Remove without confirmation",
+			ProfileCreated:           "Profile %s created, switch to it with: pgen profile use %s",
+			ProfileSwitched:          "Active profile: %s",
+			ProfileRemoved:           "Profile %s removed",
+			ProfileRenamed:           "Profile %s renamed to %s",
+			ProfileRmConfirm:         "Remove profile %s with its site registry and vault? (y/N):",
+			ProfileRmCancelled:       "Profile removal cancelled",
+			ProfileRmConfirmRequired: "Confirm the profile removal with --yes",
+			ProfileNotFound:          "Profile %s not found, list profiles with: pgen profile list",
+			ProfileExists:            "Profile %s already exists",
+			ProfileNameInvalid:       "Invalid profile name %s: latin letters, digits, '_' and '-', up to 32 characters",
+			ProfileDefaultError:      "The default profile cannot be removed or renamed",
+			ProfileActiveError:       "Profile %s is active, switch to another one first: pgen profile use default",
+			ProfileError:             "Profile error:",
+			ProfileColumnName:        "PROFILE",
+			ProfileColumnUser:        "USER",
+			ProfileColumnAlgorithm:   "ALGORITHM",
+			ProfileColumnPasswords:   "PASSWORDS",
+
+			// Проверка мастер-пароля
+			MasterShort:            "Master password check against typos",
+			MasterLong:             "The check stores a few bits derived from the master password with Argon2id\nand warns about a typo before generating. The bit count is deliberately small: one\nwrong master password out of 2^bits passes the check, so it does not help\nto brute-force the master password. Each profile has its own check.",
+			MasterSetCheckShort:    "Store a master password check",
+			MasterCheckShort:       "Test a master password",
+			MasterClearCheckShort:  "Remove the master password check",
+			MasterBitsFlagDesc:     "Check size in bits, from %d to %d: more bits mean fewer false matches but more help for brute force",
+			MasterRefuseFlagDesc:   "Refuse to generate on a mismatch instead of warning",
+			MasterCheckSaved:       "Master password check stored: %d bits, a wrong master password passes it with a chance of 1 in %d",
+			MasterCheckCleared:     "Master password check removed",
+			MasterCheckMatch:       "The master password passed the check",
+			MasterCheckMismatch:    "The master password does not match the stored check, probably a typo",
+			MasterCheckWarning:     "The master password failed the check, probably a typo: passwords will differ",
+			MasterCheckNotSet:      "No master password check stored, create one with: pgen master set-check",
+			MasterCheckBitsInvalid: "The check size must be from %d to %d bits",
+			MasterCheckInvalid:     "The stored master password check is corrupted, set it again with: pgen master set-check",
+			MasterCheckError:       "Master password check error:",
+
+			// Отпечаток мастер-пароля
+			IdenticonLabel: "Master password fingerprint:",
+
+			// Калибровка Argon2
+			CalibrateShort:             "Tune Argon2 parameters for this machine",
+			CalibrateLong:              "Benchmarks Argon2id across a grid of memory sizes and iteration counts and recommends\nthe most expensive parameters that fit the target time. Memory matters more than iterations.\nWith --apply the parameters are written to the current profile.\n\nWARNING: Argon2 parameters are part of every password derivation. After changing them\nevery password will be different. Change your site passwords first, or create\na separate profile for the new parameters.",
+			CalibrateTargetFlagDesc:    "Desired time of one derivation, e.g. 500ms or 1s",
+			CalibrateMaxMemoryFlagDesc: "Memory limit, e.g. 512MiB or 1GiB",
+			CalibrateThreadsFlagDesc:   "Argon2 threads (0 - from the profile)",
+			CalibrateApplyFlagDesc:     "Write the recommended parameters to the current profile",
+			CalibrateRunning:           "Benchmarking Argon2id: target %s, up to %d MiB, threads: %d...",
+			CalibrateColumnTime:        "ITERATIONS",
+			CalibrateColumnMemory:      "MEMORY",
+			CalibrateColumnThreads:     "THREADS",
+			CalibrateColumnDuration:    "TIME",
+			CalibrateRecommended:       "Recommended parameters: t=%d, m=%d MiB, p=%d (%s)",
+			CalibrateNoneFits:          "Even the fastest parameters take longer than the target %s: recommending them",
+			CalibrateCurrent:           "Current parameters of profile %s: t=%d, m=%d MiB, p=%d",
+			CalibrateUnchanged:         "The recommended parameters match the current ones",
+			CalibrateWarning:           "WARNING: changing Argon2 parameters changes EVERY generated password. Change your site passwords first, or create a separate profile: pgen profile create",
+			CalibrateApplyHint:         "Write the parameters to the current profile: pgen calibrate --apply",
+			CalibrateApplied:           "Argon2 parameters of profile %s: t=%d, m=%d MiB, p=%d",
+			CalibrateTargetInvalid:     "The calibration target must be from %s to %s",
+			CalibrateMemoryInvalid:     "Invalid memory size %s: use e.g. 512MiB or 1GiB",
+			CalibrateMemoryRange:       "The memory limit must be from %d MiB to %d MiB",
+			CalibrateThreadsInvalid:    "Argon2 threads must be greater than zero",
+			CalibrateError:             "Calibration error:",
+
+			// Защита от смены паролей при изменении настроек
+			DriftForceFlagDesc:   "Apply the change without confirmation even if passwords change",
+			DriftWarning:         "WARNING: settings that passwords depend on are changing:",
+			DriftSites:           "Passwords of registered services will change (%d): %s",
+			DriftOtherServices:   "Passwords of all services outside the registry will change too",
+			DriftVaultSites:      "The site registry is encrypted: passwords of all services without their own settings will change",
+			DriftConfirm:         "Apply the change? Old passwords stay available with pgen get --snapshot (y/N):",
+			DriftConfirmRequired: "The change alters passwords: confirm it with --force",
+			DriftCancelled:       "Configuration not changed",
+			DriftSnapshotSaved:   "Previous settings saved as snapshot %d, old password: pgen get --snapshot %d <service>",
+			DriftSnapshotError:   "Error saving the settings snapshot:",
+
+			// История настроек генерации и снимки --snapshot
+			HistoryShort:           "Snapshots of previous settings that passwords depend on",
+			HistoryEmpty:           "No snapshots: settings that passwords depend on have not changed",
+			HistoryColumnReplaced:  "REPLACED",
+			HistoryColumnUser:      "USER",
+			HistoryColumnAlgorithm: "ALGORITHM",
+			HistoryColumnCharset:   "CHARSET",
+			HistoryColumnLength:    "LENGTH",
+			HistoryLoadError:       "Error reading settings snapshots:",
+			HistoryInvalid:         "The settings snapshot file is corrupted",
+			HistoryVersion:         "The settings snapshot file was created by a newer pgen version",
+			SnapshotFlagDesc:       "Generate the password with previous settings from snapshot N (pgen config history)",
+			SnapshotNotFound:       "Snapshot %d not found, list snapshots with: pgen config history",
+			SnapshotApplied:        "Settings of snapshot %d from %s",
+
+			// Приведение имени сервиса
+			RawServiceFlagDesc:   "Use the service name as typed, without reducing URLs to a host or domain",
+			ServiceCanonicalized: "Service: %s",
+
+			// Проверка имени сервиса на опечатки
+			NewServiceFlagDesc:          "Confirm a new service without checking the name for typos",
+			ServiceTypo:                 "%s — did you mean %s?",
+			ServiceTypoList:             "%s — did you mean:",
+			ServiceTypoChoose:           "Service number, n — new service, Enter — cancel:",
+			ServiceTypoNewHint:          "If this is a new service, repeat the command with --new",
+			ServiceTypoShellHint:        "If this is a new service, enter the name again",
+			ServiceRecordError:          "Failed to update the service list:",
+			ServiceRecordInvalid:        "The service list file is corrupted",
+			ServiceRecordVersion:        "The service list file was created by a newer pgen version",
+			ServiceRecordMasterMismatch: "The service list is hidden with a different master password and is not checked",
+
+			// Имя пользователя для одного запуска
+			UserFlagDesc:    "Username for this run: overrides the username from the site registry and configuration",
+			UsernameApplied: "Username: %s",
+
 			// Metrics and statistics
 			MetricsTitle:       "📊 PGen Detailed Metrics",
 			ProfileStatistics:  "=== Profile Statistics [%s] ===",
@@ -936,6 +1955,29 @@ func GetMessages(lang Language, version string) *Messages {
   pgen --copy                  # Copy password to clipboard
   pgen -c -t 30                # Copy with 30sec auto-clear
   pgen --length 20             # Set password length
+  pgen --require digit,symbol  # Always include a digit and a symbol
+  pgen --counter 2             # New password after a forced change
+  pgen --passphrase --words 5  # Five-word passphrase
+  pgen --type pin              # Four-digit PIN code
+  pgen batch services.txt      # Passwords for a list of services
+  pgen get github.com --master-file ~/.pgen-master --raw  # Password for a script
+  pgen --info --output json     # Result and analysis as JSON
+  pgen agent start              # Enter the master password once for pgen get
+  pgen shell                    # Session: many services with one master password entry
+  pgen site add github.com --length 24 --counter 2  # Remember service settings
+  pgen vault init               # Encrypt the username and the site registry
+  pgen profile create work --user alice  # Separate profile for work
+  pgen --profile work get github.com     # Password from the work profile
+  pgen master set-check                  # Store a master password check against typos
+  pgen config set identicon ascii        # Master password fingerprint without Unicode
+  pgen calibrate --target 1s             # Tune Argon2 parameters for this machine
+  pgen get --snapshot 1 github.com       # Password with the settings before they changed
+  pgen get https://www.github.com/login  # Same password as for github.com
+  pgen config set service_canonical domain # accounts.google.com → google.com
+  pgen get --raw-service "GitHub"        # Service name as typed
+  pgen get --new githib.com              # New service similar to a known one
+  pgen config set service_record blinded # Hide the list of used services
+  pgen get --user work github.com        # Password of the work account
   pgen --lang ru               # Use Russian language
   pgen --install               # Install PGen to system PATH`,
 			Flags: struct {
@@ -0,0 +1,128 @@
+// Package identicon строит визуальный отпечаток мастер-пароля в духе
+// Spectre: короткую цветную фигурку из HMAC-SHA256(мастер-пароль, имя
+// пользователя). Пользователь запоминает свою фигурку и замечает опечатку
+// по незнакомой, при этом ничего не сохраняется на диск.
+package identicon
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/fatih/color"
+)
+
+// Style набор символов отпечатка
+type Style string
+
+const (
+	StyleUnicode Style = "unicode"
+	StyleASCII   Style = "ascii"
+	StyleOff     Style = "off"
+)
+
+// glyphs символы частей фигурки: левая рука, тело, правая рука, аксессуар
+type glyphs struct {
+	leftArm   []string
+	body      []string
+	rightArm  []string
+	accessory []string
+}
+
+var unicodeGlyphs = glyphs{
+	leftArm:  []string{"╔", "╚", "╰", "═"},
+	body:     []string{"█", "░", "▒", "▓", "☺", "☻"},
+	rightArm: []string{"╗", "╝", "╯", "═"},
+	accessory: []string{
+		"◈", "◎", "◐", "◑", "◒", "◓", "☀", "☁", "☂", "☃", "☄", "★", "☆", "☎", "☏", "⎈",
+		"⌂", "☘", "☢", "☣", "☕", "⌚", "⌛", "⏰", "⚡", "⛄", "⛅", "☔", "♔", "♕", "♖", "♗",
+		"♘", "♙", "♚", "♛", "♜", "♝", "♞", "♟", "♨", "♩", "♪", "♫", "⚐", "⚑", "⚔", "⚖",
+		"⚙", "⚠", "⌘", "⏎", "✄", "✆", "✈", "✉", "✌",
+	},
+}
+
+// asciiGlyphs запасной набор для терминалов без Unicode
+var asciiGlyphs = glyphs{
+	leftArm:  []string{"(", "[", "{", "<"},
+	body:     []string{"o", "O", "0", "@", "#", "*"},
+	rightArm: []string{")", "]", "}", ">"},
+	accessory: []string{
+		"!", "$", "%", "&", "+", "=", "?", "^", "~", ":", ";", "'", "\"", ".", ",", "-",
+		"_", "/", "\\", "|", "`", "a", "b", "c", "d", "e", "f", "g", "h", "k", "m", "n",
+		"p", "q", "r", "s", "t", "u", "v", "w", "x", "y", "z",
+	},
+}
+
+// palette цвета фигурки. Чёрный не используется: на тёмном фоне он не виден.
+var palette = []color.Attribute{
+	color.FgRed, color.FgGreen, color.FgYellow, color.FgBlue, color.FgMagenta, color.FgCyan, color.FgWhite,
+}
+
+// Identicon отпечаток мастер-пароля. Хранит только байты, выбирающие части
+// фигурки, а не сам HMAC.
+type Identicon struct {
+	seed [5]byte
+}
+
+// ParseStyle разбирает набор символов отпечатка. Пустое значение - Unicode.
+func ParseStyle(value string) (Style, error) {
+	switch Style(value) {
+	case "", StyleUnicode:
+		return StyleUnicode, nil
+	case StyleASCII, StyleOff:
+		return Style(value), nil
+	default:
+		return "", errors.New("identicon_style_invalid")
+	}
+}
+
+// New вычисляет отпечаток мастер-пароля и имени пользователя
+func New(master []byte, username string) Identicon {
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte(username))
+	sum := mac.Sum(nil)
+
+	var icon Identicon
+	copy(icon.seed[:], sum)
+	for i := range sum {
+		sum[i] = 0
+	}
+	return icon
+}
+
+// Text возвращает фигурку без цвета. Для StyleOff результат пуст.
+func (i Identicon) Text(style Style) string {
+	var set glyphs
+	switch style {
+	case StyleUnicode:
+		set = unicodeGlyphs
+	case StyleASCII:
+		set = asciiGlyphs
+	default:
+		return ""
+	}
+	return pick(set.leftArm, i.seed[0]) + pick(set.body, i.seed[1]) +
+		pick(set.rightArm, i.seed[2]) + pick(set.accessory, i.seed[3])
+}
+
+// Color возвращает цвет фигурки
+func (i Identicon) Color() color.Attribute {
+	return palette[int(i.seed[4])%len(palette)]
+}
+
+// Render возвращает фигурку, окрашенную при colored. Решение о цвете
+// принимает вызывающий код по настройке color_output и терминалу.
+func (i Identicon) Render(style Style, colored bool) string {
+	text := i.Text(style)
+	if !colored || text == "" {
+		return text
+	}
+	c := color.New(i.Color(), color.Bold)
+	c.EnableColor()
+	return c.Sprint(text)
+}
+
+// pick выбирает символ набора по байту отпечатка
+func pick(set []string, b byte) string {
+	return set[int(b)%len(set)]
+}
@@ -0,0 +1,84 @@
+package identicon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// Известные ответы закрепляют отпечаток: его смена сбила бы пользователей,
+// запомнивших свою фигурку
+func TestKnownIdenticons(t *testing.T) {
+	tests := []struct {
+		master   string
+		username string
+		unicode  string
+		ascii    string
+		color    color.Attribute
+	}{
+		{"master123", "user", "╚☻═◈", "[*>,", color.FgCyan},
+		{"master124", "user", "╔▒╗◑", "(0)&", color.FgGreen},
+		{"master123", "alice", "╚░╝✄", "[O]:", color.FgMagenta},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.master+"/"+tt.username, func(t *testing.T) {
+			icon := New([]byte(tt.master), tt.username)
+			if got := icon.Text(StyleUnicode); got != tt.unicode {
+				t.Errorf("Text(unicode) = %q, ожидается %q", got, tt.unicode)
+			}
+			if got := icon.Text(StyleASCII); got != tt.ascii {
+				t.Errorf("Text(ascii) = %q, ожидается %q", got, tt.ascii)
+			}
+			if got := icon.Color(); got != tt.color {
+				t.Errorf("Color() = %v, ожидается %v", got, tt.color)
+			}
+		})
+	}
+}
+
+func TestIdenticonDeterministic(t *testing.T) {
+	first := New([]byte("master123"), "user")
+	second := New([]byte("master123"), "user")
+	if first != second {
+		t.Error("Отпечаток должен быть одинаковым для одного мастер-пароля и имени пользователя")
+	}
+}
+
+func TestRender(t *testing.T) {
+	icon := New([]byte("master123"), "user")
+
+	if got := icon.Render(StyleASCII, false); got != "[*>," {
+		t.Errorf("Render() без цвета = %q, ожидается %q", got, "[*>,")
+	}
+	if got := icon.Render(StyleOff, true); got != "" {
+		t.Errorf("Render(off) = %q, ожидается пустая строка", got)
+	}
+
+	colored := icon.Render(StyleUnicode, true)
+	if !strings.HasPrefix(colored, "\x1b[36;1m") || !strings.Contains(colored, "╚☻═◈") {
+		t.Errorf("Render() с цветом = %q, ожидается голубая фигурка", colored)
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Style
+		valid    bool
+	}{
+		{"", StyleUnicode, true},
+		{"unicode", StyleUnicode, true},
+		{"ascii", StyleASCII, true},
+		{"off", StyleOff, true},
+		{"emoji", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStyle(tt.value)
+		if (err == nil) != tt.valid || got != tt.expected {
+			t.Errorf("ParseStyle(%q) = %q, %v", tt.value, got, err)
+		}
+	}
+}
@@ -18,13 +18,13 @@ func ReadPasswordWithStarsAndMessages(messages *InputMessages) (*security.Secure
 	if err != nil {
 		return nil, err
 	}
-	
+
 	// Создаем SecureString из введенного пароля
-	securePassword := security.NewSecureString(password)
-	
-	// Очищаем обычную строку из памяти
-	security.SecureWipe([]byte(password))
-	
+	securePassword := security.NewSecureStringFromBytes(password)
+
+	// Очищаем буфер ввода из памяти
+	security.SecureWipe(password[:cap(password)])
+
 	return securePassword, nil
 }
 
@@ -12,65 +12,29 @@ import (
 	"golang.org/x/term"
 )
 
-func readPasswordWithStars(messages *InputMessages) (string, error) {
+func readPasswordWithStars(messages *InputMessages) ([]byte, error) {
 	fd := int(syscall.Stdin)
 
 	if !term.IsTerminal(fd) {
 		reader := bufio.NewReader(os.Stdin)
 		password, err := reader.ReadString('\n')
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return strings.TrimSpace(password), nil
+		return []byte(strings.TrimSpace(password)), nil
 	}
 
 	oldState, err := term.MakeRaw(fd)
 	if err != nil {
-		fmt.Print("🔑 ")
+		fmt.Fprint(os.Stderr, "🔑 ")
 		password, err := term.ReadPassword(fd)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		fmt.Println()
-		return string(password), nil
+		fmt.Fprintln(os.Stderr)
+		return password, nil
 	}
 	defer term.Restore(fd, oldState)
 
-	var password strings.Builder
-	var b [1]byte
-
-	for {
-		n, err := os.Stdin.Read(b[:])
-		if err != nil {
-			return "", err
-		}
-		if n == 0 {
-			continue
-		}
-
-		char := b[0]
-		switch char {
-		case 10, 13:
-			fmt.Print("\r\n")
-			return password.String(), nil
-		case 127, 8:
-			if password.Len() > 0 {
-				str := password.String()
-				password.Reset()
-				password.WriteString(str[:len(str)-1])
-				fmt.Print("\b \b")
-			}
-		case 3:
-			fmt.Print("\r\n")
-			return "", fmt.Errorf("%s", messages.UserCanceled)
-		case 27:
-			fmt.Print("\r\n")
-			return "", fmt.Errorf("%s", messages.InputCanceled)
-		default:
-			if char >= 32 && char <= 126 {
-				password.WriteByte(char)
-				fmt.Print("*")
-			}
-		}
-	}
+	return readPassword(os.Stdin, os.Stderr, messages)
 }
@@ -5,12 +5,17 @@ package input
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"syscall"
+	"unicode/utf16"
+	"unicode/utf8"
 	"unsafe"
 
 	"golang.org/x/term"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
 )
 
 const (
@@ -18,16 +23,16 @@ const (
 	enableLineInput = 0x0002
 )
 
-func readPasswordWithStars(messages *InputMessages) (string, error) {
+func readPasswordWithStars(messages *InputMessages) ([]byte, error) {
 	fd := int(syscall.Stdin)
 
 	if !term.IsTerminal(fd) {
 		reader := bufio.NewReader(os.Stdin)
 		password, err := reader.ReadString('\n')
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return strings.TrimSpace(password), nil
+		return []byte(strings.TrimSpace(password)), nil
 	}
 
 	kernel32 := syscall.NewLazyDLL("kernel32.dll")
@@ -39,66 +44,71 @@ func readPasswordWithStars(messages *InputMessages) (string, error) {
 	var oldMode uint32
 	r1, _, _ := procGetConsoleMode.Call(uintptr(handle), uintptr(unsafe.Pointer(&oldMode)))
 	if r1 == 0 {
-		fmt.Print("🔑 ")
-		password, err := term.ReadPassword(int(syscall.Stdin))
-		if err != nil {
-			return "", err
-		}
-		fmt.Println()
-		return string(password), nil
+		return readPasswordFallback()
 	}
 
 	newMode := oldMode &^ (enableEchoInput | enableLineInput)
 	r1, _, _ = procSetConsoleMode.Call(uintptr(handle), uintptr(newMode))
 	if r1 == 0 {
-		fmt.Print("🔑 ")
-		password, err := term.ReadPassword(int(syscall.Stdin))
-		if err != nil {
-			return "", err
-		}
-		fmt.Println()
-		return string(password), nil
+		return readPasswordFallback()
 	}
 
 	defer func() {
 		procSetConsoleMode.Call(uintptr(handle), uintptr(oldMode))
 	}()
 
-	var password strings.Builder
-	var b [1]byte
+	reader := &consoleReader{handle: handle, proc: kernel32.NewProc("ReadConsoleW")}
+	return readPassword(reader, os.Stderr, messages)
+}
 
-	for {
-		n, err := syscall.Read(handle, b[:])
-		if err != nil {
-			return "", err
-		}
-		if n == 0 {
-			continue
-		}
+// readPasswordFallback читает пароль без звёздочек, если режим консоли не меняется
+func readPasswordFallback() ([]byte, error) {
+	fmt.Fprint(os.Stderr, "🔑 ")
+	password, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(os.Stderr)
+	return password, nil
+}
+
+// consoleReader читает консоль через ReadConsoleW и отдаёт текст в UTF-8.
+// ReadFile возвращает байты в кодовой странице консоли, а не в UTF-8.
+type consoleReader struct {
+	handle syscall.Handle
+	proc   *syscall.LazyProc
+	// high старшая половина суррогатной пары, пришедшая в прошлом чтении
+	high uint16
+}
+
+func (c *consoleReader) Read(p []byte) (int, error) {
+	if len(p) < utf8.UTFMax {
+		return 0, io.ErrShortBuffer
+	}
+	units := make([]uint16, len(p)/utf8.UTFMax)
+	defer security.SecureWipe(unsafe.Slice((*byte)(unsafe.Pointer(&units[0])), len(units)*2))
 
-		char := b[0]
-		switch char {
-		case 13:
-			fmt.Print("\r\n")
-			return password.String(), nil
-		case 8:
-			if password.Len() > 0 {
-				str := password.String()
-				password.Reset()
-				password.WriteString(str[:len(str)-1])
-				fmt.Print("\b \b")
-			}
-		case 3:
-			fmt.Print("\r\n")
-			return "", fmt.Errorf("%s", messages.UserCanceled)
-		case 27:
-			fmt.Print("\r\n")
-			return "", fmt.Errorf("%s", messages.InputCanceled)
+	var read uint32
+	r1, _, err := c.proc.Call(uintptr(c.handle), uintptr(unsafe.Pointer(&units[0])), uintptr(len(units)),
+		uintptr(unsafe.Pointer(&read)), 0)
+	if r1 == 0 {
+		return 0, err
+	}
+
+	n := 0
+	for _, unit := range units[:read] {
+		char := rune(unit)
+		switch {
+		case unit >= 0xD800 && unit < 0xDC00:
+			c.high = unit
+			continue
+		case unit >= 0xDC00 && unit < 0xE000:
+			char = utf16.DecodeRune(rune(c.high), char)
+			c.high = 0
 		default:
-			if char >= 32 && char <= 126 {
-				password.WriteByte(char)
-				fmt.Print("*")
-			}
+			c.high = 0
 		}
+		n += utf8.EncodeRune(p[n:], char)
 	}
+	return n, nil
 }
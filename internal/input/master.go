@@ -0,0 +1,92 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// maxMasterPasswordSize ограничивает чтение мастер-пароля из файла или потока,
+// чтобы ошибочно указанный большой файл не читался целиком
+const maxMasterPasswordSize = 4096
+
+// ReadMasterPassword читает мастер-пароль из первой строки потока. Отсекается
+// только перевод строки: пробелы по краям могут быть частью пароля. Поток
+// читается побайтно, данные после первой строки остаются непрочитанными.
+func ReadMasterPassword(r io.Reader) (*security.SecureString, error) {
+	buf := make([]byte, 0, 64)
+	defer func() { security.SecureWipe(buf[:cap(buf)]) }()
+
+	var b [1]byte
+	for {
+		n, err := r.Read(b[:])
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			if len(buf) == maxMasterPasswordSize {
+				return nil, fmt.Errorf("master_too_long")
+			}
+			if len(buf) == cap(buf) {
+				// Растим буфер вручную, чтобы затереть старую копию
+				grown := make([]byte, len(buf), 2*cap(buf))
+				copy(grown, buf)
+				security.SecureWipe(buf)
+				buf = grown
+			}
+			buf = append(buf, b[0])
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return security.NewSecureStringFromBytes(bytes.TrimSuffix(buf, []byte("\r"))), nil
+}
+
+// ReadMasterFile читает мастер-пароль из первой строки файла. Обычный файл
+// должен быть доступен только владельцу, иначе возвращается master_file_insecure.
+func ReadMasterFile(path string) (*security.SecureString, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().IsRegular() {
+		if err := checkMasterFileMode(info.Mode()); err != nil {
+			return nil, err
+		}
+	}
+
+	return ReadMasterPassword(file)
+}
+
+// ReadMasterFd читает мастер-пароль из первой строки открытого дескриптора и
+// закрывает его
+func ReadMasterFd(fd int) (*security.SecureString, error) {
+	if fd < 0 {
+		return nil, fmt.Errorf("master_fd_invalid")
+	}
+	file := os.NewFile(uintptr(fd), "master-fd")
+	if file == nil {
+		return nil, fmt.Errorf("master_fd_invalid")
+	}
+	defer file.Close()
+
+	if _, err := file.Stat(); err != nil {
+		return nil, fmt.Errorf("master_fd_invalid")
+	}
+	return ReadMasterPassword(file)
+}
@@ -0,0 +1,113 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadMasterPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Строка с переводом", "secret\n", "secret"},
+		{"Без перевода строки", "secret", "secret"},
+		{"Перевод строки Windows", "secret\r\n", "secret"},
+		{"Пробелы сохраняются", "  se cret \n", "  se cret "},
+		{"Только первая строка", "first\nsecond\n", "first"},
+		{"Unicode", "пароль🔑\n", "пароль🔑"},
+		{"Пустой ввод", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := ReadMasterPassword(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ReadMasterPassword() ошибка: %v", err)
+			}
+			if password.String() != tt.expected {
+				t.Errorf("ReadMasterPassword() = %q, ожидается %q", password.String(), tt.expected)
+			}
+		})
+	}
+
+	t.Run("Остаток потока не читается", func(t *testing.T) {
+		reader := strings.NewReader("first\nsecond")
+		if _, err := ReadMasterPassword(reader); err != nil {
+			t.Fatalf("ReadMasterPassword() ошибка: %v", err)
+		}
+		if reader.Len() != len("second") {
+			t.Errorf("Прочитано лишнее: осталось %d байт", reader.Len())
+		}
+	})
+
+	t.Run("Слишком длинный ввод", func(t *testing.T) {
+		_, err := ReadMasterPassword(strings.NewReader(strings.Repeat("a", maxMasterPasswordSize+1)))
+		if err == nil || err.Error() != "master_too_long" {
+			t.Errorf("ReadMasterPassword() ошибка = %v, ожидается master_too_long", err)
+		}
+	})
+}
+
+func TestReadMasterFile(t *testing.T) {
+	dir := t.TempDir()
+
+	private := filepath.Join(dir, "master")
+	if err := os.WriteFile(private, []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("Ошибка записи файла: %v", err)
+	}
+	password, err := ReadMasterFile(private)
+	if err != nil {
+		t.Fatalf("ReadMasterFile() ошибка: %v", err)
+	}
+	if password.String() != "secret" {
+		t.Errorf("ReadMasterFile() = %q, ожидается secret", password.String())
+	}
+
+	if _, err := ReadMasterFile(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("ReadMasterFile() для отсутствующего файла ошибка = %v", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	shared := filepath.Join(dir, "shared")
+	if err := os.WriteFile(shared, []byte("secret\n"), 0o644); err != nil {
+		t.Fatalf("Ошибка записи файла: %v", err)
+	}
+	if err := os.Chmod(shared, 0o644); err != nil {
+		t.Fatalf("Ошибка chmod: %v", err)
+	}
+	if _, err := ReadMasterFile(shared); err == nil || err.Error() != "master_file_insecure" {
+		t.Errorf("ReadMasterFile() ошибка = %v, ожидается master_file_insecure", err)
+	}
+}
+
+func TestReadMasterFd(t *testing.T) {
+	if _, err := ReadMasterFd(-1); err == nil || err.Error() != "master_fd_invalid" {
+		t.Errorf("ReadMasterFd(-1) ошибка = %v, ожидается master_fd_invalid", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Ошибка создания pipe: %v", err)
+	}
+	go func() {
+		defer w.Close()
+		w.WriteString("secret\n")
+	}()
+
+	password, err := ReadMasterFd(int(r.Fd()))
+	// Дескриптор уже закрыт ReadMasterFd, Close лишь снимает финализатор r
+	r.Close()
+	if err != nil {
+		t.Fatalf("ReadMasterFd() ошибка: %v", err)
+	}
+	if password.String() != "secret" {
+		t.Errorf("ReadMasterFd() = %q, ожидается secret", password.String())
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package input
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkMasterFileMode отклоняет файл, доступный группе или остальным
+func checkMasterFileMode(mode os.FileMode) error {
+	if mode.Perm()&0o077 != 0 {
+		return fmt.Errorf("master_file_insecure")
+	}
+	return nil
+}
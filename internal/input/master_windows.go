@@ -0,0 +1,11 @@
+//go:build windows
+
+package input
+
+import "os"
+
+// checkMasterFileMode на Windows не проверяет права: доступ задаётся ACL, а не
+// битами режима
+func checkMasterFileMode(mode os.FileMode) error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package input
+
+import (
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// Управляющие байты при вводе пароля в сыром режиме терминала
+const (
+	keyCtrlC     = 3
+	keyBackspace = 8
+	keyLineFeed  = 10
+	keyEnter     = 13
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// readPassword читает пароль с терминала в сыром режиме: r - ввод терминала,
+// echo - вывод звёздочек. Байты собираются в руны UTF-8, поэтому кириллица,
+// символы с диакритикой и эмодзи не теряются, в том числе когда вставка
+// приходит одним блоком или руна разрезана между чтениями. На каждую руну
+// выводится одна звёздочка, Backspace удаляет последнюю руну целиком.
+// Некорректные последовательности UTF-8 и управляющие символы пропускаются.
+// Возвращённый срез нужно затереть после использования.
+func readPassword(r io.Reader, echo io.Writer, messages *InputMessages) ([]byte, error) {
+	password := make([]byte, 0, 64)
+	chunk := make([]byte, 256)
+	var pending [utf8.UTFMax]byte
+	pendingLen := 0
+	defer func() {
+		security.SecureWipe(chunk)
+		security.SecureWipe(pending[:])
+	}()
+
+	fail := func(err error) ([]byte, error) {
+		security.SecureWipe(password[:cap(password)])
+		return nil, err
+	}
+
+	for {
+		n, err := r.Read(chunk)
+		for _, b := range chunk[:n] {
+			if b < utf8.RuneSelf {
+				// Однобайтовый символ обрывает незаконченную руну
+				pendingLen = 0
+				switch b {
+				case keyEnter, keyLineFeed:
+					fmt.Fprint(echo, "\r\n")
+					return password, nil
+				case keyDelete, keyBackspace:
+					if len(password) > 0 {
+						_, size := utf8.DecodeLastRune(password)
+						security.SecureWipe(password[len(password)-size:])
+						password = password[:len(password)-size]
+						fmt.Fprint(echo, "\b \b")
+					}
+				case keyCtrlC:
+					fmt.Fprint(echo, "\r\n")
+					return fail(fmt.Errorf("%s", messages.UserCanceled))
+				case keyEscape:
+					fmt.Fprint(echo, "\r\n")
+					return fail(fmt.Errorf("%s", messages.InputCanceled))
+				default:
+					if b >= 32 {
+						password = appendSecure(password, b)
+						fmt.Fprint(echo, "*")
+					}
+				}
+				continue
+			}
+
+			if utf8.RuneStart(b) {
+				// Новая руна начинается поверх незаконченной
+				pendingLen = 0
+			} else if pendingLen == 0 {
+				// Байт продолжения без начала руны
+				continue
+			}
+			pending[pendingLen] = b
+			pendingLen++
+			if !utf8.FullRune(pending[:pendingLen]) {
+				continue
+			}
+
+			char, size := utf8.DecodeRune(pending[:pendingLen])
+			if char != utf8.RuneError && size == pendingLen && !unicode.IsControl(char) {
+				password = appendSecure(password, pending[:pendingLen]...)
+				fmt.Fprint(echo, "*")
+			}
+			pendingLen = 0
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+}
+
+// appendSecure дописывает байты в буфер пароля. Буфер растёт вручную, чтобы
+// затереть старую копию пароля.
+func appendSecure(buf []byte, data ...byte) []byte {
+	if len(buf)+len(data) > cap(buf) {
+		grown := make([]byte, len(buf), 2*cap(buf)+len(data))
+		copy(grown, buf)
+		security.SecureWipe(buf[:cap(buf)])
+		buf = grown
+	}
+	return append(buf, data...)
+}
@@ -0,0 +1,142 @@
+package input
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// fakeTerminal ввод терминала в сыром режиме: каждый элемент chunks
+// возвращается отдельным чтением, как блок вставки или нажатие клавиши
+type fakeTerminal struct {
+	chunks [][]byte
+}
+
+func newFakeTerminal(chunks ...string) *fakeTerminal {
+	terminal := &fakeTerminal{}
+	for _, chunk := range chunks {
+		terminal.chunks = append(terminal.chunks, []byte(chunk))
+	}
+	return terminal
+}
+
+func (f *fakeTerminal) Read(p []byte) (int, error) {
+	if len(f.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, f.chunks[0])
+	f.chunks[0] = f.chunks[0][n:]
+	if len(f.chunks[0]) == 0 {
+		f.chunks = f.chunks[1:]
+	}
+	return n, nil
+}
+
+var testInputMessages = &InputMessages{
+	UserCanceled:  "user canceled",
+	InputCanceled: "input canceled",
+}
+
+func TestReadPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+		stars  int
+	}{
+		{"ASCII", []string{"master123\r"}, "master123", 9},
+		{"Кириллица", []string{"пароль123\r"}, "пароль123", 9},
+		{"Диакритика", []string{"café\n"}, "café", 4},
+		{"Эмодзи одной звёздочкой", []string{"a🔑b\r"}, "a🔑b", 3},
+		{"Вставка с разрезанной руной", []string{"пар\xd0", "\xbeль", "\r"}, "пароль", 6},
+		{"Backspace удаляет руну целиком", []string{"абв\x7f\x7fг\r"}, "аг", 2},
+		{"Backspace Windows", []string{"ж🔑\b\r"}, "ж", 1},
+		{"Backspace на пустом вводе", []string{"\x7fя\r"}, "я", 1},
+		{"Некорректные байты пропускаются", []string{"a\xffb\x80c\xd0d\r"}, "abcd", 4},
+		{"Управляющие символы пропускаются", []string{"a\tb\u0085c\r"}, "abc", 3},
+		{"Ввод после Enter не читается", []string{"ok\rлишнее"}, "ok", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var echo bytes.Buffer
+			password, err := readPassword(newFakeTerminal(tt.chunks...), &echo, testInputMessages)
+			if err != nil {
+				t.Fatalf("readPassword() ошибка: %v", err)
+			}
+			if string(password) != tt.want {
+				t.Errorf("readPassword() = %q, ожидается %q", password, tt.want)
+			}
+			if stars := strings.Count(echo.String(), "*") - strings.Count(echo.String(), "\b \b"); stars != tt.stars {
+				t.Errorf("На экране %d звёздочек, ожидается %d", stars, tt.stars)
+			}
+			if !strings.HasSuffix(echo.String(), "\r\n") {
+				t.Errorf("Вывод %q не завершён переводом строки", echo.String())
+			}
+		})
+	}
+}
+
+func TestReadPasswordByteByByte(t *testing.T) {
+	// Терминал отдаёт многобайтовые руны по одному байту
+	reader := iotest.OneByteReader(strings.NewReader("пароль🔑\x7f!\r"))
+	var echo bytes.Buffer
+	password, err := readPassword(reader, &echo, testInputMessages)
+	if err != nil {
+		t.Fatalf("readPassword() ошибка: %v", err)
+	}
+	if string(password) != "пароль!" {
+		t.Errorf("readPassword() = %q, ожидается %q", password, "пароль!")
+	}
+	if echo.String() != "*******\b \b*\r\n" {
+		t.Errorf("Вывод = %q", echo.String())
+	}
+}
+
+func TestReadPasswordLongPaste(t *testing.T) {
+	// Вставка длиннее буфера чтения и начальной ёмкости пароля
+	long := strings.Repeat("ё", 300)
+	password, err := readPassword(newFakeTerminal(long+"\r"), io.Discard, testInputMessages)
+	if err != nil {
+		t.Fatalf("readPassword() ошибка: %v", err)
+	}
+	if string(password) != long {
+		t.Errorf("readPassword() вернул %d байт, ожидается %d", len(password), len(long))
+	}
+}
+
+func TestReadPasswordCancel(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"Ctrl+C", "пар\x03оль\r", "user canceled"},
+		{"Esc", "пар\x1b\r", "input canceled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := readPassword(newFakeTerminal(tt.input), io.Discard, testInputMessages)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("readPassword() ошибка = %v, ожидается %q", err, tt.wantErr)
+			}
+			if password != nil {
+				t.Errorf("readPassword() при отмене вернул %q", password)
+			}
+		})
+	}
+}
+
+func TestReadPasswordEOF(t *testing.T) {
+	// Ввод оборвался до Enter
+	password, err := readPassword(newFakeTerminal("пароль"), io.Discard, testInputMessages)
+	if err != io.EOF {
+		t.Errorf("readPassword() ошибка = %v, ожидается io.EOF", err)
+	}
+	if password != nil {
+		t.Errorf("readPassword() без Enter вернул %q", password)
+	}
+}
@@ -0,0 +1,226 @@
+// Package report собирает машиночитаемые документы для --output json. Документы
+// содержат стабильные коды и числа вместо локализованного текста, поэтому их
+// можно разбирать независимо от языка интерфейса.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/MaksymLeiber/pgen/internal/analyzer"
+	"github.com/MaksymLeiber/pgen/internal/validator"
+)
+
+// Форматы вывода
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ParseFormat проверяет имя формата вывода
+func ParseFormat(name string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(name)); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("output_unknown")
+	}
+}
+
+// Strength результат проверки силы мастер-пароля
+type Strength struct {
+	Level       string   `json:"level"`
+	Score       int      `json:"score"`
+	Issues      []string `json:"issues"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// NewStrength переводит результат валидатора в документ
+func NewStrength(strength *validator.PasswordStrength) *Strength {
+	return &Strength{
+		Level:       strength.Level.Code(),
+		Score:       strength.Score,
+		Issues:      strength.IssueCodes,
+		Suggestions: strength.SuggestionCodes,
+	}
+}
+
+// Composition состав символов пароля
+type Composition struct {
+	Uppercase int `json:"uppercase"`
+	Lowercase int `json:"lowercase"`
+	Numbers   int `json:"numbers"`
+	Symbols   int `json:"symbols"`
+	Total     int `json:"total"`
+}
+
+// Info результат анализа сгенерированного пароля
+type Info struct {
+	Length       int         `json:"length"`
+	Charset      string      `json:"charset"`
+	CharsetSize  int         `json:"charset_size"`
+	EntropyBits  float64     `json:"entropy_bits"`
+	CrackSeconds float64     `json:"crack_seconds"`
+	Strength     string      `json:"strength"`
+	Composition  Composition `json:"composition"`
+	Words        int         `json:"words,omitempty"`
+	WordlistSize int         `json:"wordlist_size,omitempty"`
+}
+
+// NewInfo переводит результат анализатора в документ
+func NewInfo(info *analyzer.PasswordInfo) *Info {
+	return &Info{
+		Length:       info.Length,
+		Charset:      info.Charset,
+		CharsetSize:  info.CharsetSize,
+		EntropyBits:  info.Entropy,
+		CrackSeconds: finite(info.TimeToCrack.Seconds),
+		Strength:     info.Level.Code(),
+		Composition: Composition{
+			Uppercase: info.Composition.Uppercase,
+			Lowercase: info.Composition.Lowercase,
+			Numbers:   info.Composition.Numbers,
+			Symbols:   info.Composition.Symbols,
+			Total:     info.Composition.Total,
+		},
+		Words:        info.Words,
+		WordlistSize: info.WordlistSize,
+	}
+}
+
+// Generation результат генерации пароля
+type Generation struct {
+	Service        string    `json:"service"`
+	Username       string    `json:"username"`
+	Password       string    `json:"password"`
+	Length         int       `json:"length"`
+	Mode           string    `json:"mode"`
+	Type           string    `json:"type,omitempty"`
+	Counter        uint32    `json:"counter"`
+	Algorithm      string    `json:"algorithm"`
+	MasterStrength *Strength `json:"master_strength,omitempty"`
+	Info           *Info     `json:"info,omitempty"`
+	Copied         bool      `json:"copied,omitempty"`
+	ClearAfter     int       `json:"clear_after_seconds,omitempty"`
+}
+
+// Argon параметры Argon2id
+type Argon struct {
+	Time      uint32 `json:"time"`
+	MemoryKiB uint32 `json:"memory_kib"`
+	Threads   uint8  `json:"threads"`
+	KeyLen    uint32 `json:"key_len"`
+}
+
+// Metrics статистика профиля и параметры генерации
+type Metrics struct {
+	Username            string     `json:"username"`
+	Profile             string     `json:"profile"`
+	PasswordsGenerated  int64      `json:"passwords_generated"`
+	FirstUsed           *time.Time `json:"first_used,omitempty"`
+	LastUsed            *time.Time `json:"last_used,omitempty"`
+	ActiveDays          int        `json:"active_days,omitempty"`
+	AveragePerDay       float64    `json:"average_per_day,omitempty"`
+	EntropyBits         float64    `json:"entropy_bits"`
+	AverageGenerationMs int64      `json:"average_generation_ms"`
+	Argon               Argon      `json:"argon"`
+	Algorithm           string     `json:"algorithm"`
+	Platform            string     `json:"platform"`
+	Version             string     `json:"version"`
+	ColorOutput         bool       `json:"color_output"`
+}
+
+// ConfigChange результат команды изменения конфигурации
+type ConfigChange struct {
+	Action string `json:"action"`
+	Key    string `json:"key,omitempty"`
+	Value  string `json:"value,omitempty"`
+	File   string `json:"file,omitempty"`
+}
+
+// Profile строка списка профилей. Имя пользователя пусто, если оно
+// зашифровано в хранилище профиля.
+type Profile struct {
+	Name               string `json:"name"`
+	Active             bool   `json:"active"`
+	Current            bool   `json:"current"`
+	Username           string `json:"username,omitempty"`
+	Algorithm          string `json:"algorithm"`
+	PasswordsGenerated int64  `json:"passwords_generated"`
+}
+
+// Vault состояние зашифрованного хранилища. Число сервисов и параметры Argon2id
+// видны без мастер-пароля.
+type Vault struct {
+	Enabled bool   `json:"enabled"`
+	File    string `json:"file,omitempty"`
+	Sites   int    `json:"sites"`
+	Argon   *Argon `json:"argon,omitempty"`
+}
+
+// MasterCheck результат проверки мастер-пароля. Совпадение означает лишь,
+// что опечатки, скорее всего, нет: проверка хранит всего Bits бит.
+type MasterCheck struct {
+	Match  bool `json:"match"`
+	Bits   int  `json:"bits"`
+	Refuse bool `json:"refuse"`
+}
+
+// CalibrationRun замер Argon2id при калибровке
+type CalibrationRun struct {
+	Time       uint32 `json:"time"`
+	MemoryKiB  uint32 `json:"memory_kib"`
+	Threads    uint8  `json:"threads"`
+	DurationMs int64  `json:"duration_ms"`
+	Fits       bool   `json:"fits"`
+}
+
+// Calibration результат калибровки. Recommended.KeyLen - длина ключа профиля,
+// калибровка её не меняет.
+type Calibration struct {
+	TargetMs     int64            `json:"target_ms"`
+	MaxMemoryKiB uint32           `json:"max_memory_kib"`
+	Runs         []CalibrationRun `json:"runs"`
+	Recommended  Argon            `json:"recommended"`
+	Fits         bool             `json:"fits"`
+	Current      Argon            `json:"current"`
+	Applied      bool             `json:"applied"`
+}
+
+// Agent состояние агента. Поля состояния заполнены только у запущенного агента.
+type Agent struct {
+	Action             string `json:"action,omitempty"`
+	Socket             string `json:"socket"`
+	Running            bool   `json:"running"`
+	Locked             bool   `json:"locked"`
+	PID                int    `json:"pid,omitempty"`
+	IdleTimeoutSeconds int64  `json:"idle_timeout_seconds,omitempty"`
+	ExpiresInSeconds   int64  `json:"expires_in_seconds,omitempty"`
+	CachedKeys         int    `json:"cached_keys,omitempty"`
+}
+
+// Version версия программы
+type Version struct {
+	Version string `json:"version"`
+}
+
+// Write записывает документ в w. Символы вроде < и & в паролях не экранируются.
+func Write(w io.Writer, document any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// finite заменяет бесконечность, которую JSON не допускает, наибольшим числом.
+// Время взлома переполняет float64 уже на энтропии около 1024 бит.
+func finite(value float64) float64 {
+	if math.IsInf(value, 1) {
+		return math.MaxFloat64
+	}
+	return value
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/analyzer"
+	"github.com/MaksymLeiber/pgen/internal/i18n"
+	"github.com/MaksymLeiber/pgen/internal/validator"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"text", FormatText, false},
+		{"json", FormatJSON, false},
+		{" JSON ", FormatJSON, false},
+		{"yaml", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		format, err := ParseFormat(tt.input)
+		if (err != nil) != tt.hasError {
+			t.Errorf("ParseFormat(%q) ошибка = %v", tt.input, err)
+		}
+		if format != tt.expected {
+			t.Errorf("ParseFormat(%q) = %q, ожидается %q", tt.input, format, tt.expected)
+		}
+	}
+}
+
+func TestNewStrength(t *testing.T) {
+	// Коды не зависят от языка сообщений
+	for _, language := range []i18n.Language{i18n.Russian, i18n.English} {
+		messages := i18n.GetMessages(language, "test")
+		strength := NewStrength(validator.ValidatePasswordStrength("abc", messages))
+
+		if strength.Level != "weak" {
+			t.Errorf("Level = %q, ожидается weak", strength.Level)
+		}
+		if len(strength.Issues) == 0 || strength.Issues[0] != "length_too_short" {
+			t.Errorf("Issues = %v, первой ожидается length_too_short", strength.Issues)
+		}
+	}
+}
+
+func TestNewInfo(t *testing.T) {
+	messages := i18n.GetMessages(i18n.Russian, "test")
+	info := NewInfo(analyzer.AnalyzePasswordWithAlphabet("Ab1!Ab1!Ab1!Ab1!", "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!@#$%^&*", messages))
+
+	if info.Strength != "very_strong" {
+		t.Errorf("Strength = %q, ожидается very_strong", info.Strength)
+	}
+	if info.Composition.Total != 16 || info.Composition.Symbols != 4 {
+		t.Errorf("Composition = %+v", info.Composition)
+	}
+
+	// Огромная энтропия даёт бесконечное время взлома, которое JSON не допускает
+	huge := NewInfo(analyzer.AnalyzePasswordWithEntropy("x", 2000, messages))
+	if huge.CrackSeconds != math.MaxFloat64 {
+		t.Errorf("CrackSeconds = %v, ожидается MaxFloat64", huge.CrackSeconds)
+	}
+	if err := Write(&bytes.Buffer{}, huge); err != nil {
+		t.Errorf("Write() ошибка: %v", err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Generation{Service: "github.com", Password: "a<b&c>", Counter: 1}); err != nil {
+		t.Fatalf("Write() ошибка: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"a<b&c>"`) {
+		t.Errorf("Пароль экранирован: %s", buf.String())
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Документ не является JSON: %v", err)
+	}
+	for _, omitted := range []string{"type", "info", "master_strength", "copied"} {
+		if _, ok := decoded[omitted]; ok {
+			t.Errorf("Пустое поле %q не должно выводиться", omitted)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package security
+
+import "fmt"
+
+// NewLockedSecureString создаёт безопасную строку в памяти вне кучи Go,
+// закреплённой в оперативной памяти (mlock), чтобы секрет не попал в swap.
+// Для долгоживущих секретов, например в агенте. Память освобождается в Clear.
+func NewLockedSecureString(b []byte) (*SecureString, error) {
+	region, err := allocLocked(len(b))
+	if err != nil {
+		return nil, fmt.Errorf("memory_lock_failed: %w", err)
+	}
+	copy(region, b)
+	return &SecureString{
+		data:   region[:len(b)],
+		size:   len(b),
+		region: region,
+	}, nil
+}
+
+// IsLocked сообщает, закреплена ли строка в оперативной памяти
+func (s *SecureString) IsLocked() bool {
+	return s != nil && s.region != nil
+}
@@ -0,0 +1,40 @@
+package security
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestNewLockedSecureString(t *testing.T) {
+	locked, err := NewLockedSecureString([]byte("secret"))
+	if runtime.GOOS == "windows" {
+		if err == nil {
+			t.Error("На Windows ожидается ошибка закрепления памяти")
+		}
+		return
+	}
+	if err != nil {
+		t.Skipf("mlock недоступен в окружении: %v", err)
+	}
+
+	if !locked.IsLocked() {
+		t.Error("IsLocked() = false для закреплённой строки")
+	}
+	if locked.String() != "secret" || locked.Len() != 6 {
+		t.Errorf("String() = %q, Len() = %d", locked.String(), locked.Len())
+	}
+	if !locked.SecureCompare(NewSecureString("secret")) {
+		t.Error("SecureCompare() с той же строкой = false")
+	}
+
+	locked.Clear()
+	if locked.IsLocked() || !locked.IsEmpty() {
+		t.Error("После Clear() строка должна быть пустой и откреплённой")
+	}
+	// Повторная очистка безопасна
+	locked.Clear()
+
+	if NewSecureString("secret").IsLocked() {
+		t.Error("Обычная строка не должна считаться закреплённой")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package security
+
+import "errors"
+
+// allocLocked на этой платформе не поддерживается
+func allocLocked(size int) ([]byte, error) {
+	return nil, errors.New("memory locking is not supported on this platform")
+}
+
+// freeLocked не вызывается: allocLocked всегда возвращает ошибку
+func freeLocked(region []byte) {
+	ZeroMemory(region)
+}
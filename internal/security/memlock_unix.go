@@ -0,0 +1,33 @@
+//go:build unix
+
+package security
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// allocLocked выделяет память через mmap и закрепляет её mlock. Размер
+// округляется до страницы, нулевой размер даёт одну страницу.
+func allocLocked(size int) ([]byte, error) {
+	pageSize := os.Getpagesize()
+	length := (size/pageSize + 1) * pageSize
+
+	region, err := unix.Mmap(-1, 0, length, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Mlock(region); err != nil {
+		unix.Munmap(region)
+		return nil, err
+	}
+	return region, nil
+}
+
+// freeLocked обнуляет, открепляет и освобождает память allocLocked
+func freeLocked(region []byte) {
+	ZeroMemory(region)
+	unix.Munlock(region)
+	unix.Munmap(region)
+}
@@ -11,6 +11,9 @@ import (
 type SecureString struct {
 	data []byte
 	size int
+
+	// region память вне кучи Go, закреплённая mlock; nil для обычных строк
+	region []byte
 }
 
 // NewSecureString создает новую безопасную строку из обычной строки
@@ -80,6 +83,11 @@ func (s *SecureString) Clear() {
 		s.data[i] = 0
 	}
 	
+	if s.region != nil {
+		freeLocked(s.region)
+		s.region = nil
+	}
+
 	s.size = 0
 	s.data = nil
 	
@@ -0,0 +1,233 @@
+// Package seen хранит имена сервисов, для которых уже генерировались пароли,
+// и подбирает среди них похожие на новое имя: опечатка в имени сервиса
+// молча дала бы другой пароль.
+//
+// Имена можно хранить скрытыми: вместо имени записывается HMAC-SHA256 под
+// ключом из мастер-пароля. Ключ получается как ключ индекса хранилища:
+// Argon2id со случайной солью файла, затем HKDF-SHA256 с отдельной info
+// строкой, и в файл не записывается. Скрытый список умеет только точное
+// сравнение: похожие имена среди скрытых не ищутся, а без мастер-пароля
+// (пароль от агента) список не проверяется и не пополняется.
+package seen
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// FileName имя файла списка рядом с config.json
+const FileName = "services.json"
+
+// FormatVersion версия формата файла списка
+const FormatVersion = 1
+
+// info строка HKDF ключа скрытых имён и проверочная строка ключа. Менять
+// нельзя: от них зависит чтение уже скрытых списков.
+const (
+	infoIndex  = "PGenCLI|seen|v1|index"
+	checkEntry = "PGenCLI seen key check"
+
+	saltSize = 16
+	keySize  = 32
+)
+
+// Blinding параметры скрытия имён. Сам ключ в файле не хранится: соль и
+// параметры Argon2id нужны, чтобы получить его из мастер-пароля, проверочная
+// запись - чтобы неверный мастер-пароль не испортил список.
+type Blinding struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Check   string `json:"check,omitempty"`
+}
+
+// Record список использованных имён сервисов, упорядоченный по имени или по
+// записи скрытого имени
+type Record struct {
+	Version  int       `json:"version"`
+	Blinding *Blinding `json:"blinding,omitempty"`
+	Names    []string  `json:"names"`
+
+	key []byte
+}
+
+// NewBlinding создаёт параметры скрытия со случайной солью
+func NewBlinding(time, memory uint32, threads uint8) (*Blinding, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &Blinding{Salt: salt, Time: time, Memory: memory, Threads: threads}, nil
+}
+
+// DeriveKey получает ключ скрытых имён из мастер-пароля
+func (b *Blinding) DeriveKey(master []byte) ([]byte, error) {
+	prk := argon2.IDKey(master, b.Salt, b.Time, b.Memory, b.Threads, keySize)
+	defer clear(prk)
+	return hkdf.Expand(sha256.New, prk, infoIndex, keySize)
+}
+
+// New создаёт пустой список
+func New() *Record {
+	return &Record{Version: FormatVersion, Names: []string{}}
+}
+
+// Load читает список из файла. Отсутствующий файл даёт пустой список.
+func Load(path string) (*Record, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := New()
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, errors.New("record_invalid")
+	}
+	if record.Version > FormatVersion {
+		return nil, errors.New("record_version")
+	}
+	if b := record.Blinding; b != nil && (len(b.Salt) != saltSize || b.Check == "") {
+		return nil, errors.New("record_invalid")
+	}
+	if record.Names == nil {
+		record.Names = []string{}
+	}
+	record.Version = FormatVersion
+	sort.Strings(record.Names)
+	return record, nil
+}
+
+// Save записывает список в файл с правами 0600. Файл заменяется целиком,
+// чтобы прерванная запись не повредила список.
+func (r *Record) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".services-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Blinded сообщает, скрыты ли имена
+func (r *Record) Blinded() bool {
+	return r.Blinding != nil
+}
+
+// Locked сообщает, что имена скрыты, а ключа нет: такой список ничего не
+// содержит и не пополняется
+func (r *Record) Locked() bool {
+	return r.Blinded() && r.key == nil
+}
+
+// Blind скрывает открытые имена ключом key, полученным из b.DeriveKey.
+// Скрытые имена открыть нельзя, поэтому обратного преобразования нет.
+func (r *Record) Blind(b *Blinding, key []byte) error {
+	if r.Blinded() {
+		return errors.New("record_blinded")
+	}
+	blinding := *b
+	blinding.Check = mac(key, checkEntry)
+	r.Blinding, r.key = &blinding, key
+
+	names := r.Names
+	r.Names = make([]string, 0, len(names))
+	for _, name := range names {
+		r.Add(name)
+	}
+	return nil
+}
+
+// Unlock принимает ключ скрытых имён. Ключ другого мастер-пароля даёт
+// record_master_mismatch.
+func (r *Record) Unlock(key []byte) error {
+	if !r.Blinded() {
+		return nil
+	}
+	if !hmac.Equal([]byte(mac(key, checkEntry)), []byte(r.Blinding.Check)) {
+		return errors.New("record_master_mismatch")
+	}
+	r.key = key
+	return nil
+}
+
+// Contains сообщает, встречалось ли имя сервиса
+func (r *Record) Contains(name string) bool {
+	if r.Locked() {
+		return false
+	}
+	entry := r.entry(name)
+	i := sort.SearchStrings(r.Names, entry)
+	return i < len(r.Names) && r.Names[i] == entry
+}
+
+// Add добавляет имя сервиса. Возвращает false, если имя уже было в списке
+// или список скрыт без ключа.
+func (r *Record) Add(name string) bool {
+	if r.Locked() {
+		return false
+	}
+	entry := r.entry(name)
+	i := sort.SearchStrings(r.Names, entry)
+	if i < len(r.Names) && r.Names[i] == entry {
+		return false
+	}
+	r.Names = append(r.Names, "")
+	copy(r.Names[i+1:], r.Names[i:])
+	r.Names[i] = entry
+	return true
+}
+
+// Known возвращает открытые имена для поиска похожих. Для скрытого списка
+// возвращает nil: скрытые имена сравниваются только точно.
+func (r *Record) Known() []string {
+	if r.Blinded() {
+		return nil
+	}
+	return append([]string(nil), r.Names...)
+}
+
+// entry возвращает запись имени в списке: само имя или его HMAC
+func (r *Record) entry(name string) string {
+	if !r.Blinded() {
+		return name
+	}
+	return mac(r.key, name)
+}
+
+// mac возвращает HMAC-SHA256 строки в hex
+func mac(key []byte, value string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil))
+}
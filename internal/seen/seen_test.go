@@ -0,0 +1,160 @@
+package seen
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRecordAddContains(t *testing.T) {
+	record := New()
+	for _, name := range []string{"github.com", "bank", "github.com", "amazon.de"} {
+		record.Add(name)
+	}
+
+	if !reflect.DeepEqual(record.Known(), []string{"amazon.de", "bank", "github.com"}) {
+		t.Errorf("Known() = %v", record.Known())
+	}
+	if !record.Contains("github.com") || record.Contains("gitlab.com") {
+		t.Error("Contains() вернул неверный результат")
+	}
+	if record.Add("bank") {
+		t.Error("Add() повторного имени вернул true")
+	}
+}
+
+func TestRecordSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	// Отсутствующий файл даёт пустой список
+	record, err := Load(path)
+	if err != nil || len(record.Names) != 0 {
+		t.Fatalf("Load() отсутствующего файла = %v, %v", record, err)
+	}
+
+	record.Add("github.com")
+	record.Add("bank")
+	if err := record.Save(path); err != nil {
+		t.Fatalf("Save() ошибка: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() ошибка: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 && os.PathSeparator == '/' {
+		t.Errorf("Права файла = %o, ожидается 600", perm)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() ошибка: %v", err)
+	}
+	if !loaded.Contains("github.com") || !loaded.Contains("bank") {
+		t.Errorf("Load() = %+v", loaded)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"Не JSON", "not json", "record_invalid"},
+		{"Неверный список", `{"version":1,"names":"github.com"}`, "record_invalid"},
+		{"Новая версия", `{"version":99,"names":[]}`, "record_version"},
+		{"Скрытый без проверки", `{"version":1,"blinding":{"salt":"AAAAAAAAAAAAAAAAAAAAAA==","time":1,"memory":8192,"threads":1},"names":[]}`, "record_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil || err.Error() != tt.want {
+				t.Errorf("Load() ошибка = %v, ожидается %s", err, tt.want)
+			}
+		})
+	}
+}
+
+// testBlinding быстрые параметры Argon2id для тестов
+func testBlinding(t *testing.T) *Blinding {
+	t.Helper()
+	b, err := NewBlinding(1, 8*1024, 1)
+	if err != nil {
+		t.Fatalf("NewBlinding() ошибка: %v", err)
+	}
+	return b
+}
+
+func TestRecordBlind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	b := testBlinding(t)
+	key, err := b.DeriveKey([]byte("master"))
+	if err != nil {
+		t.Fatalf("DeriveKey() ошибка: %v", err)
+	}
+
+	record := New()
+	record.Add("github.com")
+	if err := record.Blind(b, key); err != nil {
+		t.Fatalf("Blind() ошибка: %v", err)
+	}
+	record.Add("bank")
+	if !record.Contains("github.com") || !record.Contains("bank") || record.Contains("gitlab.com") {
+		t.Error("Contains() скрытого списка вернул неверный результат")
+	}
+	// Скрытый список сравнивает только точно
+	if record.Known() != nil {
+		t.Errorf("Known() скрытого списка = %v", record.Known())
+	}
+	if err := record.Save(path); err != nil {
+		t.Fatalf("Save() ошибка: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range [][]byte{[]byte("github.com"), []byte("bank"), []byte(hex.EncodeToString(key))} {
+		if bytes.Contains(data, secret) {
+			t.Errorf("Файл содержит %q", secret)
+		}
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() ошибка: %v", err)
+	}
+	if !loaded.Locked() || loaded.Contains("github.com") || loaded.Add("mail") {
+		t.Error("Скрытый список без ключа не закрыт")
+	}
+
+	wrong, err := loaded.Blinding.DeriveKey([]byte("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Unlock(wrong); err == nil || err.Error() != "record_master_mismatch" {
+		t.Errorf("Unlock() чужим ключом = %v", err)
+	}
+
+	same, err := loaded.Blinding.DeriveKey([]byte("master"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Unlock(same); err != nil {
+		t.Fatalf("Unlock() ошибка: %v", err)
+	}
+	if !loaded.Contains("github.com") || !loaded.Contains("bank") {
+		t.Error("Открытый ключом список не содержит имён")
+	}
+	if err := loaded.Blind(b, same); err == nil {
+		t.Error("Blind() скрытого списка без ошибки")
+	}
+}
@@ -0,0 +1,146 @@
+package seen
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// minSimilarity доля общих слов, при которой имена считаются похожими:
+// "work github" и "github work", но не "mail.google.com" и "docs.google.com"
+const minSimilarity = 0.6
+
+// Suggest возвращает не больше limit имён из candidates, похожих на name:
+//   - расстояние Левенштейна не больше maxDistance: "githib.com" → "github.com"
+//   - или те же слова в другом порядке и составе: "github work" → "work github"
+//   - или имя без домена совпадает с названием домена: "github" → "github.com"
+//
+// Сначала идут самые близкие по расстоянию имена, совпадение с названием
+// домена считается самым близким.
+func Suggest(name string, candidates []string, limit int) []string {
+	type match struct {
+		name       string
+		distance   int
+		similarity float64
+	}
+
+	maxDist := maxDistance(name)
+	tokens := tokenize(name)
+	var matches []match
+	seen := map[string]bool{name: true}
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		distance := levenshtein(name, candidate)
+		similarity := jaccard(tokens, tokenize(candidate))
+		if sameSiteName(name, candidate) || sameSiteName(candidate, name) {
+			distance = 0
+		}
+		if distance <= maxDist || similarity >= minSimilarity {
+			matches = append(matches, match{candidate, distance, similarity})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		if matches[i].similarity != matches[j].similarity {
+			return matches[i].similarity > matches[j].similarity
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var result []string
+	for _, m := range matches {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, m.name)
+	}
+	return result
+}
+
+// maxDistance допустимое число опечаток для имени. В коротких именах одна
+// замена даёт другой сервис ("x.com" и "t.com"), поэтому они не сравниваются.
+// Две опечатки допустимы только в длинных именах: иначе "gitlab.com"
+// считался бы опечаткой в "github.com".
+func maxDistance(name string) int {
+	switch n := len([]rune(name)); {
+	case n < 6:
+		return 0
+	case n < 12:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// sameSiteName сообщает, что bare - имя без домена, совпадающее с названием
+// регистрируемого домена host: "github" и "github.com" или "gist.github.com".
+// Домен к имени без точки не дописывается, поэтому такие имена дают разные
+// пароли, хотя почти всегда означают один сервис.
+func sameSiteName(bare, host string) bool {
+	if bare == "" || strings.ContainsAny(bare, ". ") || net.ParseIP(host) != nil {
+		return false
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return false
+	}
+	label, _, _ := strings.Cut(domain, ".")
+	return label == bare
+}
+
+// levenshtein расстояние Левенштейна между строками в символах
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// tokenize разбивает имя на слова по всему, что не буква и не цифра
+func tokenize(name string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		tokens[token] = true
+	}
+	return tokens
+}
+
+// jaccard доля общих слов от всех слов двух имён
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for token := range a {
+		if b[token] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
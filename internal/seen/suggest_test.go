@@ -0,0 +1,82 @@
+package seen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	known := []string{"github.com", "gitlab.com", "google.com", "work github", "mail.google.com", "x.com", "bitbucket.org"}
+
+	tests := []struct {
+		name    string
+		service string
+		want    []string
+	}{
+		{"Опечатка", "githib.com", []string{"github.com"}},
+		{"Пропущенная буква", "gogle.com", []string{"google.com"}},
+		{"Лишняя буква", "gitlabb.com", []string{"gitlab.com"}},
+		{"Две опечатки в длинном имени", "bitbukett.org", []string{"bitbucket.org"}},
+		{"Слова в другом порядке", "github work", []string{"work github"}},
+		{"Поддомен известного сервиса", "docs.google.com", []string{"google.com"}},
+		{"Имя без домена", "github", []string{"github.com"}},
+		{"Название домена с поддоменом", "google", []string{"google.com", "mail.google.com"}},
+		{"Имя без домена другого сервиса", "gitlab", []string{"gitlab.com"}},
+		{"Похожий сервис не опечатка", "gitlab.io", nil},
+		{"Короткое имя", "t.com", nil},
+		{"Известное имя", "github.com", nil},
+		{"Ничего похожего", "example.org", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Suggest(tt.service, known, 3); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q) = %v, ожидается %v", tt.service, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestOrderAndLimit(t *testing.T) {
+	known := []string{"yogithub.com", "mygithubs.com", "mygithub.co", "mygithub.com", "mygithub.co"}
+
+	// Ближайшие имена первыми, повторы не дублируются, само имя не предлагается
+	got := Suggest("mygithub.com", known, 5)
+	if !reflect.DeepEqual(got, []string{"mygithub.co", "mygithubs.com", "yogithub.com"}) {
+		t.Errorf("Suggest() = %v", got)
+	}
+	if got := Suggest("mygithub.com", known, 1); len(got) != 1 {
+		t.Errorf("Suggest() с limit 1 вернул %v", got)
+	}
+}
+
+func TestSuggestBareName(t *testing.T) {
+	// Имя без домена, записанное раньше, находится по адресу сайта
+	if got := Suggest("github.com", []string{"github", "gitlab"}, 3); !reflect.DeepEqual(got, []string{"github"}) {
+		t.Errorf("Suggest(github.com) = %v, ожидается [github]", got)
+	}
+	// Название домена сравнивается целиком
+	if got := Suggest("hub", []string{"github.com"}, 3); got != nil {
+		t.Errorf("Suggest(hub) = %v, ожидается пусто", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"github", "", 6},
+		{"github", "github", 0},
+		{"github", "githib", 1},
+		{"github", "gitlab", 2},
+		{"kitten", "sitting", 3},
+		{"почта", "почат", 2},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, ожидается %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package shell разбирает команды интерактивного сеанса pgen shell и хранит
+// историю введённых имён сервисов. Пароли в историю не попадают.
+package shell
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Виды команд сеанса
+const (
+	KindNone    = ""        // пустая строка
+	KindService = "service" // имя сервиса: сгенерировать пароль
+	KindRecall  = "recall"  // !N: повторить сервис из истории
+	KindLength  = "len"
+	KindCounter = "counter"
+	KindCopy    = "copy"
+	KindInfo    = "info"
+	KindHistory = "history"
+	KindHelp    = "help"
+	KindQuit    = "quit"
+)
+
+// commandPrefix отличает команды сеанса от имён сервисов
+const commandPrefix = ":"
+
+// recallPrefix повторяет сервис из истории по номеру
+const recallPrefix = "!"
+
+// Command разобранная строка сеанса
+type Command struct {
+	Kind    string
+	Service string
+	Value   int
+}
+
+// commands сопоставляет имена команд и их синонимы с видами
+var commands = map[string]string{
+	"len":     KindLength,
+	"length":  KindLength,
+	"counter": KindCounter,
+	"copy":    KindCopy,
+	"info":    KindInfo,
+	"history": KindHistory,
+	"help":    KindHelp,
+	"quit":    KindQuit,
+	"q":       KindQuit,
+	"exit":    KindQuit,
+}
+
+// Parse разбирает строку сеанса. Строка без префикса ':' или '!' считается
+// именем сервиса.
+func Parse(line string) (Command, error) {
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "":
+		return Command{Kind: KindNone}, nil
+	case strings.HasPrefix(line, recallPrefix):
+		n, err := strconv.Atoi(strings.TrimPrefix(line, recallPrefix))
+		if err != nil || n < 1 {
+			return Command{}, errors.New("argument_invalid")
+		}
+		return Command{Kind: KindRecall, Value: n}, nil
+	case !strings.HasPrefix(line, commandPrefix):
+		return Command{Kind: KindService, Service: line}, nil
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, commandPrefix))
+	if len(fields) == 0 {
+		return Command{}, errors.New("command_unknown")
+	}
+	kind, ok := commands[strings.ToLower(fields[0])]
+	if !ok {
+		return Command{}, errors.New("command_unknown")
+	}
+
+	switch kind {
+	case KindLength, KindCounter:
+		if len(fields) < 2 {
+			return Command{}, errors.New("argument_missing")
+		}
+		if len(fields) > 2 {
+			return Command{}, errors.New("argument_invalid")
+		}
+		value, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Command{}, errors.New("argument_invalid")
+		}
+		return Command{Kind: kind, Value: value}, nil
+	default:
+		if len(fields) > 1 {
+			return Command{}, errors.New("argument_invalid")
+		}
+		return Command{Kind: kind}, nil
+	}
+}
@@ -0,0 +1,51 @@
+package shell
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected Command
+		err      string
+	}{
+		{"Пустая строка", "   ", Command{Kind: KindNone}, ""},
+		{"Имя сервиса", "  github.com ", Command{Kind: KindService, Service: "github.com"}, ""},
+		{"Сервис с пробелом", "my bank", Command{Kind: KindService, Service: "my bank"}, ""},
+		{"Длина", ":len 24", Command{Kind: KindLength, Value: 24}, ""},
+		{"Синоним длины", ":length 12", Command{Kind: KindLength, Value: 12}, ""},
+		{"Счётчик", ":counter 2", Command{Kind: KindCounter, Value: 2}, ""},
+		{"Регистр команды", ":COPY", Command{Kind: KindCopy}, ""},
+		{"Анализ", ":info", Command{Kind: KindInfo}, ""},
+		{"История", ":history", Command{Kind: KindHistory}, ""},
+		{"Справка", ":help", Command{Kind: KindHelp}, ""},
+		{"Выход", ":q", Command{Kind: KindQuit}, ""},
+		{"Повтор из истории", "!3", Command{Kind: KindRecall, Value: 3}, ""},
+		{"Повтор с нулём", "!0", Command{}, "argument_invalid"},
+		{"Повтор без номера", "!", Command{}, "argument_invalid"},
+		{"Длина без аргумента", ":len", Command{}, "argument_missing"},
+		{"Длина не число", ":len abc", Command{}, "argument_invalid"},
+		{"Лишний аргумент", ":counter 2 3", Command{}, "argument_invalid"},
+		{"Аргумент у copy", ":copy now", Command{}, "argument_invalid"},
+		{"Неизвестная команда", ":dump", Command{}, "command_unknown"},
+		{"Одно двоеточие", ":", Command{}, "command_unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := Parse(tt.line)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Parse(%q) ошибка = %v, ожидается %s", tt.line, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) ошибка: %v", tt.line, err)
+			}
+			if command != tt.expected {
+				t.Errorf("Parse(%q) = %+v, ожидается %+v", tt.line, command, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultHistoryLimit число хранимых имён сервисов
+const DefaultHistoryLimit = 100
+
+// History история имён сервисов без повторов: повторно введённое имя
+// переносится в конец
+type History struct {
+	entries []string
+	limit   int
+}
+
+// NewHistory создаёт пустую историю не длиннее limit записей
+func NewHistory(limit int) *History {
+	return &History{limit: limit}
+}
+
+// LoadHistory читает историю из файла. Отсутствующий файл даёт пустую историю.
+func LoadHistory(path string, limit int) (*History, error) {
+	history := NewHistory(limit)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		history.Add(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Add добавляет имя сервиса в конец истории
+func (h *History) Add(service string) {
+	service = strings.TrimSpace(service)
+	if service == "" {
+		return
+	}
+	for i, entry := range h.entries {
+		if entry == service {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, service)
+	if h.limit > 0 && len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+}
+
+// Entries возвращает записи от старых к новым
+func (h *History) Entries() []string {
+	return append([]string(nil), h.entries...)
+}
+
+// Get возвращает запись по номеру, начиная с 1
+func (h *History) Get(n int) (string, error) {
+	if n < 1 || n > len(h.entries) {
+		return "", errors.New("history_entry_missing")
+	}
+	return h.entries[n-1], nil
+}
+
+// Save записывает историю в файл с правами 0600. Файл заменяется целиком,
+// чтобы прерванная запись не оставила обрезанную историю.
+func (h *History) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, entry := range h.entries {
+		writer.WriteString(entry + "\n")
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
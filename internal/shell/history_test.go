@@ -0,0 +1,67 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	history := NewHistory(3)
+	for _, service := range []string{"github.com", "gitlab.com", " ", "github.com", "bank", "mail"} {
+		history.Add(service)
+	}
+
+	// Повтор переносится в конец, пустые строки пропускаются, старые записи вытесняются
+	expected := []string{"github.com", "bank", "mail"}
+	if got := history.Entries(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Entries() = %v, ожидается %v", got, expected)
+	}
+
+	if service, err := history.Get(2); err != nil || service != "bank" {
+		t.Errorf("Get(2) = %q, %v, ожидается bank", service, err)
+	}
+	for _, n := range []int{0, 4} {
+		if _, err := history.Get(n); err == nil || err.Error() != "history_entry_missing" {
+			t.Errorf("Get(%d) ошибка = %v, ожидается history_entry_missing", n, err)
+		}
+	}
+}
+
+func TestHistorySaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shell_history")
+
+	history, err := LoadHistory(path, DefaultHistoryLimit)
+	if err != nil {
+		t.Fatalf("LoadHistory() без файла ошибка: %v", err)
+	}
+	if len(history.Entries()) != 0 {
+		t.Errorf("История без файла не пуста: %v", history.Entries())
+	}
+
+	history.Add("github.com")
+	history.Add("my bank")
+	if err := history.Save(path); err != nil {
+		t.Fatalf("Save() ошибка: %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat() ошибка: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Права файла истории %o, ожидается 600", info.Mode().Perm())
+		}
+	}
+
+	loaded, err := LoadHistory(path, DefaultHistoryLimit)
+	if err != nil {
+		t.Fatalf("LoadHistory() ошибка: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Entries(), history.Entries()) {
+		t.Errorf("Загружено %v, ожидается %v", loaded.Entries(), history.Entries())
+	}
+}
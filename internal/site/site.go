@@ -0,0 +1,204 @@
+// Package site хранит реестр сервисов: настройки генерации для каждого сервиса,
+// чтобы не повторять флаги при каждом вызове. Пароли в реестре не хранятся,
+// только параметры, из которых они заново вычисляются.
+package site
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileName имя файла реестра рядом с config.json
+const FileName = "sites.json"
+
+// FormatVersion версия формата файла реестра
+const FormatVersion = 1
+
+// Site настройки сервиса. Нулевое значение поля означает «не задано»: берётся
+// значение из конфигурации.
+type Site struct {
+	Service         string    `json:"service"`
+	Username        string    `json:"username,omitempty"`
+	Length          int       `json:"length,omitempty"`
+	Counter         uint32    `json:"counter,omitempty"`
+	Charset         string    `json:"charset,omitempty"`
+	RequiredClasses string    `json:"required_classes,omitempty"`
+	Type            string    `json:"type,omitempty"`
+	Algorithm       string    `json:"algorithm,omitempty"`
+	Passphrase      bool      `json:"passphrase,omitempty"`
+	Words           int       `json:"words,omitempty"`
+	Separator       string    `json:"separator,omitempty"`
+	Wordlist        string    `json:"wordlist,omitempty"`
+	Capitalize      bool      `json:"capitalize,omitempty"`
+	Digit           bool      `json:"digit,omitempty"`
+	Note            string    `json:"note,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Registry реестр сервисов, упорядоченный по имени сервиса
+type Registry struct {
+	Version int     `json:"version"`
+	Sites   []*Site `json:"sites"`
+}
+
+// New создаёт пустой реестр
+func New() *Registry {
+	return &Registry{Version: FormatVersion, Sites: []*Site{}}
+}
+
+// Load читает реестр из файла. Отсутствующий файл даёт пустой реестр.
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	registry := New()
+	if err := json.Unmarshal(data, registry); err != nil {
+		return nil, errors.New("registry_invalid")
+	}
+	if registry.Version > FormatVersion {
+		return nil, errors.New("registry_version")
+	}
+	registry.Version = FormatVersion
+	registry.sort()
+	return registry, nil
+}
+
+// Save записывает реестр в файл с правами 0600. Файл заменяется целиком,
+// чтобы прерванная запись не повредила реестр.
+func (r *Registry) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".sites-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get возвращает настройки сервиса или nil
+func (r *Registry) Get(service string) *Site {
+	service = strings.TrimSpace(service)
+	for _, s := range r.Sites {
+		if s.Service == service {
+			return s
+		}
+	}
+	return nil
+}
+
+// Add добавляет новый сервис
+func (r *Registry) Add(s *Site) error {
+	s.Service = strings.TrimSpace(s.Service)
+	if s.Service == "" {
+		return errors.New("service_empty")
+	}
+	if r.Get(s.Service) != nil {
+		return errors.New("site_exists")
+	}
+	now := time.Now().UTC()
+	s.CreatedAt, s.UpdatedAt = now, now
+	r.Sites = append(r.Sites, s)
+	r.sort()
+	return nil
+}
+
+// Update заменяет настройки существующего сервиса
+func (r *Registry) Update(s *Site) error {
+	for i, existing := range r.Sites {
+		if existing.Service == s.Service {
+			s.CreatedAt = existing.CreatedAt
+			s.UpdatedAt = time.Now().UTC()
+			r.Sites[i] = s
+			return nil
+		}
+	}
+	return errors.New("site_not_found")
+}
+
+// Remove удаляет сервис
+func (r *Registry) Remove(service string) error {
+	service = strings.TrimSpace(service)
+	for i, s := range r.Sites {
+		if s.Service == service {
+			r.Sites = append(r.Sites[:i], r.Sites[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("site_not_found")
+}
+
+func (r *Registry) sort() {
+	sort.Slice(r.Sites, func(i, j int) bool {
+		return r.Sites[i].Service < r.Sites[j].Service
+	})
+}
+
+// Fields имена настроек сервиса, совпадающие с флагами команды site
+var Fields = []string{
+	"user", "length", "counter", "charset", "require", "type", "algorithm",
+	"passphrase", "words", "separator", "wordlist", "capitalize", "digit", "note",
+}
+
+// Unset сбрасывает настройку, после чего снова действует значение из конфигурации
+func (s *Site) Unset(field string) error {
+	switch field {
+	case "user":
+		s.Username = ""
+	case "length":
+		s.Length = 0
+	case "counter":
+		s.Counter = 0
+	case "charset":
+		s.Charset = ""
+	case "require":
+		s.RequiredClasses = ""
+	case "type":
+		s.Type = ""
+	case "algorithm":
+		s.Algorithm = ""
+	case "passphrase":
+		s.Passphrase = false
+	case "words":
+		s.Words = 0
+	case "separator":
+		s.Separator = ""
+	case "wordlist":
+		s.Wordlist = ""
+	case "capitalize":
+		s.Capitalize = false
+	case "digit":
+		s.Digit = false
+	case "note":
+		s.Note = ""
+	default:
+		return errors.New("site_field_unknown")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := New()
+
+	for _, service := range []string{"gitlab.com", " github.com ", "bank"} {
+		if err := registry.Add(&Site{Service: service, Length: 20}); err != nil {
+			t.Fatalf("Add(%q) ошибка: %v", service, err)
+		}
+	}
+	if err := registry.Add(&Site{Service: "github.com"}); err == nil || err.Error() != "site_exists" {
+		t.Errorf("Повторный Add() ошибка = %v, ожидается site_exists", err)
+	}
+	if err := registry.Add(&Site{Service: "  "}); err == nil || err.Error() != "service_empty" {
+		t.Errorf("Add() без имени ошибка = %v, ожидается service_empty", err)
+	}
+
+	// Реестр упорядочен по имени сервиса
+	var services []string
+	for _, s := range registry.Sites {
+		services = append(services, s.Service)
+	}
+	if len(services) != 3 || services[0] != "bank" || services[1] != "github.com" || services[2] != "gitlab.com" {
+		t.Errorf("Порядок сервисов %v", services)
+	}
+
+	github := registry.Get("github.com")
+	if github == nil || github.Length != 20 || github.CreatedAt.IsZero() {
+		t.Fatalf("Get(github.com) = %+v", github)
+	}
+	if registry.Get("GitHub.com") != nil {
+		t.Error("Имена сервисов сравниваются с учётом регистра: другое имя даёт другой пароль")
+	}
+
+	updated := *github
+	updated.Counter = 2
+	if err := registry.Update(&updated); err != nil {
+		t.Fatalf("Update() ошибка: %v", err)
+	}
+	if got := registry.Get("github.com"); got.Counter != 2 || !got.CreatedAt.Equal(github.CreatedAt) {
+		t.Errorf("После Update() %+v", got)
+	}
+	if err := registry.Update(&Site{Service: "unknown"}); err == nil || err.Error() != "site_not_found" {
+		t.Errorf("Update() неизвестного сервиса ошибка = %v, ожидается site_not_found", err)
+	}
+
+	if err := registry.Remove("bank"); err != nil {
+		t.Fatalf("Remove() ошибка: %v", err)
+	}
+	if err := registry.Remove("bank"); err == nil || err.Error() != "site_not_found" {
+		t.Errorf("Повторный Remove() ошибка = %v, ожидается site_not_found", err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	registry, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() без файла ошибка: %v", err)
+	}
+	if len(registry.Sites) != 0 {
+		t.Errorf("Реестр без файла не пуст: %v", registry.Sites)
+	}
+
+	registry.Add(&Site{Service: "github.com", Username: "work", Counter: 3, Type: "pin", Note: "2FA"})
+	if err := registry.Save(path); err != nil {
+		t.Fatalf("Save() ошибка: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat() ошибка: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Права файла реестра %o, ожидается 600", info.Mode().Perm())
+		}
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() ошибка: %v", err)
+	}
+	got := loaded.Get("github.com")
+	if got == nil || got.Username != "work" || got.Counter != 3 || got.Type != "pin" || got.Note != "2FA" {
+		t.Errorf("Загружено %+v", got)
+	}
+
+	t.Run("Повреждённый файл", func(t *testing.T) {
+		os.WriteFile(path, []byte("{"), 0o600)
+		if _, err := Load(path); err == nil || err.Error() != "registry_invalid" {
+			t.Errorf("Load() ошибка = %v, ожидается registry_invalid", err)
+		}
+	})
+
+	t.Run("Новая версия формата", func(t *testing.T) {
+		os.WriteFile(path, []byte(`{"version": 99, "sites": []}`), 0o600)
+		if _, err := Load(path); err == nil || err.Error() != "registry_version" {
+			t.Errorf("Load() ошибка = %v, ожидается registry_version", err)
+		}
+	})
+}
+
+func TestUnset(t *testing.T) {
+	s := &Site{Service: "github.com"}
+	for _, field := range Fields {
+		if err := s.Unset(field); err != nil {
+			t.Errorf("Unset(%q) ошибка: %v", field, err)
+		}
+	}
+	if err := s.Unset("password"); err == nil || err.Error() != "site_field_unknown" {
+		t.Errorf("Unset(password) ошибка = %v, ожидается site_field_unknown", err)
+	}
+
+	s = &Site{Service: "github.com", Length: 24, Counter: 2, Note: "x"}
+	s.Unset("length")
+	if s.Length != 0 || s.Counter != 2 || s.Note != "x" {
+		t.Errorf("Unset(length) изменил другие поля: %+v", s)
+	}
+}
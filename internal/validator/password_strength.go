@@ -25,14 +25,39 @@ type PasswordStrength struct {
 	Score       int
 	Issues      []string
 	Suggestions []string
+
+	// Стабильные коды проблем и рекомендаций для машиночитаемого вывода,
+	// в том же порядке, что и Issues и Suggestions
+	IssueCodes      []string
+	SuggestionCodes []string
+}
+
+// Code возвращает стабильный код уровня силы
+func (l StrengthLevel) Code() string {
+	switch l {
+	case StrengthWeak:
+		return "weak"
+	case StrengthFair:
+		return "fair"
+	case StrengthGood:
+		return "good"
+	case StrengthStrong:
+		return "strong"
+	case StrengthVeryStrong:
+		return "very_strong"
+	default:
+		return "unknown"
+	}
 }
 
 // ValidatePasswordStrength анализирует силу мастер-пароля
 func ValidatePasswordStrength(password string, messages *i18n.Messages) *PasswordStrength {
 	result := &PasswordStrength{
-		Score:       0,
-		Issues:      []string{},
-		Suggestions: []string{},
+		Score:           0,
+		Issues:          []string{},
+		Suggestions:     []string{},
+		IssueCodes:      []string{},
+		SuggestionCodes: []string{},
 	}
 
 	// Базовые проверки
@@ -41,7 +66,9 @@ func ValidatePasswordStrength(password string, messages *i18n.Messages) *Passwor
 	// Длина пароля
 	if length < 8 {
 		result.Issues = append(result.Issues, messages.Errors.PasswordIssues.LengthTooShort)
+		result.IssueCodes = append(result.IssueCodes, "length_too_short")
 		result.Suggestions = append(result.Suggestions, messages.Errors.Suggestions.IncreaseLength)
+		result.SuggestionCodes = append(result.SuggestionCodes, "increase_length")
 	} else if length >= 8 && length < 12 {
 		result.Score += 10
 	} else if length >= 12 && length < 16 {
@@ -75,7 +102,9 @@ func ValidatePasswordStrength(password string, messages *i18n.Messages) *Passwor
 		result.Score += 5
 	} else {
 		result.Issues = append(result.Issues, messages.Errors.PasswordIssues.NoLowercase)
+		result.IssueCodes = append(result.IssueCodes, "no_lowercase")
 		result.Suggestions = append(result.Suggestions, messages.Errors.Suggestions.AddLowercase)
+		result.SuggestionCodes = append(result.SuggestionCodes, "add_lowercase")
 	}
 
 	if hasUpper {
@@ -83,7 +112,9 @@ func ValidatePasswordStrength(password string, messages *i18n.Messages) *Passwor
 		result.Score += 5
 	} else {
 		result.Issues = append(result.Issues, messages.Errors.PasswordIssues.NoUppercase)
+		result.IssueCodes = append(result.IssueCodes, "no_uppercase")
 		result.Suggestions = append(result.Suggestions, messages.Errors.Suggestions.AddUppercase)
+		result.SuggestionCodes = append(result.SuggestionCodes, "add_uppercase")
 	}
 
 	if hasNumber {
@@ -91,7 +122,9 @@ func ValidatePasswordStrength(password string, messages *i18n.Messages) *Passwor
 		result.Score += 5
 	} else {
 		result.Issues = append(result.Issues, messages.Errors.PasswordIssues.NoNumbers)
+		result.IssueCodes = append(result.IssueCodes, "no_numbers")
 		result.Suggestions = append(result.Suggestions, messages.Errors.Suggestions.AddNumbers)
+		result.SuggestionCodes = append(result.SuggestionCodes, "add_numbers")
 	}
 
 	if hasSymbol {
@@ -99,6 +132,7 @@ func ValidatePasswordStrength(password string, messages *i18n.Messages) *Passwor
 		result.Score += 10
 	} else {
 		result.Suggestions = append(result.Suggestions, messages.Errors.Suggestions.AddSymbols)
+		result.SuggestionCodes = append(result.SuggestionCodes, "add_symbols")
 	}
 
 	// Бонус за разнообразие символов
@@ -112,21 +146,27 @@ func ValidatePasswordStrength(password string, messages *i18n.Messages) *Passwor
 	// Проверка на повторяющиеся символы
 	if hasRepeatingChars(password) {
 		result.Issues = append(result.Issues, messages.Errors.PasswordIssues.RepeatingChars)
+		result.IssueCodes = append(result.IssueCodes, "repeating_chars")
 		result.Suggestions = append(result.Suggestions, messages.Errors.Suggestions.AvoidRepetition)
+		result.SuggestionCodes = append(result.SuggestionCodes, "avoid_repetition")
 		result.Score -= 10
 	}
 
 	// Проверка на последовательности
 	if hasSequences(password) {
 		result.Issues = append(result.Issues, messages.Errors.PasswordIssues.SequentialChars)
+		result.IssueCodes = append(result.IssueCodes, "sequential_chars")
 		result.Suggestions = append(result.Suggestions, messages.Errors.Suggestions.AvoidSequences)
+		result.SuggestionCodes = append(result.SuggestionCodes, "avoid_sequences")
 		result.Score -= 15
 	}
 
 	// Проверка на словарные слова
 	if hasCommonWords(password) {
 		result.Issues = append(result.Issues, messages.Errors.PasswordIssues.CommonWords)
+		result.IssueCodes = append(result.IssueCodes, "common_words")
 		result.Suggestions = append(result.Suggestions, messages.Errors.Suggestions.AvoidDictionary)
+		result.SuggestionCodes = append(result.SuggestionCodes, "avoid_dictionary")
 		result.Score -= 20
 	}
 
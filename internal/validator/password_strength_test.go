@@ -431,3 +431,40 @@ func BenchmarkHasSequences(b *testing.B) {
 		hasSequences(password)
 	}
 }
+
+func TestIssueCodes(t *testing.T) {
+	messages := i18n.GetMessages(i18n.English, "test")
+
+	result := ValidatePasswordStrength("aaa", messages)
+	if len(result.IssueCodes) != len(result.Issues) {
+		t.Fatalf("Кодов проблем %d, проблем %d", len(result.IssueCodes), len(result.Issues))
+	}
+	if len(result.SuggestionCodes) != len(result.Suggestions) {
+		t.Fatalf("Кодов рекомендаций %d, рекомендаций %d", len(result.SuggestionCodes), len(result.Suggestions))
+	}
+
+	expected := []string{"length_too_short", "no_uppercase", "no_numbers", "repeating_chars"}
+	for i, code := range expected {
+		if result.IssueCodes[i] != code {
+			t.Errorf("IssueCodes[%d] = %q, ожидается %q", i, result.IssueCodes[i], code)
+		}
+	}
+	if result.SuggestionCodes[0] != "increase_length" {
+		t.Errorf("SuggestionCodes[0] = %q, ожидается increase_length", result.SuggestionCodes[0])
+	}
+}
+
+func TestStrengthLevelCode(t *testing.T) {
+	codes := map[StrengthLevel]string{
+		StrengthWeak:       "weak",
+		StrengthFair:       "fair",
+		StrengthGood:       "good",
+		StrengthStrong:     "strong",
+		StrengthVeryStrong: "very_strong",
+	}
+	for level, code := range codes {
+		if level.Code() != code {
+			t.Errorf("Code() = %q, ожидается %q", level.Code(), code)
+		}
+	}
+}
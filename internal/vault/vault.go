@@ -0,0 +1,344 @@
+// Package vault хранит имя пользователя и реестр сервисов в зашифрованном виде.
+//
+// Ключи хранилища получаются из мастер-пароля: Argon2id со случайной солью
+// файла, затем HKDF-SHA256 с отдельными info строками для шифрования и для
+// индекса. Они не пересекаются с info строками генерации паролей, поэтому
+// ключи хранилища ничего не говорят о паролях сервисов.
+//
+// Записи шифруются XChaCha20-Poly1305 со случайным nonce. Имя сервиса
+// в файле заменено на HMAC-SHA256 под ключом индекса: без мастер-пароля
+// файл показывает только число записей. Блок проверки ключа позволяет
+// сообщить о неверном мастер-пароле, а не вернуть мусор.
+package vault
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+	"github.com/MaksymLeiber/pgen/internal/site"
+)
+
+// FileName имя файла хранилища рядом с config.json
+const FileName = "vault.json"
+
+// FormatVersion версия формата файла хранилища
+const FormatVersion = 1
+
+// info строки HKDF и дополнительные данные AEAD. Менять нельзя: от них
+// зависит чтение уже созданных хранилищ.
+const (
+	infoEncrypt = "PGenCLI|vault|v1|encrypt"
+	infoIndex   = "PGenCLI|vault|v1|index"
+
+	adCheck    = "check"
+	adUsername = "username"
+	adSite     = "site|"
+
+	checkPlaintext = "PGenCLI vault key check"
+	saltSize       = 16
+	keySize        = chacha20poly1305.KeySize
+)
+
+// KDFParams параметры Argon2id хранилища. Сохраняются в файле, чтобы смена
+// настроек генерации не делала хранилище нечитаемым.
+type KDFParams struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+// file формат файла хранилища. Байтовые поля кодируются в base64.
+type file struct {
+	Version  int               `json:"version"`
+	KDF      KDFParams         `json:"kdf"`
+	Check    []byte            `json:"check"`
+	Username []byte            `json:"username,omitempty"`
+	Sites    map[string][]byte `json:"sites"`
+}
+
+// Vault открытое хранилище с ключами в памяти. После работы вызывается Close.
+type Vault struct {
+	file       file
+	encryptKey []byte
+	indexKey   []byte
+}
+
+// Exists сообщает, создано ли хранилище
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Create создаёт пустое хранилище под мастер-паролем. Соль генерируется заново.
+func Create(master []byte, params KDFParams) (*Vault, error) {
+	params.Salt = make([]byte, saltSize)
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, err
+	}
+
+	v := &Vault{file: file{Version: FormatVersion, KDF: params, Sites: map[string][]byte{}}}
+	if err := v.deriveKeys(master); err != nil {
+		return nil, err
+	}
+	check, err := v.seal([]byte(checkPlaintext), adCheck)
+	if err != nil {
+		v.Close()
+		return nil, err
+	}
+	v.file.Check = check
+	return v, nil
+}
+
+// Info открытые сведения о хранилище, доступные без мастер-пароля
+type Info struct {
+	KDF   KDFParams
+	Sites int
+}
+
+// Inspect читает открытые сведения о хранилище
+func Inspect(path string) (*Info, error) {
+	f, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{KDF: f.KDF, Sites: len(f.Sites)}, nil
+}
+
+// Open читает хранилище и проверяет мастер-пароль по блоку проверки ключа
+func Open(path string, master []byte) (*Vault, error) {
+	f, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Vault{file: *f}
+	if err := v.deriveKeys(master); err != nil {
+		return nil, err
+	}
+	check, err := v.open(v.file.Check, adCheck)
+	if err != nil || string(check) != checkPlaintext {
+		v.Close()
+		return nil, errors.New("master_mismatch")
+	}
+	return v, nil
+}
+
+// readFile читает и проверяет файл хранилища
+func readFile(path string) (*file, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("vault_not_found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &file{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, errors.New("vault_invalid")
+	}
+	if f.Version > FormatVersion {
+		return nil, errors.New("vault_version")
+	}
+	if len(f.KDF.Salt) == 0 || f.KDF.Time == 0 || f.KDF.Memory == 0 || f.KDF.Threads == 0 {
+		return nil, errors.New("vault_invalid")
+	}
+	if f.Sites == nil {
+		f.Sites = map[string][]byte{}
+	}
+	return f, nil
+}
+
+// Save записывает хранилище в файл с правами 0600. Файл заменяется целиком.
+func (v *Vault) Save(path string) error {
+	data, err := json.MarshalIndent(v.file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vault-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Close стирает ключи хранилища из памяти
+func (v *Vault) Close() {
+	security.ZeroMemory(v.encryptKey)
+	security.ZeroMemory(v.indexKey)
+	v.encryptKey, v.indexKey = nil, nil
+}
+
+// KDF возвращает параметры Argon2id хранилища
+func (v *Vault) KDF() KDFParams {
+	return v.file.KDF
+}
+
+// Len возвращает число сервисов. Оно видно и без мастер-пароля.
+func (v *Vault) Len() int {
+	return len(v.file.Sites)
+}
+
+// Username возвращает имя пользователя или пустую строку, если оно не задано
+func (v *Vault) Username() (string, error) {
+	if v.file.Username == nil {
+		return "", nil
+	}
+	username, err := v.open(v.file.Username, adUsername)
+	if err != nil {
+		return "", errors.New("vault_invalid")
+	}
+	return string(username), nil
+}
+
+// SetUsername шифрует имя пользователя
+func (v *Vault) SetUsername(username string) error {
+	sealed, err := v.seal([]byte(username), adUsername)
+	if err != nil {
+		return err
+	}
+	v.file.Username = sealed
+	return nil
+}
+
+// Site возвращает настройки сервиса или nil. Расшифровывается только запись
+// этого сервиса, найденная по индексу.
+func (v *Vault) Site(service string) (*site.Site, error) {
+	index := v.index(service)
+	sealed, ok := v.file.Sites[index]
+	if !ok {
+		return nil, nil
+	}
+	return v.openSite(index, sealed)
+}
+
+// Sites расшифровывает все сервисы, упорядоченные по имени
+func (v *Vault) Sites() ([]*site.Site, error) {
+	sites := make([]*site.Site, 0, len(v.file.Sites))
+	for index, sealed := range v.file.Sites {
+		s, err := v.openSite(index, sealed)
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, s)
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].Service < sites[j].Service })
+	return sites, nil
+}
+
+// SetSites заменяет все сервисы хранилища. Каждая запись шифруется
+// с новым nonce.
+func (v *Vault) SetSites(sites []*site.Site) error {
+	entries := make(map[string][]byte, len(sites))
+	for _, s := range sites {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		index := v.index(s.Service)
+		sealed, err := v.seal(data, adSite+index)
+		security.ZeroMemory(data)
+		if err != nil {
+			return err
+		}
+		entries[index] = sealed
+	}
+	v.file.Sites = entries
+	return nil
+}
+
+// deriveKeys получает ключи шифрования и индекса из мастер-пароля
+func (v *Vault) deriveKeys(master []byte) error {
+	params := v.file.KDF
+	prk := argon2.IDKey(master, params.Salt, params.Time, params.Memory, params.Threads, keySize)
+	defer security.ZeroMemory(prk)
+
+	encryptKey, err := hkdf.Expand(sha256.New, prk, infoEncrypt, keySize)
+	if err != nil {
+		return err
+	}
+	indexKey, err := hkdf.Expand(sha256.New, prk, infoIndex, keySize)
+	if err != nil {
+		security.ZeroMemory(encryptKey)
+		return err
+	}
+	v.encryptKey, v.indexKey = encryptKey, indexKey
+	return nil
+}
+
+// index возвращает слепой индекс сервиса
+func (v *Vault) index(service string) string {
+	mac := hmac.New(sha256.New, v.indexKey)
+	mac.Write([]byte(strings.TrimSpace(service)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// openSite расшифровывает запись сервиса. Запись, перенесённая под чужой
+// индекс, не проходит проверку дополнительных данных.
+func (v *Vault) openSite(index string, sealed []byte) (*site.Site, error) {
+	data, err := v.open(sealed, adSite+index)
+	if err != nil {
+		return nil, errors.New("vault_invalid")
+	}
+	defer security.ZeroMemory(data)
+
+	s := &site.Site{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.New("vault_invalid")
+	}
+	return s, nil
+}
+
+// seal шифрует данные: результат - nonce, за которым следует шифротекст
+func (v *Vault) seal(plaintext []byte, additionalData string) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(v.encryptKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(additionalData)), nil
+}
+
+// open расшифровывает результат seal
+func (v *Vault) open(sealed []byte, additionalData string) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(v.encryptKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("vault_invalid")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(additionalData))
+}
@@ -0,0 +1,184 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MaksymLeiber/pgen/internal/site"
+)
+
+// testParams быстрые параметры Argon2id для тестов
+var testParams = KDFParams{Time: 1, Memory: 8 * 1024, Threads: 1}
+
+func createTestVault(t *testing.T, path string) {
+	t.Helper()
+	v, err := Create([]byte("testmaster"), testParams)
+	if err != nil {
+		t.Fatalf("Create() ошибка: %v", err)
+	}
+	defer v.Close()
+
+	if err := v.SetUsername("alice"); err != nil {
+		t.Fatalf("SetUsername() ошибка: %v", err)
+	}
+	sites := []*site.Site{
+		{Service: "github.com", Length: 24, Counter: 2},
+		{Service: "example.org", Note: "почта"},
+	}
+	if err := v.SetSites(sites); err != nil {
+		t.Fatalf("SetSites() ошибка: %v", err)
+	}
+	if err := v.Save(path); err != nil {
+		t.Fatalf("Save() ошибка: %v", err)
+	}
+}
+
+func TestVaultRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	createTestVault(t, path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Файл хранилища не создан: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Права файла = %o, ожидается 600", info.Mode().Perm())
+	}
+
+	v, err := Open(path, []byte("testmaster"))
+	if err != nil {
+		t.Fatalf("Open() ошибка: %v", err)
+	}
+	defer v.Close()
+
+	username, err := v.Username()
+	if err != nil || username != "alice" {
+		t.Errorf("Username() = %q, %v, ожидается alice", username, err)
+	}
+	if v.Len() != 2 {
+		t.Errorf("Len() = %d, ожидается 2", v.Len())
+	}
+
+	s, err := v.Site(" github.com ")
+	if err != nil {
+		t.Fatalf("Site() ошибка: %v", err)
+	}
+	if s == nil || s.Length != 24 || s.Counter != 2 {
+		t.Errorf("Site() = %+v, ожидаются длина 24 и счётчик 2", s)
+	}
+	if s, err := v.Site("gitlab.com"); s != nil || err != nil {
+		t.Errorf("Site() для отсутствующего сервиса = %+v, %v", s, err)
+	}
+
+	sites, err := v.Sites()
+	if err != nil {
+		t.Fatalf("Sites() ошибка: %v", err)
+	}
+	if len(sites) != 2 || sites[0].Service != "example.org" || sites[1].Service != "github.com" {
+		t.Errorf("Sites() вернул неупорядоченный список: %+v", sites)
+	}
+}
+
+func TestVaultHidesPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	createTestVault(t, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Ошибка чтения файла: %v", err)
+	}
+	for _, secret := range []string{"alice", "github.com", "example.org", "почта"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Файл хранилища содержит %q в открытом виде", secret)
+		}
+	}
+}
+
+func TestVaultWrongMaster(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	createTestVault(t, path)
+
+	if _, err := Open(path, []byte("wrongmaster")); err == nil || err.Error() != "master_mismatch" {
+		t.Errorf("Open() с неверным мастер-паролем ошибка = %v, ожидается master_mismatch", err)
+	}
+}
+
+func TestVaultOpenErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Open(filepath.Join(dir, "missing.json"), []byte("testmaster")); err == nil || err.Error() != "vault_not_found" {
+		t.Errorf("Open() отсутствующего файла ошибка = %v, ожидается vault_not_found", err)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"Не JSON", "{", "vault_invalid"},
+		{"Нет параметров", `{"version":1}`, "vault_invalid"},
+		{"Новая версия", `{"version":99}`, "vault_version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "vault.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Ошибка записи файла: %v", err)
+			}
+			if _, err := Open(path, []byte("testmaster")); err == nil || err.Error() != tt.expected {
+				t.Errorf("Open() ошибка = %v, ожидается %s", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVaultSwappedEntries(t *testing.T) {
+	v, err := Create([]byte("testmaster"), testParams)
+	if err != nil {
+		t.Fatalf("Create() ошибка: %v", err)
+	}
+	defer v.Close()
+
+	if err := v.SetSites([]*site.Site{{Service: "a.com"}, {Service: "b.com"}}); err != nil {
+		t.Fatalf("SetSites() ошибка: %v", err)
+	}
+	a, b := v.index("a.com"), v.index("b.com")
+	v.file.Sites[a], v.file.Sites[b] = v.file.Sites[b], v.file.Sites[a]
+
+	if _, err := v.Site("a.com"); err == nil {
+		t.Error("Site() должен отклонить запись, перенесённую под чужой индекс")
+	}
+}
+
+func TestVaultIndexDependsOnMaster(t *testing.T) {
+	first, err := Create([]byte("testmaster"), testParams)
+	if err != nil {
+		t.Fatalf("Create() ошибка: %v", err)
+	}
+	defer first.Close()
+
+	second, err := Create([]byte("othermaster"), KDFParams{Time: 1, Memory: 8 * 1024, Threads: 1})
+	if err != nil {
+		t.Fatalf("Create() ошибка: %v", err)
+	}
+	defer second.Close()
+
+	if first.index("github.com") == second.index("github.com") {
+		t.Error("Индекс сервиса не должен совпадать у разных хранилищ")
+	}
+}
+
+func TestInspect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	createTestVault(t, path)
+
+	info, err := Inspect(path)
+	if err != nil {
+		t.Fatalf("Inspect() ошибка: %v", err)
+	}
+	if info.Sites != 2 || info.KDF.Time != testParams.Time || info.KDF.Memory != testParams.Memory {
+		t.Errorf("Inspect() = %+v, ожидаются 2 сервиса и параметры %+v", info, testParams)
+	}
+}
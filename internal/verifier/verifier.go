@@ -0,0 +1,116 @@
+// Package verifier хранит короткую проверку мастер-пароля, чтобы опечатка
+// обнаруживалась до генерации, а не при неудачном входе на сайт.
+//
+// Проверка - это несколько бит из Argon2id(мастер-пароль, случайная соль),
+// пропущенного через HKDF-SHA256 с отдельной info строкой. Число бит
+// намеренно мало: из 2^bits неверных паролей один проходит проверку, поэтому
+// она отсекает опечатки, но не подтверждает найденный перебором мастер-пароль.
+// Каждая попытка перебора по-прежнему стоит одного вычисления Argon2id.
+package verifier
+
+import (
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// Границы и значение по умолчанию для числа бит проверки
+const (
+	MinBits     = 8
+	MaxBits     = 16
+	DefaultBits = 12
+)
+
+// info строка HKDF проверки. Менять нельзя: от неё зависят сохранённые проверки.
+const infoCheck = "PGenCLI|verifier|v1"
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// Params параметры Argon2id проверки
+type Params struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// Check сохранённая проверка мастер-пароля
+type Check struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Bits    int    `json:"bits"`
+	Value   uint32 `json:"value"`
+	Refuse  bool   `json:"refuse,omitempty"` // отказ вместо предупреждения при несовпадении
+}
+
+// ValidateBits проверяет число бит проверки
+func ValidateBits(bits int) error {
+	if bits < MinBits || bits > MaxBits {
+		return errors.New("check_bits_invalid")
+	}
+	return nil
+}
+
+// New создаёт проверку мастер-пароля со случайной солью
+func New(master []byte, params Params, bits int) (*Check, error) {
+	if err := ValidateBits(bits); err != nil {
+		return nil, err
+	}
+	c := &Check{
+		Salt:    make([]byte, saltSize),
+		Time:    params.Time,
+		Memory:  params.Memory,
+		Threads: params.Threads,
+		Bits:    bits,
+	}
+	if _, err := rand.Read(c.Salt); err != nil {
+		return nil, err
+	}
+	value, err := c.compute(master)
+	if err != nil {
+		return nil, err
+	}
+	c.Value = value
+	return c, nil
+}
+
+// Verify сообщает, совпадает ли мастер-пароль с проверкой. Совпадение
+// означает лишь, что опечатки, скорее всего, нет.
+func (c *Check) Verify(master []byte) (bool, error) {
+	if len(c.Salt) == 0 || c.Time == 0 || c.Memory == 0 || c.Threads == 0 || ValidateBits(c.Bits) != nil {
+		return false, errors.New("check_invalid")
+	}
+	value, err := c.compute(master)
+	if err != nil {
+		return false, err
+	}
+	return value == c.Value, nil
+}
+
+// FalseAcceptRate возвращает, какая доля неверных мастер-паролей проходит проверку: 1 из N
+func (c *Check) FalseAcceptRate() int {
+	return 1 << c.Bits
+}
+
+// compute вычисляет значение проверки: старшие Bits бит выхода HKDF
+func (c *Check) compute(master []byte) (uint32, error) {
+	key := argon2.IDKey(master, c.Salt, c.Time, c.Memory, c.Threads, keySize)
+	defer security.ZeroMemory(key)
+
+	material, err := hkdf.Expand(sha256.New, key, infoCheck, 4)
+	if err != nil {
+		return 0, err
+	}
+	defer security.ZeroMemory(material)
+	return binary.BigEndian.Uint32(material) >> (32 - c.Bits), nil
+}
@@ -0,0 +1,97 @@
+package verifier
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testParams быстрые параметры Argon2id для тестов
+var testParams = Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+
+func TestCheckVerify(t *testing.T) {
+	check, err := New([]byte("testmaster"), testParams, DefaultBits)
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+	if check.Value >= 1<<DefaultBits {
+		t.Errorf("Value = %d не помещается в %d бит", check.Value, DefaultBits)
+	}
+
+	ok, err := check.Verify([]byte("testmaster"))
+	if err != nil || !ok {
+		t.Errorf("Verify() верного мастер-пароля = %v, %v", ok, err)
+	}
+}
+
+func TestCheckRejectsTypos(t *testing.T) {
+	check, err := New([]byte("testmaster"), testParams, MaxBits)
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+
+	// При 16 битах случайное совпадение у всех опечаток практически исключено
+	rejected := 0
+	for i := 0; i < 8; i++ {
+		ok, err := check.Verify([]byte(fmt.Sprintf("testmaster%d", i)))
+		if err != nil {
+			t.Fatalf("Verify() ошибка: %v", err)
+		}
+		if !ok {
+			rejected++
+		}
+	}
+	if rejected == 0 {
+		t.Error("Verify() принял все неверные мастер-пароли")
+	}
+}
+
+func TestCheckSaltIsRandom(t *testing.T) {
+	first, err := New([]byte("testmaster"), testParams, DefaultBits)
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+	second, err := New([]byte("testmaster"), testParams, DefaultBits)
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+	if string(first.Salt) == string(second.Salt) {
+		t.Error("Соль проверки должна быть случайной")
+	}
+}
+
+func TestValidateBits(t *testing.T) {
+	tests := []struct {
+		bits  int
+		valid bool
+	}{
+		{MinBits - 1, false},
+		{MinBits, true},
+		{DefaultBits, true},
+		{MaxBits, true},
+		{MaxBits + 1, false},
+	}
+	for _, tt := range tests {
+		err := ValidateBits(tt.bits)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateBits(%d) ошибка = %v, ожидается допустимость %v", tt.bits, err, tt.valid)
+		}
+	}
+
+	if _, err := New([]byte("testmaster"), testParams, 32); err == nil || err.Error() != "check_bits_invalid" {
+		t.Errorf("New() с 32 битами ошибка = %v, ожидается check_bits_invalid", err)
+	}
+}
+
+func TestVerifyInvalidCheck(t *testing.T) {
+	check := &Check{Bits: DefaultBits}
+	if _, err := check.Verify([]byte("testmaster")); err == nil || err.Error() != "check_invalid" {
+		t.Errorf("Verify() без соли ошибка = %v, ожидается check_invalid", err)
+	}
+}
+
+func TestFalseAcceptRate(t *testing.T) {
+	check := &Check{Bits: 12}
+	if got := check.FalseAcceptRate(); got != 4096 {
+		t.Errorf("FalseAcceptRate() = %d, ожидается 4096", got)
+	}
+}
@@ -0,0 +1,91 @@
+package pgen
+
+import "errors"
+
+// Категории ошибок. Проверяются через errors.Is, причина доступна в Error.Code.
+var (
+	ErrInvalidLength          = errors.New("pgen: invalid length")
+	ErrInvalidCounter         = errors.New("pgen: invalid counter")
+	ErrInvalidCharset         = errors.New("pgen: invalid charset")
+	ErrInvalidRequiredClasses = errors.New("pgen: invalid required classes")
+	ErrInvalidPassphrase      = errors.New("pgen: invalid passphrase options")
+	ErrInvalidArgonParams     = errors.New("pgen: invalid argon2 parameters")
+	ErrUnknownAlgorithm       = errors.New("pgen: unknown algorithm")
+	ErrUnknownPasswordType    = errors.New("pgen: unknown password type")
+	ErrUnknownMode            = errors.New("pgen: unknown mode")
+	ErrEmptyMasterPassword    = errors.New("pgen: empty master password")
+	ErrEmptyService           = errors.New("pgen: empty service name")
+	ErrHashTooShort           = errors.New("pgen: hash too short")
+	ErrNotTwoStage            = errors.New("pgen: algorithm has no master key stage")
+	ErrEmptyMasterKey         = errors.New("pgen: empty master key")
+)
+
+// Error ошибка с кодом причины. Code - стабильный машинный код вида
+// "length_too_short", по которому CLI подбирает локализованное сообщение.
+type Error struct {
+	Code string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error() + ": " + e.Code
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Коды причин и их категории
+var errorKinds = map[string]error{
+	"length_too_short":           ErrInvalidLength,
+	"length_too_long":            ErrInvalidLength,
+	"counter_too_small":          ErrInvalidCounter,
+	"counter_too_large":          ErrInvalidCounter,
+	"charset_unknown":            ErrInvalidCharset,
+	"charset_invalid_char":       ErrInvalidCharset,
+	"charset_too_small":          ErrInvalidCharset,
+	"required_class_unknown":     ErrInvalidRequiredClasses,
+	"required_classes_too_many":  ErrInvalidRequiredClasses,
+	"required_class_unavailable": ErrInvalidRequiredClasses,
+	"words_too_few":              ErrInvalidPassphrase,
+	"words_too_many":             ErrInvalidPassphrase,
+	"separator_too_long":         ErrInvalidPassphrase,
+	"separator_invalid":          ErrInvalidPassphrase,
+	"wordlist_unknown":           ErrInvalidPassphrase,
+	"argon_params_invalid":       ErrInvalidArgonParams,
+	"algorithm_unknown":          ErrUnknownAlgorithm,
+	"password_type_unknown":      ErrUnknownPasswordType,
+	"mode_unknown":               ErrUnknownMode,
+	"master_password_empty":      ErrEmptyMasterPassword,
+	"service_empty":              ErrEmptyService,
+	"hash_too_short":             ErrHashTooShort,
+	"algorithm_not_two_stage":    ErrNotTwoStage,
+	"master_key_empty":           ErrEmptyMasterKey,
+}
+
+// wrapError превращает код ошибки внутреннего генератора в Error
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	kind, ok := errorKinds[err.Error()]
+	if !ok {
+		return err
+	}
+	return &Error{Code: err.Error(), Err: kind}
+}
+
+// newError создаёт Error по коду причины
+func newError(code string) error {
+	return &Error{Code: code, Err: errorKinds[code]}
+}
+
+// ErrorCode возвращает код причины ошибки. Для ошибок без кода возвращается
+// текст ошибки, поэтому результат подходит для сопоставления с сообщениями.
+func ErrorCode(err error) string {
+	var pgenErr *Error
+	if errors.As(err, &pgenErr) {
+		return pgenErr.Code
+	}
+	return err.Error()
+}
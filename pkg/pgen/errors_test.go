@@ -0,0 +1,47 @@
+package pgen
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWrapError(t *testing.T) {
+	err := wrapError(fmt.Errorf("hash_too_short"))
+	if !errors.Is(err, ErrHashTooShort) {
+		t.Errorf("wrapError() = %v, ожидается ErrHashTooShort", err)
+	}
+	if err.Error() != "pgen: hash too short: hash_too_short" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	unknown := fmt.Errorf("something else")
+	if wrapError(unknown) != unknown {
+		t.Error("Ошибка без кода должна возвращаться без изменений")
+	}
+	if wrapError(nil) != nil {
+		t.Error("wrapError(nil) должен вернуть nil")
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	if code := ErrorCode(newError("words_too_many")); code != "words_too_many" {
+		t.Errorf("ErrorCode() = %q, ожидается words_too_many", code)
+	}
+	wrapped := fmt.Errorf("generate: %w", newError("counter_too_large"))
+	if code := ErrorCode(wrapped); code != "counter_too_large" {
+		t.Errorf("ErrorCode() для обёрнутой ошибки = %q, ожидается counter_too_large", code)
+	}
+	if code := ErrorCode(errors.New("plain")); code != "plain" {
+		t.Errorf("ErrorCode() = %q, ожидается plain", code)
+	}
+}
+
+func TestErrorKindsCoverGeneratorCodes(t *testing.T) {
+	// Каждый код должен относиться к категории, иначе errors.Is не сработает
+	for code, kind := range errorKinds {
+		if kind == nil {
+			t.Errorf("Код %q без категории", code)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package pgen
+
+import (
+	"context"
+
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// MasterKey результат Argon2id над мастер-паролем для двухэтапного алгоритма v3.
+// Один ключ обслуживает любое количество сайтов пользователя.
+type MasterKey struct {
+	key *generator.MasterKey
+}
+
+// Username возвращает имя пользователя, для которого получен ключ
+func (mk *MasterKey) Username() string {
+	return mk.key.Username()
+}
+
+// LockMemory закрепляет ключ в оперативной памяти (mlock), чтобы он не попал
+// в swap. Полезно, когда ключ хранится долго. Поддерживается на Unix системах.
+func (mk *MasterKey) LockMemory() error {
+	return mk.key.LockMemory()
+}
+
+// Clear очищает мастер-ключ из памяти
+func (mk *MasterKey) Clear() {
+	if mk == nil {
+		return
+	}
+	mk.key.Clear()
+}
+
+// DeriveMasterKey выполняет Argon2id один раз для пользователя. Доступно для
+// алгоритма v3, ключ нужно очистить через Clear после использования.
+func (g *Generator) DeriveMasterKey(ctx context.Context, masterPassword []byte, username string) (*MasterKey, error) {
+	if g.opts.Algorithm != AlgorithmV3 {
+		return nil, newError("algorithm_not_two_stage")
+	}
+	if len(masterPassword) == 0 {
+		return nil, newError("master_password_empty")
+	}
+
+	secureMaster := security.NewSecureStringFromBytes(masterPassword)
+	key, err := await(ctx, func() (*generator.MasterKey, error) {
+		defer secureMaster.Clear()
+		return g.gen.DeriveMasterKey(secureMaster, username), nil
+	}, func(key *generator.MasterKey) {
+		key.Clear()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MasterKey{key: key}, nil
+}
+
+// GenerateWithMasterKey возвращает пароль сервиса из готового мастер-ключа без
+// повторного Argon2. Результат следует затереть после использования.
+func (g *Generator) GenerateWithMasterKey(ctx context.Context, masterKey *MasterKey, service string) ([]byte, error) {
+	if masterKey == nil {
+		return nil, newError("master_key_empty")
+	}
+	if service == "" {
+		return nil, newError("service_empty")
+	}
+	return awaitPassword(ctx, func() (*security.SecureString, error) {
+		return g.gen.GenerateWithMasterKey(masterKey.key, service)
+	})
+}
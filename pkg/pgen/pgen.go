@@ -0,0 +1,253 @@
+// Package pgen предоставляет детерминированную генерацию паролей PGen для
+// встраивания в Go программы. CLI построен на этом же API, поэтому при одинаковых
+// параметрах библиотека и pgen выдают одинаковые пароли.
+package pgen
+
+import (
+	"context"
+
+	"github.com/MaksymLeiber/pgen/internal/generator"
+	"github.com/MaksymLeiber/pgen/internal/security"
+)
+
+// Algorithm версия алгоритма генерации
+type Algorithm string
+
+const (
+	AlgorithmV1 Algorithm = generator.AlgorithmV1 // исходный алгоритм, совместим с существующими паролями
+	AlgorithmV2 Algorithm = generator.AlgorithmV2 // Argon2id → HKDF-SHA256
+	AlgorithmV3 Algorithm = generator.AlgorithmV3 // мастер-ключ Argon2id, затем HMAC-SHA256 на сайт
+	AlgorithmV4 Algorithm = generator.AlgorithmV4 // v2 с нормализацией Unicode и каноническим именем сервиса
+
+	// DefaultAlgorithm алгоритм для новых установок
+	DefaultAlgorithm Algorithm = generator.DefaultAlgorithmName
+)
+
+// Mode режим генерации: пароль по набору символов, парольная фраза или шаблон
+type Mode = generator.Mode
+
+const (
+	ModePassword   = generator.ModePassword
+	ModePassphrase = generator.ModePassphrase
+	ModeTemplate   = generator.ModeTemplate
+)
+
+// CharClass класс символов, обязательный в пароле
+type CharClass = generator.CharClass
+
+const (
+	ClassUpper  = generator.ClassUpper
+	ClassLower  = generator.ClassLower
+	ClassDigit  = generator.ClassDigit
+	ClassSymbol = generator.ClassSymbol
+)
+
+// PasswordType тип шаблонного пароля в стиле Master Password/Spectre
+type PasswordType = generator.PasswordType
+
+const (
+	TypeMaximum = generator.TypeMaximum
+	TypeLong    = generator.TypeLong
+	TypeMedium  = generator.TypeMedium
+	TypeShort   = generator.TypeShort
+	TypeBasic   = generator.TypeBasic
+	TypePIN     = generator.TypePIN
+	TypeName    = generator.TypeName
+	TypePhrase  = generator.TypePhrase
+)
+
+// PassphraseOptions параметры парольной фразы
+type PassphraseOptions = generator.PassphraseOptions
+
+// ArgonParams параметры Argon2id. Memory задаётся в КиБ.
+type ArgonParams = generator.ArgonConfig
+
+// Options параметры генератора. Начинать следует с DefaultOptions: нулевые
+// значения полей не подменяются и считаются ошибкой.
+type Options struct {
+	Length          int         // длина пароля в режиме ModePassword, от 4 до 128
+	Charset         string      // имя набора, "custom:<символы>" или "exclude:<символы>"
+	RequiredClasses []CharClass // классы, которые обязательно попадут в пароль
+	Counter         uint32      // счётчик пароля сервиса, от 1
+	Algorithm       Algorithm
+	Argon           ArgonParams
+	Mode            Mode
+	Passphrase      PassphraseOptions // для ModePassphrase
+	Type            PasswordType      // для ModeTemplate
+}
+
+// DefaultOptions возвращает параметры по умолчанию для новых установок
+func DefaultOptions() Options {
+	return Options{
+		Length:     16,
+		Charset:    generator.DefaultCharsetName,
+		Counter:    1,
+		Algorithm:  DefaultAlgorithm,
+		Argon:      DefaultArgonParams(),
+		Mode:       ModePassword,
+		Passphrase: generator.DefaultPassphraseOptions(),
+	}
+}
+
+// DefaultArgonParams возвращает параметры Argon2id по умолчанию
+func DefaultArgonParams() ArgonParams {
+	return ArgonParams{
+		Time:    3,
+		Memory:  256 * 1024,
+		Threads: 4,
+		KeyLen:  32,
+	}
+}
+
+// ParseRequiredClasses разбирает список классов через запятую ("upper,digit")
+func ParseRequiredClasses(spec string) ([]CharClass, error) {
+	classes, err := generator.ParseRequiredClasses(spec)
+	return classes, wrapError(err)
+}
+
+// ParsePasswordType разбирает имя типа шаблона. Пустая строка и "none"
+// возвращают пустой тип, то есть генерацию без шаблона.
+func ParsePasswordType(name string) (PasswordType, error) {
+	passwordType, err := generator.ParsePasswordType(name)
+	return passwordType, wrapError(err)
+}
+
+// CanonicalService возвращает имя сервиса в той форме, в которой его использует
+// алгоритм v4: NFC, без пробелов по краям, домены в ASCII по IDNA, остальные
+// имена в свёрнутом регистре
+func CanonicalService(service string) (string, error) {
+	canonical, err := generator.CanonicalService(service)
+	return canonical, wrapError(err)
+}
+
+// Generator генератор паролей с проверенными параметрами. Безопасен для
+// одновременного использования из нескольких горутин.
+type Generator struct {
+	opts Options
+	gen  *generator.PasswordGenerator
+}
+
+// New проверяет параметры и создаёт генератор
+func New(opts Options) (*Generator, error) {
+	if err := generator.ValidateLength(opts.Length); err != nil {
+		return nil, wrapError(err)
+	}
+	if err := generator.ValidateCounter(int(opts.Counter)); err != nil {
+		return nil, wrapError(err)
+	}
+	if err := validateArgonParams(opts.Argon); err != nil {
+		return nil, err
+	}
+
+	charset, err := generator.ResolveCharset(opts.Charset)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	algorithm, err := generator.ResolveAlgorithm(string(opts.Algorithm))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	gen := generator.NewPasswordGeneratorWithConfig(opts.Length, opts.Argon, charset)
+	gen.SetAlgorithm(algorithm)
+	gen.SetCounter(opts.Counter)
+	gen.SetMode(opts.Mode)
+
+	switch opts.Mode {
+	case ModePassword:
+		if err := gen.SetRequiredClasses(opts.RequiredClasses); err != nil {
+			return nil, wrapError(err)
+		}
+	case ModePassphrase:
+		if err := gen.SetPassphraseOptions(opts.Passphrase); err != nil {
+			return nil, wrapError(err)
+		}
+	case ModeTemplate:
+		if err := gen.SetPasswordType(opts.Type); err != nil {
+			return nil, wrapError(err)
+		}
+	default:
+		return nil, newError("mode_unknown")
+	}
+
+	return &Generator{opts: opts, gen: gen}, nil
+}
+
+// validateArgonParams отсекает параметры, с которыми Argon2 не работает
+func validateArgonParams(params ArgonParams) error {
+	if params.Time < 1 || params.Threads < 1 || params.KeyLen < 1 || params.Memory < 8*uint32(params.Threads) {
+		return newError("argon_params_invalid")
+	}
+	return nil
+}
+
+// Options возвращает параметры генератора
+func (g *Generator) Options() Options {
+	return g.opts
+}
+
+// Alphabet возвращает алфавит набора символов
+func (g *Generator) Alphabet() string {
+	return g.gen.Charset().Alphabet
+}
+
+// Generate возвращает пароль сервиса. Срез masterPassword не изменяется,
+// результат следует затереть после использования. Argon2 нельзя прервать,
+// поэтому при отмене ctx вычисление завершается в фоне, а результат очищается.
+func (g *Generator) Generate(ctx context.Context, masterPassword []byte, service, username string) ([]byte, error) {
+	if len(masterPassword) == 0 {
+		return nil, newError("master_password_empty")
+	}
+	if service == "" {
+		return nil, newError("service_empty")
+	}
+
+	secureMaster := security.NewSecureStringFromBytes(masterPassword)
+	return awaitPassword(ctx, func() (*security.SecureString, error) {
+		defer secureMaster.Clear()
+		return g.gen.GeneratePassword(secureMaster, service, username)
+	})
+}
+
+// awaitPassword выполняет генерацию с учётом отмены ctx
+func awaitPassword(ctx context.Context, generate func() (*security.SecureString, error)) ([]byte, error) {
+	password, err := await(ctx, generate, func(password *security.SecureString) {
+		password.Clear()
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer password.Clear()
+	return password.Bytes(), nil
+}
+
+// await запускает долгое вычисление и возвращается при отмене ctx, не дожидаясь
+// его. Результат, до которого вызывающий не дождался, передаётся в discard.
+func await[T any](ctx context.Context, compute func() (T, error), discard func(T)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := compute()
+		done <- result{value, wrapError(err)}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.err == nil {
+				discard(r.value)
+			}
+		}()
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,251 @@
+package pgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fastOptions параметры с быстрым Argon2 для тестов
+func fastOptions() Options {
+	opts := DefaultOptions()
+	opts.Argon = ArgonParams{Time: 1, Memory: 64 * 1024, Threads: 1, KeyLen: 32}
+	return opts
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(opts *Options)
+		kind   error
+		code   string
+	}{
+		{"Короткая длина", func(o *Options) { o.Length = 3 }, ErrInvalidLength, "length_too_short"},
+		{"Нулевая длина", func(o *Options) { o.Length = 0 }, ErrInvalidLength, "length_too_short"},
+		{"Длинная длина", func(o *Options) { o.Length = 129 }, ErrInvalidLength, "length_too_long"},
+		{"Нулевой счётчик", func(o *Options) { o.Counter = 0 }, ErrInvalidCounter, "counter_too_small"},
+		{"Неизвестный набор", func(o *Options) { o.Charset = "emoji" }, ErrInvalidCharset, "charset_unknown"},
+		{"Неизвестный алгоритм", func(o *Options) { o.Algorithm = "v9" }, ErrUnknownAlgorithm, "algorithm_unknown"},
+		{"Пустой алгоритм", func(o *Options) { o.Algorithm = "" }, ErrUnknownAlgorithm, "algorithm_unknown"},
+		{"Нулевые параметры Argon2", func(o *Options) { o.Argon = ArgonParams{} }, ErrInvalidArgonParams, "argon_params_invalid"},
+		{"Неизвестный режим", func(o *Options) { o.Mode = "poem" }, ErrUnknownMode, "mode_unknown"},
+		{"Шаблон без типа", func(o *Options) { o.Mode = ModeTemplate }, ErrUnknownPasswordType, "password_type_unknown"},
+		{"Фраза из двух слов", func(o *Options) {
+			o.Mode = ModePassphrase
+			o.Passphrase.Words = 2
+		}, ErrInvalidPassphrase, "words_too_few"},
+		{"Класс вне набора", func(o *Options) {
+			o.Charset = "alphanumeric"
+			o.RequiredClasses = []CharClass{ClassSymbol}
+		}, ErrInvalidRequiredClasses, "required_class_unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := fastOptions()
+			tt.modify(&opts)
+
+			_, err := New(opts)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("New() ошибка = %v, ожидается %v", err, tt.kind)
+			}
+			if code := ErrorCode(err); code != tt.code {
+				t.Errorf("ErrorCode() = %q, ожидается %q", code, tt.code)
+			}
+		})
+	}
+
+	if _, err := New(DefaultOptions()); err != nil {
+		t.Errorf("New(DefaultOptions()) ошибка: %v", err)
+	}
+}
+
+func TestGenerateKnownAnswers(t *testing.T) {
+	// Те же значения, что закреплены для внутреннего генератора: библиотека и CLI не расходятся
+	tests := []struct {
+		name     string
+		modify   func(opts *Options)
+		expected string
+	}{
+		{"v1 по умолчанию", func(o *Options) { o.Algorithm = AlgorithmV1 }, "Y|o3ku[p<_WSL+v1"},
+		{"v1 счётчик", func(o *Options) {
+			o.Algorithm = AlgorithmV1
+			o.Counter = 2
+		}, "7mlxXH[A5eJ@hHEV"},
+		{"v1 парольная фраза", func(o *Options) {
+			o.Algorithm = AlgorithmV1
+			o.Mode = ModePassphrase
+		}, "equinox-ashy-dollhouse-fountain-tracing-antarctic"},
+		{"v1 шаблон pin", func(o *Options) {
+			o.Algorithm = AlgorithmV1
+			o.Mode = ModeTemplate
+			o.Type = TypePIN
+		}, "9459"},
+		{"v4 совпадает с v2 для канонического имени", func(o *Options) { o.Algorithm = AlgorithmV4 }, "xc-J%=P>JViVn}C|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := fastOptions()
+			tt.modify(&opts)
+
+			gen, err := New(opts)
+			if err != nil {
+				t.Fatalf("New() ошибка: %v", err)
+			}
+			password, err := gen.Generate(context.Background(), []byte("testmaster"), "github.com", "testuser")
+			if err != nil {
+				t.Fatalf("Generate() ошибка: %v", err)
+			}
+			if string(password) != tt.expected {
+				t.Errorf("Generate() = %q, ожидается %q", password, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateKeepsMasterPassword(t *testing.T) {
+	gen, err := New(fastOptions())
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+
+	master := []byte("testmaster")
+	if _, err := gen.Generate(context.Background(), master, "github.com", "testuser"); err != nil {
+		t.Fatalf("Generate() ошибка: %v", err)
+	}
+	if string(master) != "testmaster" {
+		t.Error("Generate() изменил срез мастер-пароля вызывающего")
+	}
+}
+
+func TestGenerateInputErrors(t *testing.T) {
+	gen, err := New(fastOptions())
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+
+	if _, err := gen.Generate(context.Background(), nil, "github.com", "testuser"); !errors.Is(err, ErrEmptyMasterPassword) {
+		t.Errorf("Generate() без мастер-пароля ошибка = %v, ожидается ErrEmptyMasterPassword", err)
+	}
+	if _, err := gen.Generate(context.Background(), []byte("testmaster"), "", "testuser"); !errors.Is(err, ErrEmptyService) {
+		t.Errorf("Generate() без сервиса ошибка = %v, ожидается ErrEmptyService", err)
+	}
+}
+
+func TestCanonicalService(t *testing.T) {
+	canonical, err := CanonicalService(" Пример.РФ ")
+	if err != nil || canonical != "xn--e1afmkfd.xn--p1ai" {
+		t.Errorf("CanonicalService() = %q, %v", canonical, err)
+	}
+	if _, err := CanonicalService("   "); !errors.Is(err, ErrEmptyService) {
+		t.Errorf("CanonicalService() из пробелов ошибка = %v, ожидается ErrEmptyService", err)
+	}
+}
+
+func TestGenerateContext(t *testing.T) {
+	gen, err := New(fastOptions())
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+
+	t.Run("Отменённый контекст", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := gen.Generate(ctx, []byte("testmaster"), "github.com", "testuser"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Generate() ошибка = %v, ожидается context.Canceled", err)
+		}
+	})
+
+	t.Run("Отмена во время Argon2", func(t *testing.T) {
+		opts := fastOptions()
+		opts.Argon.Time = 20 // достаточно долго, чтобы отмена пришла раньше результата
+		slow, err := New(opts)
+		if err != nil {
+			t.Fatalf("New() ошибка: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go cancel()
+		if _, err := slow.Generate(ctx, []byte("testmaster"), "github.com", "testuser"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Generate() ошибка = %v, ожидается context.Canceled", err)
+		}
+	})
+}
+
+func TestMasterKey(t *testing.T) {
+	opts := fastOptions()
+	opts.Algorithm = AlgorithmV3
+	gen, err := New(opts)
+	if err != nil {
+		t.Fatalf("New() ошибка: %v", err)
+	}
+	ctx := context.Background()
+
+	masterKey, err := gen.DeriveMasterKey(ctx, []byte("testmaster"), "testuser")
+	if err != nil {
+		t.Fatalf("DeriveMasterKey() ошибка: %v", err)
+	}
+	defer masterKey.Clear()
+
+	if masterKey.Username() != "testuser" {
+		t.Errorf("Username() = %q, ожидается testuser", masterKey.Username())
+	}
+
+	fromKey, err := gen.GenerateWithMasterKey(ctx, masterKey, "github.com")
+	if err != nil {
+		t.Fatalf("GenerateWithMasterKey() ошибка: %v", err)
+	}
+	full, err := gen.Generate(ctx, []byte("testmaster"), "github.com", "testuser")
+	if err != nil {
+		t.Fatalf("Generate() ошибка: %v", err)
+	}
+	if string(fromKey) != string(full) {
+		t.Errorf("GenerateWithMasterKey() = %q, Generate() = %q", fromKey, full)
+	}
+
+	t.Run("Закреплённый ключ", func(t *testing.T) {
+		lockedKey, err := gen.DeriveMasterKey(ctx, []byte("testmaster"), "testuser")
+		if err != nil {
+			t.Fatalf("DeriveMasterKey() ошибка: %v", err)
+		}
+		defer lockedKey.Clear()
+		if err := lockedKey.LockMemory(); err != nil {
+			t.Skipf("mlock недоступен в окружении: %v", err)
+		}
+		password, err := gen.GenerateWithMasterKey(ctx, lockedKey, "github.com")
+		if err != nil {
+			t.Fatalf("GenerateWithMasterKey() ошибка: %v", err)
+		}
+		if string(password) != string(full) {
+			t.Errorf("Закреплённый ключ дал %q, ожидается %q", password, full)
+		}
+	})
+
+	t.Run("Алгоритм без мастер-ключа", func(t *testing.T) {
+		v2, err := New(fastOptions())
+		if err != nil {
+			t.Fatalf("New() ошибка: %v", err)
+		}
+		if _, err := v2.DeriveMasterKey(ctx, []byte("testmaster"), "testuser"); !errors.Is(err, ErrNotTwoStage) {
+			t.Errorf("DeriveMasterKey() ошибка = %v, ожидается ErrNotTwoStage", err)
+		}
+		if _, err := v2.GenerateWithMasterKey(ctx, masterKey, "github.com"); !errors.Is(err, ErrNotTwoStage) {
+			t.Errorf("GenerateWithMasterKey() ошибка = %v, ожидается ErrNotTwoStage", err)
+		}
+	})
+
+	t.Run("Очищенный ключ", func(t *testing.T) {
+		cleared, err := gen.DeriveMasterKey(ctx, []byte("testmaster"), "testuser")
+		if err != nil {
+			t.Fatalf("DeriveMasterKey() ошибка: %v", err)
+		}
+		cleared.Clear()
+		if _, err := gen.GenerateWithMasterKey(ctx, cleared, "github.com"); !errors.Is(err, ErrEmptyMasterKey) {
+			t.Errorf("GenerateWithMasterKey() ошибка = %v, ожидается ErrEmptyMasterKey", err)
+		}
+		if _, err := gen.GenerateWithMasterKey(ctx, nil, "github.com"); !errors.Is(err, ErrEmptyMasterKey) {
+			t.Errorf("GenerateWithMasterKey(nil) ошибка = %v, ожидается ErrEmptyMasterKey", err)
+		}
+	})
+}